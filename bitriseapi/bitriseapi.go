@@ -99,6 +99,26 @@ type BuildParamsModel struct {
 	PullRequestComment string `json:"pull_request_comment,omitempty"`
 	// newly added pull request comment's ID
 	PullRequestCommentID string `json:"pull_request_comment_id,omitempty"`
+	// association of the pull request comment's author with the repository (e.g. MEMBER), if the provider sends it.
+	// Only used to authorize the ChatOps commands, not sent to the Build Trigger API.
+	PullRequestCommentAuthorAssociation string `json:"-"`
+}
+
+// ChangedPaths returns the added, removed and modified paths of every commit, without duplicates.
+func (buildParams BuildParamsModel) ChangedPaths() []string {
+	var changedPaths []string
+	seen := map[string]bool{}
+	for _, commitPaths := range buildParams.PushCommitPaths {
+		for _, paths := range [][]string{commitPaths.Added, commitPaths.Removed, commitPaths.Modified} {
+			for _, pth := range paths {
+				if !seen[pth] {
+					seen[pth] = true
+					changedPaths = append(changedPaths, pth)
+				}
+			}
+		}
+	}
+	return changedPaths
 }
 
 // TriggerAPIParamsModel ...
@@ -135,6 +155,28 @@ type BuildTriggerRespItemModel struct {
 	TriggeredPipeline string `json:"triggered_pipeline"`
 }
 
+// RetryableError marks a Build Trigger failure which might succeed if the request is sent again
+// (e.g. the request could not be sent, or the server responded with a 5xx status code).
+type RetryableError struct {
+	err error
+}
+
+// Error ...
+func (e RetryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap ...
+func (e RetryableError) Unwrap() error {
+	return e.err
+}
+
+// IsRetryableError ...
+func IsRetryableError(err error) bool {
+	var retryableErr RetryableError
+	return errors.As(err, &retryableErr)
+}
+
 // Validate ...
 func (triggerParams TriggerAPIParamsModel) Validate() error {
 	// This check validates the outgoing build params, catching cases that are clearly invalid. TODO it's incomplete, doesn't check for missing repo etc.
@@ -207,7 +249,7 @@ func TriggerBuild(ctx context.Context, url *url.URL, apiToken string, params Tri
 
 	resp, err := client.Do(req)
 	if err != nil {
-		return TriggerAPIResponseModel{}, false, errors.Wrapf(err, "TriggerBuild (url:%s): failed to send request", url.String())
+		return TriggerAPIResponseModel{}, false, RetryableError{err: errors.Wrapf(err, "TriggerBuild (url:%s): failed to send request", url.String())}
 	}
 	defer func() {
 		if err := resp.Body.Close(); err != nil {
@@ -222,7 +264,7 @@ func TriggerBuild(ctx context.Context, url *url.URL, apiToken string, params Tri
 	bodyString := string(body)
 
 	if resp.StatusCode >= 500 && resp.StatusCode < 600 {
-		return TriggerAPIResponseModel{}, false, errors.New(fmt.Sprintf("TriggerBuild (url:%s): request sent, but received a server error response (http-code:%d, response body:%s)", url.String(), resp.StatusCode, bodyString))
+		return TriggerAPIResponseModel{}, false, RetryableError{err: errors.New(fmt.Sprintf("TriggerBuild (url:%s): request sent, but received a server error response (http-code:%d, response body:%s)", url.String(), resp.StatusCode, bodyString))}
 	}
 
 	var respModel TriggerAPIResponseModel
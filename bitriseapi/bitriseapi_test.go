@@ -1,6 +1,9 @@
 package bitriseapi
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"testing"
 
@@ -26,6 +29,17 @@ func TestBuildTriggerURL(t *testing.T) {
 	}
 }
 
+func Test_BuildParamsModel_ChangedPaths(t *testing.T) {
+	buildParams := BuildParamsModel{
+		PushCommitPaths: []CommitPaths{
+			{Added: []string{"ios/App.swift"}, Modified: []string{"README.md"}},
+			{Removed: []string{"android/Old.kt"}, Modified: []string{"README.md", "ios/App.swift"}},
+		},
+	}
+	require.Equal(t, []string{"ios/App.swift", "README.md", "android/Old.kt"}, buildParams.ChangedPaths())
+	require.Nil(t, BuildParamsModel{}.ChangedPaths())
+}
+
 func Test_TriggerAPIParamsModel_Validate(t *testing.T) {
 	t.Log("Empty params")
 	{
@@ -162,3 +176,55 @@ func TestTriggerBuild(t *testing.T) {
 		}, apiResponse)
 	}
 }
+
+func TestTriggerBuild_RetryableErrors(t *testing.T) {
+	triggerParams := TriggerAPIParamsModel{
+		BuildParams: BuildParamsModel{
+			Branch: "develop",
+		},
+		TriggeredBy: "webhook",
+	}
+
+	t.Log("Server error - retryable")
+	{
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+		url, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		_, isSuccess, err := TriggerBuild(context.Background(), url, "api-token", triggerParams, false)
+		require.False(t, isSuccess)
+		require.Error(t, err)
+		require.True(t, IsRetryableError(err))
+	}
+
+	t.Log("Client error - not retryable")
+	{
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"status":"error","message":"invalid token"}`))
+		}))
+		defer server.Close()
+		url, err := url.Parse(server.URL)
+		require.NoError(t, err)
+
+		apiResponse, isSuccess, err := TriggerBuild(context.Background(), url, "api-token", triggerParams, false)
+		require.False(t, isSuccess)
+		require.NoError(t, err)
+		require.Equal(t, "invalid token", apiResponse.Message)
+	}
+
+	t.Log("Server not reachable - retryable")
+	{
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+		url, err := url.Parse(server.URL)
+		require.NoError(t, err)
+		server.Close()
+
+		_, isSuccess, err := TriggerBuild(context.Background(), url, "api-token", triggerParams, false)
+		require.False(t, isSuccess)
+		require.True(t, IsRetryableError(err))
+	}
+}
@@ -23,6 +23,14 @@ var (
 
 	// LogOnlyMode when set to true, no requests are sent to trigger builds
 	LogOnlyMode = false
+
+	// WebhookSecrets holds the secrets the incoming webhooks are signed with,
+	//  keyed either by app slug or by "service-id/app-slug" (for a single route)
+	WebhookSecrets = map[string]string{}
+
+	// WebhookUsernames holds the usernames the incoming webhooks are authenticated with, along with the secrets
+	//  (Basic authentication), keyed the same way as the WebhookSecrets
+	WebhookUsernames = map[string]string{}
 )
 
 // GetServerEnvMode ...
@@ -37,3 +45,23 @@ func SetupServerEnvMode() {
 		serverEnvironmentMode = envMode
 	}
 }
+
+// WebhookSecret returns the secret configured for the given route,
+// or an empty string if the incoming webhooks should not be verified.
+// A secret defined for the route ("service-id/app-slug") takes precedence over the one defined for the app.
+func WebhookSecret(serviceID, appSlug string) string {
+	if secret, ok := WebhookSecrets[serviceID+"/"+appSlug]; ok {
+		return secret
+	}
+	return WebhookSecrets[appSlug]
+}
+
+// WebhookUsername returns the username configured for the given route,
+// or an empty string if the username of the incoming webhooks should not be checked.
+// It's resolved the same way as the WebhookSecret.
+func WebhookUsername(serviceID, appSlug string) string {
+	if username, ok := WebhookUsernames[serviceID+"/"+appSlug]; ok {
+		return username
+	}
+	return WebhookUsernames[appSlug]
+}
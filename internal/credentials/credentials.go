@@ -0,0 +1,193 @@
+package credentials
+
+import (
+	"encoding/json"
+	"os"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+
+	"github.com/bitrise-io/bitrise-webhooks/internal/glob"
+)
+
+// Credentials are the Bitrise app's details a hook ID resolves to.
+type Credentials struct {
+	AppSlug  string `json:"app_slug,omitempty"`
+	APIToken string `json:"api_token,omitempty"`
+	// Secret is the optional secret the incoming webhooks are signed with
+	Secret string `json:"secret,omitempty"`
+	// Username is the optional username the incoming webhooks are authenticated with, along with the secret
+	//  (only checked by the providers which authenticate the webhooks with Basic authentication, e.g. Azure DevOps)
+	Username string `json:"username,omitempty"`
+	// Targets are the apps the builds are triggered on, if the hook fans out to multiple apps
+	//  (instead of AppSlug and APIToken)
+	Targets []Target `json:"targets,omitempty"`
+}
+
+// Target is an app the builds of a fan-out hook are triggered on.
+type Target struct {
+	AppSlug  string `json:"app_slug"`
+	APIToken string `json:"api_token"`
+	// Paths are optional glob patterns: if set, a build is triggered on the app only if
+	//  any of the changed files matches (or the webhook doesn't include the changed files)
+	Paths []string `json:"paths,omitempty"`
+}
+
+// APITokenOf returns the API token of the app, if the hook triggers builds on it.
+func (credentials Credentials) APITokenOf(appSlug string) (string, bool) {
+	if len(credentials.Targets) == 0 {
+		return credentials.APIToken, credentials.AppSlug == appSlug && credentials.APIToken != ""
+	}
+	for _, target := range credentials.Targets {
+		if target.AppSlug == appSlug {
+			return target.APIToken, true
+		}
+	}
+	return "", false
+}
+
+// Store resolves hook IDs to app credentials,
+// so that the Build Trigger API token doesn't have to be part of the webhook URL.
+type Store interface {
+	// Get returns the credentials registered for the hook ID,
+	// or false if no credentials are registered for it.
+	Get(hookID string) (Credentials, bool, error)
+}
+
+// ---------------------------------------
+// --- File backed store ---
+
+// FileStore ...
+type FileStore struct {
+	credentials map[string]Credentials
+}
+
+// NewFileStore reads the credentials from a JSON file, which maps hook IDs to credentials, e.g.:
+//
+//	{"my-hook-id": {"app_slug": "...", "api_token": "...", "secret": "..."}}
+//
+// or to the targets of a fan-out hook:
+//
+//	{"my-hook-id": {"targets": [{"app_slug": "...", "api_token": "...", "paths": ["ios/**"]}, ...], "secret": "..."}}
+func NewFileStore(pth string) (*FileStore, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read credentials file (%s)", pth)
+	}
+
+	var credentials map[string]Credentials
+	if err := json.Unmarshal(content, &credentials); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse credentials file (%s)", pth)
+	}
+	for hookID, aCredentials := range credentials {
+		if err := aCredentials.validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid credentials for hook (%s)", hookID)
+		}
+	}
+
+	return &FileStore{credentials: credentials}, nil
+}
+
+// Get ...
+func (s *FileStore) Get(hookID string) (Credentials, bool, error) {
+	credentials, ok := s.credentials[hookID]
+	return credentials, ok, nil
+}
+
+// ---------------------------------------
+// --- Environment backed store ---
+
+// DefaultEnvPrefix ...
+const DefaultEnvPrefix = "WEBHOOK_CREDENTIALS_"
+
+// EnvStore reads the credentials of a hook from environment variables:
+// <prefix><HOOK_ID>_APP_SLUG, <prefix><HOOK_ID>_API_TOKEN, the optional <prefix><HOOK_ID>_SECRET and <prefix><HOOK_ID>_USERNAME,
+// where HOOK_ID is the upper case hook ID, with every non alphanumeric character replaced with '_'.
+type EnvStore struct {
+	prefix    string
+	lookupEnv func(key string) (string, bool)
+}
+
+// NewEnvStore ...
+func NewEnvStore(prefix string) *EnvStore {
+	return &EnvStore{
+		prefix:    prefix,
+		lookupEnv: os.LookupEnv,
+	}
+}
+
+// Get ...
+func (s *EnvStore) Get(hookID string) (Credentials, bool, error) {
+	keyPrefix := s.prefix + envKeyFromHookID(hookID) + "_"
+
+	appSlug, isAppSlugSet := s.lookupEnv(keyPrefix + "APP_SLUG")
+	apiToken, isAPITokenSet := s.lookupEnv(keyPrefix + "API_TOKEN")
+	if !isAppSlugSet && !isAPITokenSet {
+		return Credentials{}, false, nil
+	}
+	secret, _ := s.lookupEnv(keyPrefix + "SECRET")
+	username, _ := s.lookupEnv(keyPrefix + "USERNAME")
+
+	credentials := Credentials{
+		AppSlug:  appSlug,
+		APIToken: apiToken,
+		Secret:   secret,
+		Username: username,
+	}
+	if err := credentials.validate(); err != nil {
+		return Credentials{}, false, errors.Wrapf(err, "invalid credentials for hook (%s)", hookID)
+	}
+	return credentials, true, nil
+}
+
+func envKeyFromHookID(hookID string) string {
+	return strings.Map(func(r rune) rune {
+		if r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return unicode.ToUpper(r)
+		}
+		return '_'
+	}, hookID)
+}
+
+func (c Credentials) validate() error {
+	if len(c.Targets) > 0 {
+		if c.AppSlug != "" || c.APIToken != "" {
+			return errors.New("both app slug and targets are defined")
+		}
+		appSlugs := map[string]bool{}
+		for i, target := range c.Targets {
+			if err := target.validate(); err != nil {
+				return errors.Wrapf(err, "invalid target #%d", i+1)
+			}
+			if appSlugs[target.AppSlug] {
+				return errors.Errorf("duplicated target app slug: %s", target.AppSlug)
+			}
+			appSlugs[target.AppSlug] = true
+		}
+		return nil
+	}
+
+	if c.AppSlug == "" {
+		return errors.New("missing app slug")
+	}
+	if c.APIToken == "" {
+		return errors.New("missing API token")
+	}
+	return nil
+}
+
+func (t Target) validate() error {
+	if t.AppSlug == "" {
+		return errors.New("missing app slug")
+	}
+	if t.APIToken == "" {
+		return errors.New("missing API token")
+	}
+	for _, pattern := range t.Paths {
+		if err := glob.Validate(pattern); err != nil {
+			return errors.Wrapf(err, "invalid path pattern (%s)", pattern)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package credentials
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	t.Log("Valid credentials file")
+	{
+		pth := filepath.Join(t.TempDir(), "credentials.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{
+  "ios-app": {"app_slug": "slug-1", "api_token": "token-1", "secret": "secret-1", "username": "bitrise"},
+  "android-app": {"app_slug": "slug-2", "api_token": "token-2"}
+}`), 0600))
+
+		store, err := NewFileStore(pth)
+		require.NoError(t, err)
+
+		credentials, ok, err := store.Get("ios-app")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, Credentials{AppSlug: "slug-1", APIToken: "token-1", Secret: "secret-1", Username: "bitrise"}, credentials)
+
+		credentials, ok, err = store.Get("android-app")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, Credentials{AppSlug: "slug-2", APIToken: "token-2"}, credentials)
+
+		_, ok, err = store.Get("unknown")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	t.Log("Fan-out hook")
+	{
+		pth := filepath.Join(t.TempDir(), "credentials.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{
+  "monorepo": {
+    "secret": "secret-1",
+    "targets": [
+      {"app_slug": "ios-slug", "api_token": "ios-token", "paths": ["ios/**", "shared/**"]},
+      {"app_slug": "backend-slug", "api_token": "backend-token"}
+    ]
+  }
+}`), 0600))
+
+		store, err := NewFileStore(pth)
+		require.NoError(t, err)
+
+		credentials, ok, err := store.Get("monorepo")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, Credentials{
+			Secret: "secret-1",
+			Targets: []Target{
+				{AppSlug: "ios-slug", APIToken: "ios-token", Paths: []string{"ios/**", "shared/**"}},
+				{AppSlug: "backend-slug", APIToken: "backend-token"},
+			},
+		}, credentials)
+	}
+
+	t.Log("Fan-out hook - invalid target")
+	{
+		pth := filepath.Join(t.TempDir(), "credentials.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"monorepo": {"targets": [{"app_slug": "ios-slug", "api_token": "ios-token"}, {"app_slug": "backend-slug"}]}}`), 0600))
+
+		_, err := NewFileStore(pth)
+		require.EqualError(t, err, "invalid credentials for hook (monorepo): invalid target #2: missing API token")
+	}
+
+	t.Log("Missing API token")
+	{
+		pth := filepath.Join(t.TempDir(), "credentials.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"ios-app": {"app_slug": "slug-1"}}`), 0600))
+
+		_, err := NewFileStore(pth)
+		require.EqualError(t, err, "invalid credentials for hook (ios-app): missing API token")
+	}
+
+	t.Log("Missing file")
+	{
+		_, err := NewFileStore(filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	}
+}
+
+func TestEnvStore(t *testing.T) {
+	envs := map[string]string{
+		"WEBHOOK_CREDENTIALS_IOS_APP_APP_SLUG":  "slug-1",
+		"WEBHOOK_CREDENTIALS_IOS_APP_API_TOKEN": "token-1",
+		"WEBHOOK_CREDENTIALS_IOS_APP_SECRET":    "secret-1",
+		"WEBHOOK_CREDENTIALS_IOS_APP_USERNAME":  "bitrise",
+		"WEBHOOK_CREDENTIALS_BROKEN_APP_SLUG":   "slug-2",
+	}
+	store := NewEnvStore(DefaultEnvPrefix)
+	store.lookupEnv = func(key string) (string, bool) {
+		value, ok := envs[key]
+		return value, ok
+	}
+
+	t.Log("Registered hook")
+	{
+		credentials, ok, err := store.Get("ios-app")
+		require.NoError(t, err)
+		require.True(t, ok)
+		require.Equal(t, Credentials{AppSlug: "slug-1", APIToken: "token-1", Secret: "secret-1", Username: "bitrise"}, credentials)
+	}
+
+	t.Log("Unknown hook")
+	{
+		_, ok, err := store.Get("android-app")
+		require.NoError(t, err)
+		require.False(t, ok)
+	}
+
+	t.Log("Incomplete credentials")
+	{
+		_, ok, err := store.Get("broken")
+		require.EqualError(t, err, "invalid credentials for hook (broken): missing API token")
+		require.False(t, ok)
+	}
+}
@@ -0,0 +1,105 @@
+package dedup
+
+import (
+	"sync"
+	"time"
+)
+
+// Response is the response which was sent for the first delivery of a webhook,
+// and which is sent again for its redeliveries.
+type Response struct {
+	StatusCode  int
+	ContentType string
+	Body        []byte
+}
+
+// Store keeps track of the processed webhook deliveries.
+// The in-memory store is the only implementation for now, which is not shared between server instances;
+// a Redis or SQL backed store can implement the same interface.
+type Store interface {
+	// Reserve marks the delivery as being processed.
+	// If the delivery is already known it isn't reserved, and its response is returned
+	// (which is nil if the first delivery is still being processed).
+	Reserve(key string) (response *Response, isReserved bool, err error)
+	// Complete stores the response of the reserved delivery.
+	Complete(key string, response Response) error
+	// Release removes the reservation, so that the next delivery with the same key is processed again.
+	Release(key string) error
+}
+
+// ---------------------------------------
+// --- In-memory store ---
+
+// sweepInterval is the minimum time between two removals of the expired deliveries
+const sweepInterval = time.Minute
+
+type delivery struct {
+	response  *Response
+	expiresAt time.Time
+}
+
+// MemoryStore keeps the deliveries in memory, for the given TTL.
+type MemoryStore struct {
+	ttl        time.Duration
+	now        func() time.Time
+	mu         sync.Mutex
+	deliveries map[string]delivery
+	lastSweep  time.Time
+}
+
+// NewMemoryStore ...
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{
+		ttl:        ttl,
+		now:        time.Now,
+		deliveries: map[string]delivery{},
+	}
+}
+
+// Reserve ...
+func (s *MemoryStore) Reserve(key string) (*Response, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if d, ok := s.deliveries[key]; ok && now.Before(d.expiresAt) {
+		return d.response, false, nil
+	}
+	s.deliveries[key] = delivery{expiresAt: now.Add(s.ttl)}
+	return nil, true, nil
+}
+
+// Complete ...
+func (s *MemoryStore) Complete(key string, response Response) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.deliveries[key] = delivery{
+		response:  &response,
+		expiresAt: s.now().Add(s.ttl),
+	}
+	return nil
+}
+
+// Release ...
+func (s *MemoryStore) Release(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.deliveries, key)
+	return nil
+}
+
+func (s *MemoryStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	for key, d := range s.deliveries {
+		if !now.Before(d.expiresAt) {
+			delete(s.deliveries, key)
+		}
+	}
+	s.lastSweep = now
+}
@@ -0,0 +1,70 @@
+package dedup
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryStore(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	store := NewMemoryStore(time.Hour)
+	store.now = func() time.Time { return now }
+
+	t.Log("First delivery - reserved")
+	{
+		response, isReserved, err := store.Reserve("github/app-slug/delivery-1")
+		require.NoError(t, err)
+		require.True(t, isReserved)
+		require.Nil(t, response)
+	}
+
+	t.Log("Redelivery while the first one is processed")
+	{
+		response, isReserved, err := store.Reserve("github/app-slug/delivery-1")
+		require.NoError(t, err)
+		require.False(t, isReserved)
+		require.Nil(t, response)
+	}
+
+	t.Log("Redelivery after the first one is processed - the original response")
+	{
+		require.NoError(t, store.Complete("github/app-slug/delivery-1", Response{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}))
+
+		response, isReserved, err := store.Reserve("github/app-slug/delivery-1")
+		require.NoError(t, err)
+		require.False(t, isReserved)
+		require.Equal(t, &Response{StatusCode: 201, ContentType: "application/json", Body: []byte(`{}`)}, response)
+	}
+
+	t.Log("Same delivery ID for an other app - reserved")
+	{
+		_, isReserved, err := store.Reserve("github/other-app-slug/delivery-1")
+		require.NoError(t, err)
+		require.True(t, isReserved)
+	}
+
+	t.Log("Released delivery - reserved again")
+	{
+		_, isReserved, err := store.Reserve("github/app-slug/delivery-2")
+		require.NoError(t, err)
+		require.True(t, isReserved)
+		require.NoError(t, store.Release("github/app-slug/delivery-2"))
+
+		_, isReserved, err = store.Reserve("github/app-slug/delivery-2")
+		require.NoError(t, err)
+		require.True(t, isReserved)
+	}
+
+	t.Log("Expired delivery - reserved again")
+	{
+		now = now.Add(time.Hour)
+
+		_, isReserved, err := store.Reserve("github/app-slug/delivery-1")
+		require.NoError(t, err)
+		require.True(t, isReserved)
+		// the other expired deliveries are swept
+		require.Len(t, store.deliveries, 1)
+	}
+}
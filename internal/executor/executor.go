@@ -0,0 +1,131 @@
+package executor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/bitrise-io/api-utils/logging"
+)
+
+const (
+	// DefaultWorkers ...
+	DefaultWorkers = 10
+	// DefaultQueueSize ...
+	DefaultQueueSize = 100
+	// DefaultJobTimeout ...
+	DefaultJobTimeout = 2 * time.Minute
+)
+
+// ErrQueueFull is returned by Submit if every worker is busy and the queue is full.
+var ErrQueueFull = errors.New("background job queue is full")
+
+// ErrStopped is returned by Submit after Shutdown was called.
+var ErrStopped = errors.New("background executor is shut down")
+
+// Job ...
+type Job func(ctx context.Context)
+
+type queuedJob struct {
+	ctx context.Context
+	job Job
+}
+
+// Executor runs jobs in the background, on a bounded pool of workers.
+// The jobs don't inherit the cancellation of the context they are submitted with
+// (typically a request context, which is cancelled as soon as the response is sent),
+// but they do inherit its values, e.g. the logger fields and the tracing span.
+type Executor struct {
+	jobTimeout time.Duration
+	queue      chan queuedJob
+	workers    sync.WaitGroup
+	// pending is the number of the queued and the running jobs
+	pending atomic.Int64
+
+	mu        sync.RWMutex
+	isStopped bool
+}
+
+// New starts the workers.
+func New(workers, queueSize int, jobTimeout time.Duration) *Executor {
+	e := &Executor{
+		jobTimeout: jobTimeout,
+		queue:      make(chan queuedJob, queueSize),
+	}
+	for i := 0; i < workers; i++ {
+		e.workers.Add(1)
+		go e.work()
+	}
+	return e
+}
+
+// Submit queues the job, or returns ErrQueueFull without blocking.
+func (e *Executor) Submit(ctx context.Context, job Job) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.isStopped {
+		return ErrStopped
+	}
+
+	e.pending.Add(1)
+	select {
+	case e.queue <- queuedJob{ctx: context.WithoutCancel(ctx), job: job}:
+		return nil
+	default:
+		e.pending.Add(-1)
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting new jobs, and waits for the queued and the running jobs to finish,
+// or for the context to be done. Returns the number of the jobs which did not finish.
+func (e *Executor) Shutdown(ctx context.Context) int {
+	e.mu.Lock()
+	if !e.isStopped {
+		e.isStopped = true
+		close(e.queue)
+	}
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return 0
+	case <-ctx.Done():
+		return int(e.pending.Load())
+	}
+}
+
+func (e *Executor) work() {
+	defer e.workers.Done()
+
+	for queued := range e.queue {
+		e.run(queued)
+	}
+}
+
+func (e *Executor) run(queued queuedJob) {
+	defer e.pending.Add(-1)
+
+	ctx, cancel := context.WithTimeout(queued.ctx, e.jobTimeout)
+	defer cancel()
+
+	defer func() {
+		if r := recover(); r != nil {
+			logging.WithContext(ctx).Error(" [!] Exception: background job panicked", zap.Error(fmt.Errorf("%v", r)))
+		}
+	}()
+
+	queued.job(ctx)
+}
@@ -0,0 +1,116 @@
+package executor
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+type contextKey string
+
+func TestExecutor_Submit(t *testing.T) {
+	t.Log("The job's context is detached from the submitter's context, but keeps its values")
+	{
+		e := New(1, 1, time.Minute)
+
+		ctx, cancel := context.WithCancel(context.WithValue(context.Background(), contextKey("key"), "value"))
+		cancel()
+
+		type jobContextInfo struct {
+			err         error
+			value       interface{}
+			hasDeadline bool
+		}
+		done := make(chan jobContextInfo)
+		require.NoError(t, e.Submit(ctx, func(jobCtx context.Context) {
+			_, hasDeadline := jobCtx.Deadline()
+			done <- jobContextInfo{err: jobCtx.Err(), value: jobCtx.Value(contextKey("key")), hasDeadline: hasDeadline}
+		}))
+
+		info := <-done
+		require.NoError(t, info.err)
+		require.Equal(t, "value", info.value)
+		require.True(t, info.hasDeadline)
+	}
+
+	t.Log("Queue full")
+	{
+		e := New(1, 1, time.Minute)
+
+		block := make(chan struct{})
+		started := make(chan struct{})
+		require.NoError(t, e.Submit(context.Background(), func(context.Context) {
+			close(started)
+			<-block
+		}))
+		<-started
+		// the worker is busy, the queue has room for one job
+		require.NoError(t, e.Submit(context.Background(), func(context.Context) {}))
+		require.ErrorIs(t, e.Submit(context.Background(), func(context.Context) {}), ErrQueueFull)
+		close(block)
+	}
+
+	t.Log("A panicking job doesn't stop the worker")
+	{
+		e := New(1, 2, time.Minute)
+
+		require.NoError(t, e.Submit(context.Background(), func(context.Context) {
+			panic("boom")
+		}))
+		done := make(chan struct{})
+		require.NoError(t, e.Submit(context.Background(), func(context.Context) {
+			close(done)
+		}))
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("the job after the panicking one did not run")
+		}
+	}
+}
+
+func TestExecutor_Shutdown(t *testing.T) {
+	t.Log("Waits for the queued and the running jobs")
+	{
+		e := New(1, 3, time.Minute)
+
+		var finished []int
+		var mu sync.Mutex
+		for i := 0; i < 3; i++ {
+			i := i
+			require.NoError(t, e.Submit(context.Background(), func(context.Context) {
+				time.Sleep(10 * time.Millisecond)
+				mu.Lock()
+				finished = append(finished, i)
+				mu.Unlock()
+			}))
+		}
+
+		require.Equal(t, 0, e.Shutdown(context.Background()))
+		require.Equal(t, []int{0, 1, 2}, finished)
+		require.ErrorIs(t, e.Submit(context.Background(), func(context.Context) {}), ErrStopped)
+	}
+
+	t.Log("Timeout - returns the number of the abandoned jobs")
+	{
+		e := New(1, 2, time.Minute)
+
+		block := make(chan struct{})
+		defer close(block)
+		started := make(chan struct{})
+		require.NoError(t, e.Submit(context.Background(), func(context.Context) {
+			close(started)
+			<-block
+		}))
+		<-started
+		require.NoError(t, e.Submit(context.Background(), func(context.Context) {}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		require.Equal(t, 2, e.Shutdown(ctx))
+	}
+}
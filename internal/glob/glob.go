@@ -0,0 +1,66 @@
+package glob
+
+import (
+	"path"
+	"strings"
+)
+
+// Match reports whether the slash separated name (e.g. a branch name or a file path) matches the pattern.
+// The pattern is matched segment by segment with path.Match, and a `**` segment matches
+// zero or more segments, e.g. `ios/**/*.swift` matches `ios/App/Sources/main.swift`.
+// An invalid pattern never matches, use Validate to check the patterns in advance.
+func Match(pattern, name string) bool {
+	return matchSegments(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+// MatchAny reports whether the name matches at least one of the patterns.
+func MatchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if Match(pattern, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchAnyOf reports whether any of the names matches at least one of the patterns.
+func MatchAnyOf(patterns []string, names []string) bool {
+	for _, name := range names {
+		if MatchAny(patterns, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate returns an error if the pattern is malformed.
+func Validate(pattern string) error {
+	for _, segment := range strings.Split(pattern, "/") {
+		if _, err := path.Match(segment, ""); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func matchSegments(patterns, names []string) bool {
+	for len(patterns) > 0 {
+		if patterns[0] == "**" {
+			for i := 0; i <= len(names); i++ {
+				if matchSegments(patterns[1:], names[i:]) {
+					return true
+				}
+			}
+			return false
+		}
+
+		if len(names) == 0 {
+			return false
+		}
+		if matched, err := path.Match(patterns[0], names[0]); err != nil || !matched {
+			return false
+		}
+		patterns, names = patterns[1:], names[1:]
+	}
+	return len(names) == 0
+}
@@ -0,0 +1,48 @@
+package glob
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatch(t *testing.T) {
+	for _, tt := range []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{pattern: "master", name: "master", want: true},
+		{pattern: "release/*", name: "release/1.0", want: true},
+		{pattern: "release/*", name: "release/1.0/hotfix", want: false},
+		{pattern: "release/**", name: "release/1.0/hotfix", want: true},
+		{pattern: "*", name: "feature/login", want: false},
+		{pattern: "**", name: "feature/login", want: true},
+		{pattern: "ios/**/*.swift", name: "ios/main.swift", want: true},
+		{pattern: "ios/**/*.swift", name: "ios/App/Sources/main.swift", want: true},
+		{pattern: "ios/**/*.swift", name: "android/App/main.swift", want: false},
+		{pattern: "**/*.md", name: "README.md", want: true},
+		{pattern: "docs/**", name: "docs", want: true},
+		{pattern: "v[0-9]*", name: "v1.2.3", want: true},
+		{pattern: "v[0-9", name: "v1", want: false},
+	} {
+		require.Equal(t, tt.want, Match(tt.pattern, tt.name), "%s - %s", tt.pattern, tt.name)
+	}
+}
+
+func TestMatchAny(t *testing.T) {
+	require.True(t, MatchAny([]string{"main", "release/*"}, "release/2.0"))
+	require.False(t, MatchAny([]string{"main", "release/*"}, "feature/a"))
+	require.False(t, MatchAny(nil, "main"))
+}
+
+func TestMatchAnyOf(t *testing.T) {
+	require.True(t, MatchAnyOf([]string{"ios/**", "shared/**"}, []string{"README.md", "shared/Model.swift"}))
+	require.False(t, MatchAnyOf([]string{"ios/**"}, []string{"README.md", "android/App.kt"}))
+	require.False(t, MatchAnyOf([]string{"ios/**"}, nil))
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, Validate("ios/**/*.swift"))
+	require.Error(t, Validate("release/[a-"))
+}
@@ -0,0 +1,237 @@
+package outbox
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bitrise-io/api-utils/logging"
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	"github.com/bitrise-io/bitrise-webhooks/internal/credentials"
+)
+
+const (
+	// DefaultMaxAttempts ...
+	DefaultMaxAttempts = 8
+	// DefaultInitialBackoff ...
+	DefaultInitialBackoff = 30 * time.Second
+	// DefaultMaxBackoff ...
+	DefaultMaxBackoff = 30 * time.Minute
+	// DefaultPollInterval ...
+	DefaultPollInterval = 10 * time.Second
+
+	// batchSize is the max number of entries retried in a single poll
+	batchSize = 50
+)
+
+// TriggerFunc sends a Build Trigger API request, with the same semantics as bitriseapi.TriggerBuild.
+type TriggerFunc func(ctx context.Context, triggerURL *url.URL, apiToken string, params bitriseapi.TriggerAPIParamsModel) (bitriseapi.TriggerAPIResponseModel, bool, error)
+
+// Outbox retries the failed Build Trigger API calls with exponential backoff.
+// An entry is retried until either the call succeeds, the Build Trigger API rejects the call
+// or MaxAttempts is reached; in the latter two cases the entry is kept in the store as a dead letter.
+type Outbox struct {
+	MaxAttempts    int
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+	// CredentialStore resolves the API tokens of the entries stored with a hook ID,
+	//  if not set, the API tokens are stored in the entries
+	CredentialStore credentials.Store
+
+	store   Store
+	trigger TriggerFunc
+	now     func() time.Time
+}
+
+// New ...
+func New(store Store, trigger TriggerFunc) *Outbox {
+	return &Outbox{
+		MaxAttempts:    DefaultMaxAttempts,
+		InitialBackoff: DefaultInitialBackoff,
+		MaxBackoff:     DefaultMaxBackoff,
+		store:          store,
+		trigger:        trigger,
+		now:            time.Now,
+	}
+}
+
+// Enqueue stores a Build Trigger API call which failed on its first attempt.
+// If the call was made for a hook ID (and the CredentialStore is set), only the hook ID is stored, not the API token.
+func (o *Outbox) Enqueue(appSlug, hookID string, triggerURL *url.URL, apiToken string, params bitriseapi.TriggerAPIParamsModel, cause error) (Entry, error) {
+	id, err := generateID()
+	if err != nil {
+		return Entry{}, err
+	}
+
+	now := o.now()
+	entry := Entry{
+		ID:            id,
+		AppSlug:       appSlug,
+		TriggerURL:    triggerURL.String(),
+		APIToken:      apiToken,
+		Params:        params,
+		State:         StatePending,
+		Attempts:      1,
+		CreatedAt:     now,
+		NextAttemptAt: now.Add(o.backoff(1)),
+	}
+	if hookID != "" && o.CredentialStore != nil {
+		entry.HookID = hookID
+		entry.APIToken = ""
+	}
+	if cause != nil {
+		entry.LastError = cause.Error()
+	}
+
+	if err := o.store.Add(entry); err != nil {
+		return Entry{}, err
+	}
+	return entry, nil
+}
+
+// Run retries the due entries every pollInterval, until the context is cancelled.
+func (o *Outbox) Run(ctx context.Context, pollInterval time.Duration) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			o.ProcessDue(ctx)
+		}
+	}
+}
+
+// ProcessDue retries the entries which are due.
+func (o *Outbox) ProcessDue(ctx context.Context) {
+	logger := logging.WithContext(ctx)
+
+	entries, err := o.store.Due(o.now(), batchSize)
+	if err != nil {
+		logger.Error(" [!] Exception: Outbox: failed to list due entries", zap.Error(err))
+		return
+	}
+
+	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
+		o.retry(ctx, entry)
+	}
+}
+
+func (o *Outbox) retry(ctx context.Context, entry Entry) {
+	logger := logging.WithContext(ctx).With(
+		zap.String("outbox_entry_id", entry.ID),
+		zap.String("app_slug", entry.AppSlug),
+		zap.Int("attempt", entry.Attempts+1),
+	)
+
+	triggerURL, err := url.Parse(entry.TriggerURL)
+	if err != nil {
+		o.markDead(ctx, entry, errors.Wrap(err, "invalid trigger URL").Error())
+		return
+	}
+
+	apiToken := entry.APIToken
+	if entry.HookID != "" {
+		var isResolved bool
+		apiToken, isResolved, err = o.resolveAPIToken(entry)
+		if err != nil {
+			// the credential store might be temporarily unavailable, try again on the next poll
+			logger.Error(" [!] Exception: Outbox: failed to resolve the API token", zap.String("hook_id", entry.HookID), zap.Error(err))
+			return
+		}
+		if !isResolved {
+			o.markDead(ctx, entry, "the hook ID doesn't trigger builds on the app anymore")
+			return
+		}
+	}
+
+	triggerResp, isSuccess, err := o.trigger(ctx, triggerURL, apiToken, entry.Params)
+	entry.Attempts++
+
+	switch {
+	case err != nil && bitriseapi.IsRetryableError(err):
+		if entry.Attempts >= o.MaxAttempts {
+			o.markDead(ctx, entry, err.Error())
+			return
+		}
+		entry.LastError = err.Error()
+		entry.NextAttemptAt = o.now().Add(o.backoff(entry.Attempts))
+		if err := o.store.Update(entry); err != nil {
+			logger.Error(" [!] Exception: Outbox: failed to reschedule entry", zap.Error(err))
+			return
+		}
+		logger.Warn("Outbox: Build Trigger retry failed, rescheduled", zap.Time("next_attempt_at", entry.NextAttemptAt), zap.Error(err))
+	case err != nil:
+		o.markDead(ctx, entry, err.Error())
+	case !isSuccess:
+		o.markDead(ctx, entry, "Build Trigger API rejected the request: "+triggerResp.Message)
+	default:
+		if err := o.store.Remove(entry.ID); err != nil {
+			logger.Error(" [!] Exception: Outbox: failed to remove entry", zap.Error(err))
+			return
+		}
+		logger.Info("Outbox: Build Trigger retry succeeded")
+	}
+}
+
+// resolveAPIToken returns the current API token of the entry's app, registered for the entry's hook ID.
+func (o *Outbox) resolveAPIToken(entry Entry) (string, bool, error) {
+	if o.CredentialStore == nil {
+		return "", false, errors.New("no credential store configured, hook IDs can't be resolved")
+	}
+	hookCredentials, isRegistered, err := o.CredentialStore.Get(entry.HookID)
+	if err != nil || !isRegistered {
+		return "", false, err
+	}
+	apiToken, isResolved := hookCredentials.APITokenOf(entry.AppSlug)
+	return apiToken, isResolved, nil
+}
+
+func (o *Outbox) markDead(ctx context.Context, entry Entry, reason string) {
+	logger := logging.WithContext(ctx).With(
+		zap.String("outbox_entry_id", entry.ID),
+		zap.String("app_slug", entry.AppSlug),
+		zap.Int("attempts", entry.Attempts),
+	)
+
+	entry.State = StateDead
+	entry.LastError = reason
+	if err := o.store.Update(entry); err != nil {
+		logger.Error(" [!] Exception: Outbox: failed to move entry to dead letters", zap.Error(err))
+		return
+	}
+	logger.Error("Outbox: Build Trigger gave up, entry moved to dead letters", zap.String("reason", reason))
+}
+
+// backoff returns the delay before the next attempt, after the given number of attempts
+func (o *Outbox) backoff(attempts int) time.Duration {
+	delay := o.InitialBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= o.MaxBackoff {
+			return o.MaxBackoff
+		}
+	}
+	if delay > o.MaxBackoff {
+		return o.MaxBackoff
+	}
+	return delay
+}
+
+func generateID() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate outbox entry ID")
+	}
+	return hex.EncodeToString(b), nil
+}
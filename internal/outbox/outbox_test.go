@@ -0,0 +1,232 @@
+package outbox
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	"github.com/bitrise-io/bitrise-webhooks/internal/credentials"
+)
+
+type triggerCall struct {
+	triggerURL string
+	apiToken   string
+	params     bitriseapi.TriggerAPIParamsModel
+}
+
+type testTrigger struct {
+	calls     []triggerCall
+	resp      bitriseapi.TriggerAPIResponseModel
+	isSuccess bool
+	err       error
+}
+
+func (t *testTrigger) trigger(_ context.Context, triggerURL *url.URL, apiToken string, params bitriseapi.TriggerAPIParamsModel) (bitriseapi.TriggerAPIResponseModel, bool, error) {
+	t.calls = append(t.calls, triggerCall{triggerURL: triggerURL.String(), apiToken: apiToken, params: params})
+	return t.resp, t.isSuccess, t.err
+}
+
+func newTestOutbox(t *testing.T, trigger *testTrigger, now *time.Time) (*Outbox, *FileStore) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	o := New(store, trigger.trigger)
+	o.MaxAttempts = 3
+	o.InitialBackoff = time.Minute
+	o.MaxBackoff = 10 * time.Minute
+	o.now = func() time.Time { return *now }
+	return o, store
+}
+
+var (
+	testTriggerURL, _ = url.Parse("https://app.bitrise.io/app/app-slug/build/start.json")
+	testParams        = bitriseapi.TriggerAPIParamsModel{
+		BuildParams: bitriseapi.BuildParamsModel{Branch: "master", CommitHash: "sha"},
+		TriggeredBy: "webhook",
+	}
+)
+
+func retryableError(t *testing.T) error {
+	// a real connection error, to get a bitriseapi.RetryableError
+	_, _, err := bitriseapi.TriggerBuild(context.Background(), &url.URL{Scheme: "http", Host: "127.0.0.1:0"}, "token", testParams, false)
+	require.True(t, bitriseapi.IsRetryableError(err))
+	return err
+}
+
+func TestOutbox_Enqueue(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	trigger := &testTrigger{}
+	o, store := newTestOutbox(t, trigger, &now)
+
+	entry, err := o.Enqueue("app-slug", "", testTriggerURL, "api-token", testParams, errors.New("connection refused"))
+	require.NoError(t, err)
+	require.NotEmpty(t, entry.ID)
+	require.Equal(t, StatePending, entry.State)
+	require.Equal(t, 1, entry.Attempts)
+	require.Equal(t, "connection refused", entry.LastError)
+	require.Equal(t, now.Add(time.Minute), entry.NextAttemptAt)
+
+	t.Log("Not due yet")
+	{
+		o.ProcessDue(context.Background())
+		require.Empty(t, trigger.calls)
+	}
+
+	t.Log("Due")
+	{
+		due, err := store.Due(now.Add(time.Minute), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, entry.ID, due[0].ID)
+		require.Equal(t, testParams, due[0].Params)
+		require.Equal(t, "api-token", due[0].APIToken)
+	}
+}
+
+func TestOutbox_ProcessDue(t *testing.T) {
+	t.Log("Successful retry - entry removed")
+	{
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		trigger := &testTrigger{isSuccess: true}
+		o, store := newTestOutbox(t, trigger, &now)
+
+		_, err := o.Enqueue("app-slug", "", testTriggerURL, "api-token", testParams, nil)
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		o.ProcessDue(context.Background())
+
+		require.Equal(t, []triggerCall{{triggerURL: testTriggerURL.String(), apiToken: "api-token", params: testParams}}, trigger.calls)
+		due, err := store.Due(now.Add(time.Hour), 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+	}
+
+	t.Log("Retryable error - rescheduled with exponential backoff, then dead letter")
+	{
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		trigger := &testTrigger{err: retryableError(t)}
+		o, store := newTestOutbox(t, trigger, &now)
+
+		entry, err := o.Enqueue("app-slug", "", testTriggerURL, "api-token", testParams, nil)
+		require.NoError(t, err)
+
+		// 2nd attempt
+		now = now.Add(time.Minute)
+		o.ProcessDue(context.Background())
+		require.Len(t, trigger.calls, 1)
+
+		due, err := store.Due(now.Add(2*time.Minute-time.Second), 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+		due, err = store.Due(now.Add(2*time.Minute), 10)
+		require.NoError(t, err)
+		require.Len(t, due, 1)
+		require.Equal(t, 2, due[0].Attempts)
+
+		// 3rd attempt - MaxAttempts reached
+		now = now.Add(2 * time.Minute)
+		o.ProcessDue(context.Background())
+		require.Len(t, trigger.calls, 2)
+
+		due, err = store.Due(now.Add(time.Hour), 10)
+		require.NoError(t, err)
+		require.Empty(t, due)
+		requireDeadLetter(t, store, entry.ID, 3)
+	}
+
+	t.Log("Rejected by the Build Trigger API - dead letter")
+	{
+		now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+		trigger := &testTrigger{resp: bitriseapi.TriggerAPIResponseModel{Status: "error", Message: "invalid token"}}
+		o, store := newTestOutbox(t, trigger, &now)
+
+		entry, err := o.Enqueue("app-slug", "", testTriggerURL, "api-token", testParams, nil)
+		require.NoError(t, err)
+
+		now = now.Add(time.Minute)
+		o.ProcessDue(context.Background())
+		require.Len(t, trigger.calls, 1)
+		requireDeadLetter(t, store, entry.ID, 2)
+	}
+}
+
+func TestOutbox_backoff(t *testing.T) {
+	o := New(nil, nil)
+	o.InitialBackoff = 30 * time.Second
+	o.MaxBackoff = 5 * time.Minute
+
+	require.Equal(t, 30*time.Second, o.backoff(1))
+	require.Equal(t, time.Minute, o.backoff(2))
+	require.Equal(t, 2*time.Minute, o.backoff(3))
+	require.Equal(t, 4*time.Minute, o.backoff(4))
+	require.Equal(t, 5*time.Minute, o.backoff(5))
+	require.Equal(t, 5*time.Minute, o.backoff(100))
+}
+
+func requireDeadLetter(t *testing.T, store *FileStore, id string, attempts int) {
+	entry := readEntry(t, store.deadEntryPath(id))
+	require.Equal(t, StateDead, entry.State)
+	require.Equal(t, attempts, entry.Attempts)
+	require.NotEmpty(t, entry.LastError)
+}
+
+type credentialStoreStub map[string]credentials.Credentials
+
+func (s credentialStoreStub) Get(hookID string) (credentials.Credentials, bool, error) {
+	hookCredentials, ok := s[hookID]
+	return hookCredentials, ok, nil
+}
+
+func TestOutbox_HookID(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	trigger := &testTrigger{isSuccess: true}
+	o, store := newTestOutbox(t, trigger, &now)
+	credentialStore := credentialStoreStub{
+		"my-hook": {Targets: []credentials.Target{{AppSlug: "app-slug", APIToken: "api-token"}}},
+	}
+	o.CredentialStore = credentialStore
+
+	t.Log("The API token is not stored")
+	{
+		entry, err := o.Enqueue("app-slug", "my-hook", testTriggerURL, "api-token", testParams, nil)
+		require.NoError(t, err)
+		require.Equal(t, "my-hook", entry.HookID)
+		require.Equal(t, "", entry.APIToken)
+
+		content, err := os.ReadFile(store.entryPath(entry.ID))
+		require.NoError(t, err)
+		require.NotContains(t, string(content), "api-token")
+	}
+
+	t.Log("The current API token of the hook is used on retry")
+	{
+		credentialStore["my-hook"] = credentials.Credentials{Targets: []credentials.Target{{AppSlug: "app-slug", APIToken: "rotated-api-token"}}}
+
+		now = now.Add(time.Minute)
+		o.ProcessDue(context.Background())
+		require.Equal(t, []triggerCall{{triggerURL: testTriggerURL.String(), apiToken: "rotated-api-token", params: testParams}}, trigger.calls)
+	}
+
+	t.Log("The hook doesn't trigger builds on the app anymore - dead letter")
+	{
+		trigger.calls = nil
+		entry, err := o.Enqueue("app-slug", "my-hook", testTriggerURL, "api-token", testParams, nil)
+		require.NoError(t, err)
+		delete(credentialStore, "my-hook")
+
+		now = now.Add(time.Minute)
+		o.ProcessDue(context.Background())
+		require.Empty(t, trigger.calls)
+
+		content, err := os.ReadFile(store.deadEntryPath(entry.ID))
+		require.NoError(t, err)
+		require.Contains(t, string(content), `"state":"dead"`)
+	}
+}
@@ -0,0 +1,225 @@
+package outbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/api-utils/logging"
+	"github.com/pkg/errors"
+	"go.uber.org/zap"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+)
+
+// State ...
+type State string
+
+const (
+	// StatePending entries are waiting for their next attempt
+	StatePending State = "pending"
+	// StateDead entries ran out of attempts (or were rejected by the Build Trigger API),
+	//  they are kept in the store for inspection, but are not retried anymore
+	//  (the FileStore keeps them in a separate subdirectory, so they aren't read when the due entries are listed)
+	StateDead State = "dead"
+)
+
+// Entry is a Build Trigger API call which failed and has to be retried.
+type Entry struct {
+	ID         string `json:"id"`
+	AppSlug    string `json:"app_slug"`
+	TriggerURL string `json:"trigger_url"`
+	// HookID is set if the call was made for a hook ID of the credential store:
+	//  the API token is not stored in this case, it's resolved again from the credential store on retry
+	HookID string `json:"hook_id,omitempty"`
+	// APIToken is only stored for the calls which can't be resolved by a hook ID
+	//  (the /h/{service-id}/{app-slug}/{api-token} route)
+	APIToken string                           `json:"api_token,omitempty"`
+	Params   bitriseapi.TriggerAPIParamsModel `json:"params"`
+
+	State         State     `json:"state"`
+	Attempts      int       `json:"attempts"`
+	LastError     string    `json:"last_error,omitempty"`
+	CreatedAt     time.Time `json:"created_at"`
+	NextAttemptAt time.Time `json:"next_attempt_at"`
+}
+
+// Store persists the outbox entries.
+// The file backed store is the only implementation for now,
+// but the interface is kept small so that a Redis or SQL backed store can be added later.
+type Store interface {
+	// Add stores a new entry
+	Add(entry Entry) error
+	// Update overwrites a stored entry
+	Update(entry Entry) error
+	// Remove deletes the entry, it's not an error if the entry does not exist
+	Remove(id string) error
+	// Due returns at most limit pending entries whose next attempt is not after now,
+	// the ones which are due the longest first
+	Due(now time.Time, limit int) ([]Entry, error)
+}
+
+// ---------------------------------------
+// --- File backed store ---
+
+const (
+	entryFileExt = ".json"
+	deadDirName  = "dead"
+)
+
+// FileStore stores every entry as a separate JSON file in a directory,
+// the dead letters in its dead subdirectory.
+// The entries of the /h/{service-id}/{app-slug}/{api-token} route include the Build Trigger API token in plain text,
+// so the directory (0700) and the files (0600) are only accessible by the owner.
+type FileStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// NewFileStore ...
+func NewFileStore(dir string) (*FileStore, error) {
+	if err := os.MkdirAll(filepath.Join(dir, deadDirName), 0700); err != nil {
+		return nil, errors.Wrapf(err, "failed to create outbox directory (%s)", dir)
+	}
+	return &FileStore{dir: dir}, nil
+}
+
+// Add ...
+func (s *FileStore) Add(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.exists(entry.ID) {
+		return errors.Errorf("outbox entry (%s) already exists", entry.ID)
+	}
+	return s.write(entry)
+}
+
+// Update ...
+func (s *FileStore) Update(entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if !s.exists(entry.ID) {
+		return errors.Errorf("outbox entry (%s) not found", entry.ID)
+	}
+	return s.write(entry)
+}
+
+// Remove ...
+func (s *FileStore) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, path := range []string{s.entryPath(id), s.deadEntryPath(id)} {
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return errors.Wrapf(err, "failed to remove outbox entry (%s)", id)
+		}
+	}
+	return nil
+}
+
+// Due ...
+// An unreadable or invalid entry file is skipped (and logged), so that it can't block the retries of the other entries.
+func (s *FileStore) Due(now time.Time, limit int) ([]Entry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	dirEntries, err := os.ReadDir(s.dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list outbox directory (%s)", s.dir)
+	}
+
+	var due []Entry
+	for _, dirEntry := range dirEntries {
+		if dirEntry.IsDir() || !strings.HasSuffix(dirEntry.Name(), entryFileExt) {
+			continue
+		}
+
+		content, err := os.ReadFile(filepath.Join(s.dir, dirEntry.Name()))
+		if err != nil {
+			logging.WithContext(nil).Error(" [!] Exception: Outbox: failed to read entry, skipping", zap.String("file", dirEntry.Name()), zap.Error(err))
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(content, &entry); err != nil {
+			logging.WithContext(nil).Error(" [!] Exception: Outbox: failed to parse entry, skipping", zap.String("file", dirEntry.Name()), zap.Error(err))
+			continue
+		}
+
+		if entry.State == StatePending && !entry.NextAttemptAt.After(now) {
+			due = append(due, entry)
+		}
+	}
+
+	sort.Slice(due, func(i, j int) bool {
+		return due[i].NextAttemptAt.Before(due[j].NextAttemptAt)
+	})
+	if limit > 0 && len(due) > limit {
+		due = due[:limit]
+	}
+	return due, nil
+}
+
+func (s *FileStore) entryPath(id string) string {
+	return filepath.Join(s.dir, id+entryFileExt)
+}
+
+func (s *FileStore) deadEntryPath(id string) string {
+	return filepath.Join(s.dir, deadDirName, id+entryFileExt)
+}
+
+func (s *FileStore) exists(id string) bool {
+	for _, path := range []string{s.entryPath(id), s.deadEntryPath(id)} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// write replaces the entry's file atomically: a crash mid-write can't leave a truncated entry behind.
+// A dead entry is moved to the dead subdirectory.
+func (s *FileStore) write(entry Entry) error {
+	content, err := json.Marshal(entry)
+	if err != nil {
+		return errors.Wrapf(err, "failed to serialize outbox entry (%s)", entry.ID)
+	}
+
+	tmpFile, err := os.CreateTemp(s.dir, entry.ID+"-*.tmp")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create outbox entry (%s)", entry.ID)
+	}
+	tmpPath := tmpFile.Name()
+	defer func() {
+		_ = os.Remove(tmpPath)
+	}()
+
+	if _, err := tmpFile.Write(content); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrapf(err, "failed to write outbox entry (%s)", entry.ID)
+	}
+	if err := tmpFile.Sync(); err != nil {
+		_ = tmpFile.Close()
+		return errors.Wrapf(err, "failed to write outbox entry (%s)", entry.ID)
+	}
+	if err := tmpFile.Close(); err != nil {
+		return errors.Wrapf(err, "failed to write outbox entry (%s)", entry.ID)
+	}
+
+	path, stalePath := s.entryPath(entry.ID), s.deadEntryPath(entry.ID)
+	if entry.State == StateDead {
+		path, stalePath = stalePath, path
+	}
+	if err := os.Rename(tmpPath, path); err != nil {
+		return errors.Wrapf(err, "failed to store outbox entry (%s)", entry.ID)
+	}
+	if err := os.Remove(stalePath); err != nil && !os.IsNotExist(err) {
+		return errors.Wrapf(err, "failed to store outbox entry (%s)", entry.ID)
+	}
+	return nil
+}
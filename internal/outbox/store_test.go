@@ -0,0 +1,106 @@
+package outbox
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFileStore(t *testing.T) {
+	store, err := NewFileStore(t.TempDir())
+	require.NoError(t, err)
+
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	first := Entry{ID: "first", AppSlug: "app-slug", State: StatePending, NextAttemptAt: now.Add(-time.Minute)}
+	second := Entry{ID: "second", AppSlug: "app-slug", State: StatePending, NextAttemptAt: now.Add(-2 * time.Minute)}
+	later := Entry{ID: "later", AppSlug: "app-slug", State: StatePending, NextAttemptAt: now.Add(time.Minute)}
+	dead := Entry{ID: "dead", AppSlug: "app-slug", State: StateDead, NextAttemptAt: now.Add(-time.Minute)}
+
+	t.Log("Add")
+	{
+		for _, entry := range []Entry{first, second, later, dead} {
+			require.NoError(t, store.Add(entry))
+		}
+		require.EqualError(t, store.Add(first), "outbox entry (first) already exists")
+	}
+
+	t.Log("Dead letters are kept in the dead subdirectory")
+	{
+		require.FileExists(t, store.deadEntryPath("dead"))
+		require.NoFileExists(t, store.entryPath("dead"))
+		require.EqualError(t, store.Add(Entry{ID: "dead"}), "outbox entry (dead) already exists")
+	}
+
+	t.Log("Due - oldest first, without the dead letters")
+	{
+		due, err := store.Due(now, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"second", "first"}, entryIDs(due))
+
+		due, err = store.Due(now, 1)
+		require.NoError(t, err)
+		require.Equal(t, []string{"second"}, entryIDs(due))
+	}
+
+	t.Log("Due - an invalid entry file doesn't block the others")
+	{
+		require.NoError(t, os.WriteFile(filepath.Join(store.dir, "invalid.json"), []byte("{"), 0600))
+		defer func() {
+			require.NoError(t, os.Remove(filepath.Join(store.dir, "invalid.json")))
+		}()
+
+		due, err := store.Due(now, 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"second", "first"}, entryIDs(due))
+	}
+
+	t.Log("Update")
+	{
+		first.State = StateDead
+		require.NoError(t, store.Update(first))
+		require.Equal(t, StateDead, readEntry(t, store.deadEntryPath("first")).State)
+		require.NoFileExists(t, store.entryPath("first"))
+
+		require.Error(t, store.Update(Entry{ID: "missing"}))
+	}
+
+	t.Log("Remove")
+	{
+		require.NoError(t, store.Remove("second"))
+		require.NoError(t, store.Remove("dead"))
+		require.NoFileExists(t, store.deadEntryPath("dead"))
+		require.NoError(t, store.Remove("missing"))
+
+		due, err := store.Due(now.Add(time.Hour), 10)
+		require.NoError(t, err)
+		require.Equal(t, []string{"later"}, entryIDs(due))
+	}
+
+	t.Log("Entries are only readable by the owner")
+	{
+		info, err := os.Stat(store.entryPath("later"))
+		require.NoError(t, err)
+		require.Equal(t, os.FileMode(0600), info.Mode().Perm())
+	}
+}
+
+func entryIDs(entries []Entry) []string {
+	var ids []string
+	for _, entry := range entries {
+		ids = append(ids, entry.ID)
+	}
+	return ids
+}
+
+func readEntry(t *testing.T, path string) Entry {
+	content, err := os.ReadFile(path)
+	require.NoError(t, err)
+
+	var entry Entry
+	require.NoError(t, json.Unmarshal(content, &entry))
+	return entry
+}
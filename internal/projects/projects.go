@@ -0,0 +1,82 @@
+package projects
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bitrise-io/bitrise-webhooks/internal/glob"
+)
+
+// AffectedProjectsEnvKey is the env var which lists the affected projects of the build, separated by commas
+const AffectedProjectsEnvKey = "BITRISE_AFFECTED_PROJECTS"
+
+// Map maps the projects of a (mono)repository to the glob patterns of their files (see glob.Match).
+type Map struct {
+	Projects map[string][]string `json:"projects"`
+	// SkipIfUnaffected skips the build if the changed files don't belong to any of the projects
+	SkipIfUnaffected bool `json:"skip_if_unaffected,omitempty"`
+}
+
+// ReadFile reads the project maps from a JSON file, which maps app slugs to project maps, e.g.:
+//
+//	{"my-app-slug": {"projects": {"ios": ["ios/**", "shared/**"], "android": ["android/**"]}, "skip_if_unaffected": true}}
+func ReadFile(pth string) (map[string]Map, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read project map file (%s)", pth)
+	}
+
+	var mapsByApp map[string]Map
+	if err := json.Unmarshal(content, &mapsByApp); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse project map file (%s)", pth)
+	}
+	for appSlug, projectMap := range mapsByApp {
+		if err := projectMap.validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid project map for app (%s)", appSlug)
+		}
+	}
+
+	return mapsByApp, nil
+}
+
+// Affected returns the sorted names of the projects which have at least one changed file.
+func (projectMap Map) Affected(changedPaths []string) []string {
+	var affected []string
+	for name, patterns := range projectMap.Projects {
+		if glob.MatchAnyOf(patterns, changedPaths) {
+			affected = append(affected, name)
+		}
+	}
+	sort.Strings(affected)
+	return affected
+}
+
+// EnvValue returns the value of the AffectedProjectsEnvKey env var.
+func EnvValue(affected []string) string {
+	return strings.Join(affected, ",")
+}
+
+func (projectMap Map) validate() error {
+	if len(projectMap.Projects) == 0 {
+		return errors.New("no projects defined")
+	}
+	for name, patterns := range projectMap.Projects {
+		if name == "" || strings.Contains(name, ",") {
+			return fmt.Errorf("invalid project name: %q", name)
+		}
+		if len(patterns) == 0 {
+			return fmt.Errorf("no paths defined for project: %s", name)
+		}
+		for _, pattern := range patterns {
+			if err := glob.Validate(pattern); err != nil {
+				return fmt.Errorf("invalid pattern (%s) of project (%s): %s", pattern, name, err)
+			}
+		}
+	}
+	return nil
+}
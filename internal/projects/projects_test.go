@@ -0,0 +1,65 @@
+package projects
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMap_Affected(t *testing.T) {
+	projectMap := Map{Projects: map[string][]string{
+		"ios":     {"ios/**", "shared/**"},
+		"android": {"android/**", "shared/**"},
+		"docs":    {"*.md"},
+	}}
+
+	require.Equal(t, []string{"ios"}, projectMap.Affected([]string{"ios/App/AppDelegate.swift"}))
+	require.Equal(t, []string{"android", "ios"}, projectMap.Affected([]string{"shared/strings.json"}))
+	require.Equal(t, []string{"android", "docs"}, projectMap.Affected([]string{"README.md", "android/app/build.gradle"}))
+	require.Nil(t, projectMap.Affected([]string{"scripts/release.sh"}))
+	require.Nil(t, projectMap.Affected(nil))
+
+	require.Equal(t, "android,docs", EnvValue([]string{"android", "docs"}))
+	require.Equal(t, "", EnvValue(nil))
+}
+
+func TestReadFile(t *testing.T) {
+	t.Log("Valid project map file")
+	{
+		pth := filepath.Join(t.TempDir(), "projects.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{
+  "app-slug": {"projects": {"ios": ["ios/**"], "android": ["android/**"]}, "skip_if_unaffected": true}
+}`), 0600))
+
+		mapsByApp, err := ReadFile(pth)
+		require.NoError(t, err)
+		require.Equal(t, Map{
+			Projects:         map[string][]string{"ios": {"ios/**"}, "android": {"android/**"}},
+			SkipIfUnaffected: true,
+		}, mapsByApp["app-slug"])
+	}
+
+	t.Log("Invalid project maps")
+	{
+		for content, wantErr := range map[string]string{
+			`{"app-slug": {}}`:                                   "invalid project map for app (app-slug): no projects defined",
+			`{"app-slug": {"projects": {"ios": []}}}`:            "invalid project map for app (app-slug): no paths defined for project: ios",
+			`{"app-slug": {"projects": {"ios,tv": ["ios/**"]}}}`: `invalid project map for app (app-slug): invalid project name: "ios,tv"`,
+			`{"app-slug": {"projects": {"ios": ["[a-"]}}}`:       "invalid project map for app (app-slug): invalid pattern ([a-) of project (ios): syntax error in pattern",
+		} {
+			pth := filepath.Join(t.TempDir(), "projects.json")
+			require.NoError(t, os.WriteFile(pth, []byte(content), 0600))
+
+			_, err := ReadFile(pth)
+			require.EqualError(t, err, wantErr)
+		}
+	}
+
+	t.Log("Missing file")
+	{
+		_, err := ReadFile(filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	}
+}
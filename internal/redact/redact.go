@@ -0,0 +1,95 @@
+package redact
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Mask is the value secrets are replaced with.
+const Mask = "[REDACTED]"
+
+// sensitiveHeaders are the request headers which include credentials or signatures.
+var sensitiveHeaders = []string{
+	"Api-Token",
+	"Authorization",
+	"X-Hub-Signature",
+	"X-Hub-Signature-256",
+	"X-Gitlab-Token",
+	"X-Slack-Signature",
+	"X-Gitea-Signature",
+	"X-Forgejo-Signature",
+}
+
+// sensitiveValueKeys are the query / form value keys which include credentials,
+// e.g. the "token" field of Slack's requests.
+var sensitiveValueKeys = []string{
+	"token",
+	"api_token",
+	"api-token",
+	"secret",
+}
+
+// SensitiveHeaders returns the canonical names of the headers which should never be logged or traced.
+func SensitiveHeaders() []string {
+	headers := make([]string, len(sensitiveHeaders))
+	for i, header := range sensitiveHeaders {
+		headers[i] = http.CanonicalHeaderKey(header)
+	}
+	return headers
+}
+
+// Path masks the API token segment of the /h/{service-id}/{app-slug}/{api-token} hook route.
+func Path(pth string) string {
+	segments := strings.Split(pth, "/")
+	// "/h/service-id/app-slug/api-token" splits to ["", "h", "service-id", "app-slug", "api-token"]
+	if len(segments) >= 5 && segments[0] == "" && segments[1] == "h" && segments[4] != "" {
+		segments[4] = Mask
+	}
+	return strings.Join(segments, "/")
+}
+
+// Values returns a copy of the query or form values, with the sensitive values masked.
+func Values(values url.Values) url.Values {
+	redacted := url.Values{}
+	for key, vals := range values {
+		if isSensitiveValueKey(key) {
+			redacted[key] = []string{Mask}
+			continue
+		}
+		redacted[key] = vals
+	}
+	return redacted
+}
+
+// RequestURI masks the API token path segment and the sensitive query values of a request URI.
+func RequestURI(requestURI string) string {
+	u, err := url.ParseRequestURI(requestURI)
+	if err != nil {
+		// don't risk logging anything sensitive from an unparsable URI
+		return Mask
+	}
+	return URL(u)
+}
+
+// URL masks the API token path segment and the sensitive query values of a URL.
+func URL(u *url.URL) string {
+	redacted := *u
+	redacted.User = nil
+	redacted.Path = Path(u.Path)
+	redacted.RawPath = ""
+	if u.RawQuery != "" {
+		redacted.RawQuery = Values(u.Query()).Encode()
+	}
+	// the mask must not be escaped, to keep it readable
+	return strings.ReplaceAll(redacted.String(), url.PathEscape(Mask), Mask)
+}
+
+func isSensitiveValueKey(key string) bool {
+	for _, sensitiveKey := range sensitiveValueKeys {
+		if strings.EqualFold(key, sensitiveKey) {
+			return true
+		}
+	}
+	return false
+}
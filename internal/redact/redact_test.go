@@ -0,0 +1,59 @@
+package redact
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestURI(t *testing.T) {
+	tests := []struct {
+		name       string
+		requestURI string
+		want       string
+	}{
+		{
+			name:       "Hook route with API token",
+			requestURI: "/h/github/app-slug/secret-api-token",
+			want:       "/h/github/app-slug/[REDACTED]",
+		},
+		{
+			name:       "Hook ID route",
+			requestURI: "/h/github/my-hook-id",
+			want:       "/h/github/my-hook-id",
+		},
+		{
+			name:       "Token in the query",
+			requestURI: "/h/slack/app-slug/secret-api-token?token=slack-token&text=hello",
+			want:       "/h/slack/app-slug/[REDACTED]?text=hello&token=[REDACTED]",
+		},
+		{
+			name:       "Root",
+			requestURI: "/",
+			want:       "/",
+		},
+		{
+			name:       "Unparsable URI",
+			requestURI: "not a request uri",
+			want:       "[REDACTED]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, RequestURI(tt.requestURI))
+		})
+	}
+}
+
+func TestValues(t *testing.T) {
+	values := url.Values{
+		"command": {"/bitrise"},
+		"token":   {"slack-verification-token"},
+	}
+
+	require.Equal(t, url.Values{
+		"command": {"/bitrise"},
+		"token":   {Mask},
+	}, Values(values))
+}
@@ -0,0 +1,193 @@
+package rules
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	"github.com/bitrise-io/bitrise-webhooks/internal/glob"
+)
+
+// Action is what happens with the build, if the conditions of the rule are met.
+type Action string
+
+const (
+	// ActionAllow triggers the build as-it-is
+	ActionAllow Action = "allow"
+	// ActionSkip doesn't trigger the build
+	ActionSkip Action = "skip"
+	// ActionSetWorkflow triggers the build with the rule's workflow
+	ActionSetWorkflow Action = "set_workflow"
+)
+
+// Event types, derived from the build parameters, so that they are the same for every provider.
+const (
+	EventPush               = "push"
+	EventTag                = "tag"
+	EventPullRequest        = "pull_request"
+	EventPullRequestComment = "pull_request_comment"
+)
+
+// Conditions of a rule: every non-empty condition has to be met for the rule to match,
+// and a condition is met if any of its values matches.
+// Branch, target branch, tag and path values are glob patterns (see glob.Match).
+type Conditions struct {
+	Providers      []string `json:"provider,omitempty"`
+	Events         []string `json:"event,omitempty"`
+	Branches       []string `json:"branch,omitempty"`
+	TargetBranches []string `json:"target_branch,omitempty"`
+	Tags           []string `json:"tag,omitempty"`
+	// Paths is met if any of the changed files (PushCommitPaths) matches
+	Paths   []string `json:"paths,omitempty"`
+	Labels  []string `json:"labels,omitempty"`
+	Authors []string `json:"author,omitempty"`
+}
+
+// Rule ...
+type Rule struct {
+	If     Conditions `json:"if"`
+	Action Action     `json:"action"`
+	// Reason is reported for the skipped builds
+	Reason string `json:"reason,omitempty"`
+	// WorkflowID is the workflow of the ActionSetWorkflow action
+	WorkflowID string `json:"workflow_id,omitempty"`
+}
+
+// Rules are evaluated in order, the first matching rule decides what happens with the build.
+type Rules []Rule
+
+// ReadFile reads the rules from a JSON file, which maps app slugs to rules, e.g.:
+//
+//	{"my-app-slug": [{"if": {"event": ["push"], "paths": ["docs/**"]}, "action": "skip", "reason": "docs only"}]}
+func ReadFile(pth string) (map[string]Rules, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read rules file (%s)", pth)
+	}
+
+	var rulesByApp map[string]Rules
+	if err := json.Unmarshal(content, &rulesByApp); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse rules file (%s)", pth)
+	}
+	for appSlug, rules := range rulesByApp {
+		if err := rules.validate(); err != nil {
+			return nil, errors.Wrapf(err, "invalid rules for app (%s)", appSlug)
+		}
+	}
+
+	return rulesByApp, nil
+}
+
+// Match returns the first rule which matches the build, or nil if none of them does.
+func (rules Rules) Match(providerID string, triggerParams bitriseapi.TriggerAPIParamsModel) *Rule {
+	for i := range rules {
+		if rules[i].If.match(providerID, triggerParams) {
+			return &rules[i]
+		}
+	}
+	return nil
+}
+
+// EventType returns the type of the event the build was triggered for.
+func EventType(buildParams bitriseapi.BuildParamsModel) string {
+	switch {
+	case buildParams.Tag != "":
+		return EventTag
+	case buildParams.PullRequestComment != "":
+		return EventPullRequestComment
+	case buildParams.PullRequestID != nil || buildParams.BranchDest != "":
+		return EventPullRequest
+	default:
+		return EventPush
+	}
+}
+
+// author returns the pull request's author, or the user who triggered the build (see common.GenerateTriggeredBy).
+func author(triggerParams bitriseapi.TriggerAPIParamsModel) string {
+	if triggerParams.BuildParams.PullRequestAuthor != "" {
+		return triggerParams.BuildParams.PullRequestAuthor
+	}
+	if i := strings.Index(triggerParams.TriggeredBy, "/"); i != -1 {
+		return triggerParams.TriggeredBy[i+1:]
+	}
+	return ""
+}
+
+func (conditions Conditions) match(providerID string, triggerParams bitriseapi.TriggerAPIParamsModel) bool {
+	buildParams := triggerParams.BuildParams
+
+	if len(conditions.Providers) > 0 && !slices.Contains(conditions.Providers, providerID) {
+		return false
+	}
+	if len(conditions.Events) > 0 && !slices.Contains(conditions.Events, EventType(buildParams)) {
+		return false
+	}
+	if len(conditions.Branches) > 0 && (buildParams.Branch == "" || !glob.MatchAny(conditions.Branches, buildParams.Branch)) {
+		return false
+	}
+	if len(conditions.TargetBranches) > 0 && (buildParams.BranchDest == "" || !glob.MatchAny(conditions.TargetBranches, buildParams.BranchDest)) {
+		return false
+	}
+	if len(conditions.Tags) > 0 && (buildParams.Tag == "" || !glob.MatchAny(conditions.Tags, buildParams.Tag)) {
+		return false
+	}
+	if len(conditions.Paths) > 0 && !glob.MatchAnyOf(conditions.Paths, buildParams.ChangedPaths()) {
+		return false
+	}
+	if len(conditions.Labels) > 0 && !containsAny(conditions.Labels, buildParams.PullRequestLabels) {
+		return false
+	}
+	if len(conditions.Authors) > 0 && !slices.Contains(conditions.Authors, author(triggerParams)) {
+		return false
+	}
+	return true
+}
+
+func containsAny(values []string, others []string) bool {
+	for _, other := range others {
+		if slices.Contains(values, other) {
+			return true
+		}
+	}
+	return false
+}
+
+func (rules Rules) validate() error {
+	for i, rule := range rules {
+		if err := rule.validate(); err != nil {
+			return fmt.Errorf("rule #%d: %s", i+1, err)
+		}
+	}
+	return nil
+}
+
+func (rule Rule) validate() error {
+	switch rule.Action {
+	case ActionAllow, ActionSkip:
+	case ActionSetWorkflow:
+		if rule.WorkflowID == "" {
+			return fmt.Errorf("missing workflow_id of the %s action", ActionSetWorkflow)
+		}
+	default:
+		return fmt.Errorf("unsupported action: %s", rule.Action)
+	}
+
+	for _, patterns := range [][]string{rule.If.Branches, rule.If.TargetBranches, rule.If.Tags, rule.If.Paths} {
+		for _, pattern := range patterns {
+			if err := glob.Validate(pattern); err != nil {
+				return fmt.Errorf("invalid pattern (%s): %s", pattern, err)
+			}
+		}
+	}
+	for _, event := range rule.If.Events {
+		if !slices.Contains([]string{EventPush, EventTag, EventPullRequest, EventPullRequestComment}, event) {
+			return fmt.Errorf("unsupported event: %s", event)
+		}
+	}
+	return nil
+}
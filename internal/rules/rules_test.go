@@ -0,0 +1,120 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+)
+
+func TestRules_Match(t *testing.T) {
+	pullRequestID := 7
+	push := bitriseapi.TriggerAPIParamsModel{
+		BuildParams: bitriseapi.BuildParamsModel{
+			Branch: "feature/login",
+			PushCommitPaths: []bitriseapi.CommitPaths{
+				{Added: []string{"docs/login.md"}, Modified: []string{"README.md"}},
+			},
+		},
+		TriggeredBy: "webhook-github/jane",
+	}
+	pullRequest := bitriseapi.TriggerAPIParamsModel{
+		BuildParams: bitriseapi.BuildParamsModel{
+			Branch:            "dependabot/npm/lodash",
+			BranchDest:        "main",
+			PullRequestID:     &pullRequestID,
+			PullRequestAuthor: "dependabot[bot]",
+			PullRequestLabels: []string{"dependencies", "javascript"},
+		},
+	}
+	tag := bitriseapi.TriggerAPIParamsModel{
+		BuildParams: bitriseapi.BuildParamsModel{Tag: "v1.2.0"},
+	}
+
+	rules := Rules{
+		{If: Conditions{Providers: []string{"gitlab"}}, Action: ActionSkip, Reason: "gitlab"},
+		{If: Conditions{Events: []string{EventPush}, Paths: []string{"docs/**", "*.md"}}, Action: ActionSkip, Reason: "docs"},
+		{If: Conditions{TargetBranches: []string{"main"}, Authors: []string{"dependabot[bot]"}, Labels: []string{"dependencies"}}, Action: ActionSetWorkflow, WorkflowID: "dependencies"},
+		{If: Conditions{Tags: []string{"v*"}}, Action: ActionAllow},
+	}
+
+	t.Log("Provider condition")
+	{
+		require.Equal(t, "gitlab", rules.Match("gitlab", push).Reason)
+	}
+
+	t.Log("Event and paths conditions")
+	{
+		require.Equal(t, "docs", rules.Match("github", push).Reason)
+
+		noDocsPush := push
+		noDocsPush.BuildParams.PushCommitPaths = []bitriseapi.CommitPaths{{Modified: []string{"ios/App.swift"}}}
+		require.Nil(t, rules.Match("github", noDocsPush))
+	}
+
+	t.Log("Target branch, author and labels conditions")
+	{
+		require.Equal(t, "dependencies", rules.Match("github", pullRequest).WorkflowID)
+
+		otherAuthor := pullRequest
+		otherAuthor.BuildParams.PullRequestAuthor = "jane"
+		require.Nil(t, rules.Match("github", otherAuthor))
+	}
+
+	t.Log("Tag condition")
+	{
+		require.Equal(t, ActionAllow, rules.Match("github", tag).Action)
+	}
+
+	t.Log("No rules")
+	{
+		require.Nil(t, Rules(nil).Match("github", push))
+	}
+}
+
+func TestEventType(t *testing.T) {
+	pullRequestID := 1
+	require.Equal(t, EventPush, EventType(bitriseapi.BuildParamsModel{Branch: "main"}))
+	require.Equal(t, EventTag, EventType(bitriseapi.BuildParamsModel{Tag: "v1.0.0"}))
+	require.Equal(t, EventPullRequest, EventType(bitriseapi.BuildParamsModel{Branch: "feature", PullRequestID: &pullRequestID}))
+	require.Equal(t, EventPullRequestComment, EventType(bitriseapi.BuildParamsModel{Branch: "feature", PullRequestID: &pullRequestID, PullRequestComment: "retest"}))
+}
+
+func TestReadFile(t *testing.T) {
+	t.Log("Valid rules file")
+	{
+		pth := filepath.Join(t.TempDir(), "rules.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{
+  "app-slug": [
+    {"if": {"event": ["push"], "paths": ["docs/**"]}, "action": "skip", "reason": "docs only"},
+    {"if": {"branch": ["release/*"]}, "action": "set_workflow", "workflow_id": "release"}
+  ]
+}`), 0600))
+
+		rulesByApp, err := ReadFile(pth)
+		require.NoError(t, err)
+		require.Equal(t, Rules{
+			{If: Conditions{Events: []string{"push"}, Paths: []string{"docs/**"}}, Action: ActionSkip, Reason: "docs only"},
+			{If: Conditions{Branches: []string{"release/*"}}, Action: ActionSetWorkflow, WorkflowID: "release"},
+		}, rulesByApp["app-slug"])
+	}
+
+	t.Log("Invalid rules")
+	{
+		for content, wantErr := range map[string]string{
+			`{"app-slug": [{"action": "deploy"}]}`:                           "invalid rules for app (app-slug): rule #1: unsupported action: deploy",
+			`{"app-slug": [{"action": "set_workflow"}]}`:                     "invalid rules for app (app-slug): rule #1: missing workflow_id of the set_workflow action",
+			`{"app-slug": [{"if": {"event": ["merge"]}, "action": "skip"}]}`: "invalid rules for app (app-slug): rule #1: unsupported event: merge",
+			`{"app-slug": [{"if": {"branch": ["[a-"]}, "action": "skip"}]}`:  "invalid rules for app (app-slug): rule #1: invalid pattern ([a-): syntax error in pattern",
+		} {
+			pth := filepath.Join(t.TempDir(), "rules.json")
+			require.NoError(t, os.WriteFile(pth, []byte(content), 0600))
+
+			_, err := ReadFile(pth)
+			require.EqualError(t, err, wantErr)
+		}
+	}
+}
@@ -1,19 +1,40 @@
 package main
 
 import (
+	"context"
+	"encoding/json"
 	"flag"
 	"log"
 	"net/http"
 	"net/url"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"syscall"
+	"time"
 
 	_ "go.uber.org/automaxprocs"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
 	"github.com/bitrise-io/bitrise-webhooks/config"
+	"github.com/bitrise-io/bitrise-webhooks/internal/credentials"
+	"github.com/bitrise-io/bitrise-webhooks/internal/dedup"
+	"github.com/bitrise-io/bitrise-webhooks/internal/executor"
+	"github.com/bitrise-io/bitrise-webhooks/internal/outbox"
+	"github.com/bitrise-io/bitrise-webhooks/internal/projects"
 	"github.com/bitrise-io/bitrise-webhooks/internal/pubsub"
+	"github.com/bitrise-io/bitrise-webhooks/internal/rules"
+	"github.com/bitrise-io/bitrise-webhooks/service/hook"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/generic"
 )
 
+// defaultShutdownTimeout fits into Kubernetes' default 30s termination grace period
+const defaultShutdownTimeout = 25 * time.Second
+
 func main() {
 	err := tracer.Start(tracer.WithService("webhooks"))
 	if err != nil {
@@ -21,10 +42,25 @@ func main() {
 	}
 	defer tracer.Stop()
 	var (
-		portFlag            = flag.String("port", "", `Use port [$PORT]`)
-		sendRequestToFlag   = flag.String("send-request-to", "", `Send requests to this URL. If set, every request will be sent to this URL and not to bitrise.io. You can use this to debug/test, e.g. with http://requestb.in [$SEND_REQUEST_TO]`)
-		logOnlyModeFlag     = flag.Bool("log-only-mode", false, `Only print log messages without triggering builds [$LOG_ONLY_MODE]`)
-		buildTriggerURLFlag = flag.String("build-trigger-url", "", "URL to send build trigger requests to [$BUILD_TRIGGER_URL]")
+		portFlag             = flag.String("port", "", `Use port [$PORT]`)
+		sendRequestToFlag    = flag.String("send-request-to", "", `Send requests to this URL. If set, every request will be sent to this URL and not to bitrise.io. You can use this to debug/test, e.g. with http://requestb.in [$SEND_REQUEST_TO]`)
+		logOnlyModeFlag      = flag.Bool("log-only-mode", false, `Only print log messages without triggering builds [$LOG_ONLY_MODE]`)
+		buildTriggerURLFlag  = flag.String("build-trigger-url", "", "URL to send build trigger requests to [$BUILD_TRIGGER_URL]")
+		credentialStoreFlag  = flag.String("credential-store", "", `Resolve hook IDs of the /h/SERVICE/HOOK-ID route from a credential store: "env" or "file:/path/to/credentials.json" [$CREDENTIAL_STORE]`)
+		webhookSecretsFlag   = flag.String("webhook-secrets", "", `JSON object of webhook secrets, keyed by app slug or by "service-id/app-slug" [$WEBHOOK_SECRETS]`)
+		webhookUsernamesFlag = flag.String("webhook-usernames", "", `JSON object of the Basic authentication usernames of the webhooks (e.g. Azure DevOps service hooks), keyed the same way as the webhook secrets [$WEBHOOK_USERNAMES]`)
+		deliveryDedupTTLFlag = flag.String("delivery-dedup-ttl", "", `Deduplicate the redeliveries of the webhooks by the providers' delivery IDs, for this long (e.g. "24h") [$DELIVERY_DEDUP_TTL]`)
+		shutdownTimeoutFlag  = flag.String("shutdown-timeout", "", `On SIGTERM wait at most this long for the in-flight requests and build triggers to finish (default: 25s) [$SHUTDOWN_TIMEOUT]`)
+		triggerWorkersFlag   = flag.String("trigger-workers", "", `Number of the workers sending the background Build Trigger calls (default: 10) [$TRIGGER_WORKERS]`)
+		triggerQueueFlag     = flag.String("trigger-queue-size", "", `Number of the background Build Trigger calls which can wait for a free worker, before the calls are sent synchronously (default: 100) [$TRIGGER_QUEUE_SIZE]`)
+		triggerTimeoutFlag   = flag.String("trigger-job-timeout", "", `Timeout of a background Build Trigger call (default: 2m) [$TRIGGER_JOB_TIMEOUT]`)
+		retryQueueDirFlag    = flag.String("retry-queue-dir", "", `Queue the Build Trigger calls which failed with a network error or a 5xx response in this directory, and retry them with exponential backoff [$RETRY_QUEUE_DIR]`)
+		routingRulesFlag     = flag.String("routing-rules", "", `Path of the JSON file which defines the per app rules deciding whether a build is triggered for a webhook, keyed by app slug [$ROUTING_RULES]`)
+		projectMapFlag       = flag.String("project-map", "", `Path of the JSON file which maps the files of the apps' (mono)repositories to projects, to set the affected projects of the builds, keyed by app slug [$PROJECT_MAP]`)
+		labelWorkflowsFlag   = flag.String("label-workflows", "", `Path of the JSON file which maps the newly added pull request labels to the workflows to trigger, keyed by app slug [$LABEL_WORKFLOWS]`)
+		chatOpsFlag          = flag.String("chatops", "", `Path of the JSON file which enables the ChatOps commands of the pull request comments (e.g. "/bitrise run deploy"), keyed by app slug: the comments of these apps trigger builds only if they include a command [$CHATOPS]`)
+		codeCommitTopicsFlag = flag.String("codecommit-topic-arns", "", `Comma separated list of the ARNs of the SNS topics whose messages are accepted (and whose subscriptions are confirmed) by the codecommit provider [$CODECOMMIT_TOPIC_ARNS]`)
+		genericMappingsFlag  = flag.String("generic-mappings", "", `Path of the JSON file which defines how the payloads of the /h/generic/... webhooks are mapped to build parameters, keyed by hook ID or app slug [$GENERIC_MAPPINGS]`)
 	)
 	flag.Parse()
 
@@ -34,6 +70,42 @@ func main() {
 	}
 	config.SetupServerEnvMode()
 
+	shutdownTimeout := defaultShutdownTimeout
+	if shutdownTimeoutStr := stringFlagOrEnv(shutdownTimeoutFlag, "SHUTDOWN_TIMEOUT"); shutdownTimeoutStr != "" {
+		timeout, err := time.ParseDuration(shutdownTimeoutStr)
+		if err != nil || timeout <= 0 {
+			log.Fatalf("Failed to parse shutdown-timeout (%s) as a positive duration, error: %v", shutdownTimeoutStr, err)
+		}
+		shutdownTimeout = timeout
+	}
+
+	triggerWorkers := executor.DefaultWorkers
+	if triggerWorkersStr := stringFlagOrEnv(triggerWorkersFlag, "TRIGGER_WORKERS"); triggerWorkersStr != "" {
+		workers, err := strconv.Atoi(triggerWorkersStr)
+		if err != nil || workers <= 0 {
+			log.Fatalf("Failed to parse trigger-workers (%s) as a positive number, error: %v", triggerWorkersStr, err)
+		}
+		triggerWorkers = workers
+	}
+
+	triggerQueueSize := executor.DefaultQueueSize
+	if triggerQueueSizeStr := stringFlagOrEnv(triggerQueueFlag, "TRIGGER_QUEUE_SIZE"); triggerQueueSizeStr != "" {
+		queueSize, err := strconv.Atoi(triggerQueueSizeStr)
+		if err != nil || queueSize < 0 {
+			log.Fatalf("Failed to parse trigger-queue-size (%s) as a non-negative number, error: %v", triggerQueueSizeStr, err)
+		}
+		triggerQueueSize = queueSize
+	}
+
+	triggerJobTimeout := executor.DefaultJobTimeout
+	if triggerJobTimeoutStr := stringFlagOrEnv(triggerTimeoutFlag, "TRIGGER_JOB_TIMEOUT"); triggerJobTimeoutStr != "" {
+		timeout, err := time.ParseDuration(triggerJobTimeoutStr)
+		if err != nil || timeout <= 0 {
+			log.Fatalf("Failed to parse trigger-job-timeout (%s) as a positive duration, error: %v", triggerJobTimeoutStr, err)
+		}
+		triggerJobTimeout = timeout
+	}
+
 	requestToStr := stringFlagOrEnv(sendRequestToFlag, "SEND_REQUEST_TO")
 	if requestToStr != "" {
 		url, err := url.Parse(requestToStr)
@@ -60,6 +132,128 @@ func main() {
 
 	config.LogOnlyMode = logOnlyMode
 
+	if webhookSecrets := stringFlagOrEnv(webhookSecretsFlag, "WEBHOOK_SECRETS"); webhookSecrets != "" {
+		if err := json.Unmarshal([]byte(webhookSecrets), &config.WebhookSecrets); err != nil {
+			log.Fatalf("Failed to parse webhook-secrets as a JSON object, error: %s", err)
+		}
+		log.Printf(" (i) Webhook signature verification enabled for %d app(s) / route(s)", len(config.WebhookSecrets))
+	}
+
+	if webhookUsernames := stringFlagOrEnv(webhookUsernamesFlag, "WEBHOOK_USERNAMES"); webhookUsernames != "" {
+		if err := json.Unmarshal([]byte(webhookUsernames), &config.WebhookUsernames); err != nil {
+			log.Fatalf("Failed to parse webhook-usernames as a JSON object, error: %s", err)
+		}
+		log.Printf(" (i) Webhook username verification enabled for %d app(s) / route(s)", len(config.WebhookUsernames))
+	}
+
+	var credentialStore credentials.Store
+	if credentialStoreStr := stringFlagOrEnv(credentialStoreFlag, "CREDENTIAL_STORE"); credentialStoreStr == "env" {
+		credentialStore = credentials.NewEnvStore(credentials.DefaultEnvPrefix)
+		log.Printf(" (i) Resolving hook IDs from environment variables (%s...)", credentials.DefaultEnvPrefix)
+	} else if strings.HasPrefix(credentialStoreStr, "file:") {
+		pth := strings.TrimPrefix(credentialStoreStr, "file:")
+		store, err := credentials.NewFileStore(pth)
+		if err != nil {
+			log.Fatalf("Failed to init credential store, error: %s", err)
+		}
+		credentialStore = store
+		log.Printf(" (i) Resolving hook IDs from credentials file: %s", pth)
+	} else if credentialStoreStr != "" {
+		log.Fatalf("Unsupported credential-store (%s), should be either \"env\" or \"file:/path/to/credentials.json\"", credentialStoreStr)
+	}
+
+	var genericMappings map[string]*generic.Mapping
+	if genericMappingsPth := stringFlagOrEnv(genericMappingsFlag, "GENERIC_MAPPINGS"); genericMappingsPth != "" {
+		mappings, err := generic.ReadMappingsFile(genericMappingsPth)
+		if err != nil {
+			log.Fatalf("Failed to read generic mappings, error: %s", err)
+		}
+		genericMappings = mappings
+		log.Printf(" (i) Generic webhook mappings defined for %d route(s)", len(genericMappings))
+	}
+
+	var routingRules map[string]rules.Rules
+	if routingRulesPth := stringFlagOrEnv(routingRulesFlag, "ROUTING_RULES"); routingRulesPth != "" {
+		rulesByApp, err := rules.ReadFile(routingRulesPth)
+		if err != nil {
+			log.Fatalf("Failed to read routing rules, error: %s", err)
+		}
+		routingRules = rulesByApp
+		log.Printf(" (i) Routing rules defined for %d app(s)", len(routingRules))
+	}
+
+	var projectMaps map[string]projects.Map
+	if projectMapPth := stringFlagOrEnv(projectMapFlag, "PROJECT_MAP"); projectMapPth != "" {
+		mapsByApp, err := projects.ReadFile(projectMapPth)
+		if err != nil {
+			log.Fatalf("Failed to read project map, error: %s", err)
+		}
+		projectMaps = mapsByApp
+		log.Printf(" (i) Project maps defined for %d app(s)", len(projectMaps))
+	}
+
+	var labelWorkflows map[string]hookCommon.LabelWorkflows
+	if labelWorkflowsPth := stringFlagOrEnv(labelWorkflowsFlag, "LABEL_WORKFLOWS"); labelWorkflowsPth != "" {
+		labelWorkflowsByApp, err := hookCommon.ReadLabelWorkflowsFile(labelWorkflowsPth)
+		if err != nil {
+			log.Fatalf("Failed to read label workflows, error: %s", err)
+		}
+		labelWorkflows = labelWorkflowsByApp
+		log.Printf(" (i) Label workflows defined for %d app(s)", len(labelWorkflows))
+	}
+
+	var codeCommitTopicArns []string
+	if topicArns := stringFlagOrEnv(codeCommitTopicsFlag, "CODECOMMIT_TOPIC_ARNS"); topicArns != "" {
+		for _, topicArn := range strings.Split(topicArns, ",") {
+			codeCommitTopicArns = append(codeCommitTopicArns, strings.TrimSpace(topicArn))
+		}
+		log.Printf(" (i) CodeCommit SNS messages are accepted from %d topic(s)", len(codeCommitTopicArns))
+	}
+
+	var chatOps map[string]*hookCommon.ChatOpsConfig
+	if chatOpsPth := stringFlagOrEnv(chatOpsFlag, "CHATOPS"); chatOpsPth != "" {
+		chatOpsByApp, err := hookCommon.ReadChatOpsFile(chatOpsPth)
+		if err != nil {
+			log.Fatalf("Failed to read ChatOps configs, error: %s", err)
+		}
+		chatOps = chatOpsByApp
+		log.Printf(" (i) ChatOps commands enabled for the pull request comments of %d app(s)", len(chatOps))
+	}
+
+	// the outbox is stopped on shutdown, outboxDone is closed once its in-progress retries are finished
+	outboxCtx, stopOutbox := context.WithCancel(context.Background())
+	defer stopOutbox()
+	outboxDone := make(chan struct{})
+	var triggerOutbox *outbox.Outbox
+	if retryQueueDir := stringFlagOrEnv(retryQueueDirFlag, "RETRY_QUEUE_DIR"); retryQueueDir != "" {
+		store, err := outbox.NewFileStore(retryQueueDir)
+		if err != nil {
+			log.Fatalf("Failed to init retry queue, error: %s", err)
+		}
+		triggerOutbox = outbox.New(store, func(ctx context.Context, triggerURL *url.URL, apiToken string, params bitriseapi.TriggerAPIParamsModel) (bitriseapi.TriggerAPIResponseModel, bool, error) {
+			return bitriseapi.TriggerBuild(ctx, triggerURL, apiToken, params, config.LogOnlyMode)
+		})
+		// the calls of the hook IDs are stored without the API token, it's resolved again on retry
+		triggerOutbox.CredentialStore = credentialStore
+		go func() {
+			triggerOutbox.Run(outboxCtx, outbox.DefaultPollInterval)
+			close(outboxDone)
+		}()
+		log.Printf(" (i) Failed Build Triggers will be retried, retry queue: %s", retryQueueDir)
+	} else {
+		close(outboxDone)
+	}
+
+	var deliveryStore dedup.Store
+	if deliveryDedupTTLStr := stringFlagOrEnv(deliveryDedupTTLFlag, "DELIVERY_DEDUP_TTL"); deliveryDedupTTLStr != "" {
+		deliveryDedupTTL, err := time.ParseDuration(deliveryDedupTTLStr)
+		if err != nil || deliveryDedupTTL <= 0 {
+			log.Fatalf("Failed to parse delivery-dedup-ttl (%s) as a positive duration, error: %v", deliveryDedupTTLStr, err)
+		}
+		deliveryStore = dedup.NewMemoryStore(deliveryDedupTTL)
+		log.Printf(" (i) Webhook redeliveries are deduplicated for %s", deliveryDedupTTL)
+	}
+
 	var (
 		pubsubServiceAccountJSON = os.Getenv("METRICS_PUBSUB_SERVICE_ACCOUNT_JSON")
 		pubsubTopicID            = os.Getenv("METRICS_PUBSUB_TOPIC_ID")
@@ -82,12 +276,79 @@ func main() {
 	// }
 
 	// Routing
-	setupRoutes(pubsubClient)
+	triggerExecutor := executor.New(triggerWorkers, triggerQueueSize, triggerJobTimeout)
+	setupRoutes(&hook.Client{
+		PubsubClient:        pubsubClient,
+		CredentialStore:     credentialStore,
+		Outbox:              triggerOutbox,
+		DeliveryStore:       deliveryStore,
+		TriggerExecutor:     triggerExecutor,
+		GenericMappings:     genericMappings,
+		CodeCommitTopicArns: codeCommitTopicArns,
+		RoutingRules:        routingRules,
+		ProjectMaps:         projectMaps,
+		ChatOps:             chatOps,
+		LabelWorkflows:      labelWorkflows,
+	})
+
+	inFlight := &inFlightCounter{}
+	server := &http.Server{
+		Addr:    ":" + port,
+		Handler: inFlight.wrap(http.DefaultServeMux),
+	}
+
+	serverErr := make(chan error, 1)
+	go func() {
+		log.Println("Starting - using port:", port)
+		serverErr <- server.ListenAndServe()
+	}()
+
+	signalCtx, stopSignals := signal.NotifyContext(context.Background(), syscall.SIGTERM, os.Interrupt)
+	defer stopSignals()
 
-	log.Println("Starting - using port:", port)
-	if err := http.ListenAndServe(":"+port, nil); err != nil {
+	select {
+	case err := <-serverErr:
 		log.Fatalf("Failed to ListenAndServe: %s", err)
+	case <-signalCtx.Done():
+	}
+
+	log.Printf("Shutting down - waiting at most %s for the in-flight requests and build triggers", shutdownTimeout)
+	shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancelShutdown()
+
+	// no new requests are accepted, the in-flight ones are waited for
+	if err := server.Shutdown(shutdownCtx); err != nil {
+		log.Printf(" [!] Shutdown timeout: %d in-flight request(s) abandoned", inFlight.count())
+	}
+	// the handlers are done, no new background build triggers can be started
+	if abandoned := triggerExecutor.Shutdown(shutdownCtx); abandoned > 0 {
+		log.Printf(" [!] Shutdown timeout: %d background build trigger(s) abandoned", abandoned)
 	}
+	stopOutbox()
+	select {
+	case <-outboxDone:
+	case <-shutdownCtx.Done():
+		log.Printf(" [!] Shutdown timeout: the in-progress build trigger retry abandoned, it'll be retried after restart")
+	}
+
+	log.Println("Shutdown complete")
+}
+
+// inFlightCounter counts the requests which are being handled.
+type inFlightCounter struct {
+	n atomic.Int64
+}
+
+func (c *inFlightCounter) wrap(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		c.n.Add(1)
+		defer c.n.Add(-1)
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (c *inFlightCounter) count() int64 {
+	return c.n.Load()
 }
 
 func stringFlagOrEnv(flagValue *string, envKey string) string {
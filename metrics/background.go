@@ -0,0 +1,29 @@
+package metrics
+
+import (
+	"context"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+)
+
+// Outcomes of the background Build Trigger calls
+const (
+	BackgroundTriggerSuccess = "success"
+	BackgroundTriggerFailed  = "failed"
+	BackgroundTriggerError   = "error"
+	BackgroundTriggerQueued  = "queued"
+)
+
+// StartBackgroundTrigger starts the span of a background Build Trigger call.
+// The returned function finishes the span, tagged with the call's outcome, so that the tracing backend
+// can derive the metrics (e.g. the failed trigger count) from it: no other metric is emitted for these calls.
+func StartBackgroundTrigger(ctx context.Context) (context.Context, func(outcome string, err error)) {
+	span, ctx := tracer.StartSpanFromContext(ctx, "background.trigger")
+
+	return ctx, func(outcome string, err error) {
+		span.SetTag("outcome", outcome)
+		span.SetTag(ext.ResourceName, "background.trigger."+outcome)
+		span.Finish(tracer.WithError(err))
+	}
+}
@@ -6,6 +6,8 @@ import (
 	"time"
 
 	"github.com/bitrise-io/api-utils/logging"
+
+	"github.com/bitrise-io/bitrise-webhooks/internal/redact"
 )
 
 // WrapHandlerFunc ...
@@ -15,7 +17,7 @@ func WrapHandlerFunc(h func(http.ResponseWriter, *http.Request)) func(http.Respo
 	requestWrap := func(w http.ResponseWriter, req *http.Request) {
 		startTime := time.Now()
 		h(w, req)
-		logger.Info(fmt.Sprintf(" => %s: %s - %s (%s)", req.Method, req.RequestURI, time.Since(startTime), req.Header.Get("Content-Type")))
+		logger.Info(fmt.Sprintf(" => %s: %s - %s (%s)", req.Method, redact.RequestURI(req.RequestURI), time.Since(startTime), req.Header.Get("Content-Type")))
 	}
 	return requestWrap
 	// if newRelicAgent == nil {
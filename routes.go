@@ -2,32 +2,39 @@ package main
 
 import (
 	"net/http"
+	"net/url"
 	"os"
+	"regexp"
+	"strings"
 
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
 	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
 
 	"github.com/DataDog/dd-trace-go/contrib/gorilla/mux/v2"
-	"github.com/bitrise-io/bitrise-webhooks/internal/pubsub"
+	"github.com/bitrise-io/bitrise-webhooks/internal/redact"
 	"github.com/bitrise-io/bitrise-webhooks/metrics"
 	"github.com/bitrise-io/bitrise-webhooks/service"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook"
 	"github.com/bitrise-io/bitrise-webhooks/service/root"
 )
 
-func setupRoutes(pubsubClient *pubsub.Client) {
+func setupRoutes(hookClient *hook.Client) {
 	r := mux.NewRouter(mux.WithService("webhooks"))
 	r.Use(dropTraceMiddleware())
+	r.Use(redactTraceMiddleware)
 
 	//
-	hookClient := hook.Client{PubsubClient: pubsubClient}
 	r.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", metrics.WrapHandlerFunc(hookClient.HTTPHandler)).
 		Methods("POST")
+	if hookClient.CredentialStore != nil {
+		r.HandleFunc("/h/{service-id}/{hook-id}", metrics.WrapHandlerFunc(hookClient.HTTPHandler)).
+			Methods("POST")
+	}
 	//
 	r.HandleFunc("/", metrics.WrapHandlerFunc(root.HTTPHandler)).
 		Methods("GET")
 	//
-	r.NotFoundHandler = http.HandlerFunc(metrics.WrapHandlerFunc(routeNotFoundHandler))
+	r.NotFoundHandler = redactTraceMiddleware(http.HandlerFunc(metrics.WrapHandlerFunc(routeNotFoundHandler)))
 	//
 	http.Handle("/", r)
 }
@@ -36,6 +43,41 @@ func routeNotFoundHandler(w http.ResponseWriter, r *http.Request) {
 	service.RespondWithNotFoundError(w, "Not Found")
 }
 
+var spanTagHeaderNameRegexp = regexp.MustCompile("[^a-z0-9_-]")
+
+// redactTraceMiddleware overwrites the request span's URL tag and header tags,
+// so that no API token or signature is sent to the tracing backend.
+func redactTraceMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if span, ok := tracer.SpanFromContext(r.Context()); ok {
+			span.SetTag(ext.HTTPURL, redact.URL(absoluteRequestURL(r)))
+			for _, header := range redact.SensitiveHeaders() {
+				if r.Header.Get(header) != "" {
+					span.SetTag(ext.HTTPRequestHeaders+"."+spanTagHeaderNameRegexp.ReplaceAllString(strings.ToLower(header), "_"), redact.Mask)
+				}
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// absoluteRequestURL returns the request's URL including the scheme and the host,
+// the same way as the tracer reports it.
+func absoluteRequestURL(r *http.Request) *url.URL {
+	u := *r.URL
+	if u.Host == "" {
+		u.Host = r.Host
+	}
+	if u.Scheme == "" && u.Host != "" {
+		u.Scheme = "http"
+		if r.TLS != nil {
+			u.Scheme = "https"
+		}
+	}
+	return &u
+}
+
 func dropTraceMiddleware() func(http.Handler) http.Handler {
 	header := os.Getenv("DROP_TRACE_HEADER")
 	if header == "" {
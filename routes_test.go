@@ -0,0 +1,37 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/ext"
+	"github.com/DataDog/dd-trace-go/v2/ddtrace/tracer"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_redactTraceMiddleware(t *testing.T) {
+	require.NoError(t, tracer.Start(tracer.WithTestDefaults(nil), tracer.WithLogStartup(false)))
+	defer tracer.Stop()
+
+	span := tracer.StartSpan("http.request")
+	defer span.Finish()
+
+	req := httptest.NewRequest(http.MethodPost, "/h/github/app-slug/api-token?token=slack-token&event=push", nil)
+	req.Header.Set("X-Hub-Signature-256", "sha256=abc")
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	req.Header.Set("X-Github-Event", "push")
+	req = req.WithContext(tracer.ContextWithSpan(req.Context(), span))
+
+	isCalled := false
+	redactTraceMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		isCalled = true
+	})).ServeHTTP(httptest.NewRecorder(), req)
+
+	require.True(t, isCalled)
+	tags := span.AsMap()
+	require.Equal(t, "http://example.com/h/github/app-slug/[REDACTED]?event=push&token=[REDACTED]", tags[ext.HTTPURL])
+	require.Equal(t, "[REDACTED]", tags[ext.HTTPRequestHeaders+".x-hub-signature-256"])
+	require.Equal(t, "[REDACTED]", tags[ext.HTTPRequestHeaders+".authorization"])
+	require.NotContains(t, tags, ext.HTTPRequestHeaders+".x-github-event")
+}
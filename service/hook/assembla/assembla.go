@@ -55,7 +55,21 @@ type PushEventModel struct {
 // --- Webhook Provider Implementation ---
 
 // HookProvider ...
-type HookProvider struct{}
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
 
 func detectContentType(header http.Header) (string, error) {
 	contentType := header.Get("Content-Type")
@@ -0,0 +1,41 @@
+package assembla
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// GatherMetrics ...
+func (hp HookProvider) GatherMetrics(r *http.Request, appSlug string) ([]common.Metrics, error) {
+	var pushEvent PushEventModel
+	if err := json.NewDecoder(r.Body).Decode(&pushEvent); err != nil {
+		return nil, err
+	}
+
+	currentTime := hp.timeProvider.CurrentTime()
+	metricsList := hp.gatherMetrics(pushEvent, appSlug, currentTime)
+	return metricsList, nil
+}
+
+func (hp HookProvider) gatherMetrics(pushEvent PushEventModel, appSlug string, currentTime time.Time) []common.Metrics {
+	space := pushEvent.SpaceEventModel
+	if space.Action != "pushed" && space.Action != "committed" {
+		return nil
+	}
+
+	git := pushEvent.GitEventModel
+	var gitRef string
+	if git.Branch != "" {
+		gitRef = "refs/heads/" + git.Branch
+	}
+
+	// Assembla doesn't send the previous commit, nor any timestamp
+	originalTrigger := common.OriginalTrigger(space.Object, space.Action)
+	generalMetrics := common.NewGeneralMetrics(ProviderID, space.Space, currentTime, nil, appSlug, originalTrigger, pushEvent.MessageEventModel.Author, gitRef)
+	metrics := common.NewPushMetrics(generalMetrics, git.CommitID, "", nil, nil, "")
+
+	return []common.Metrics{metrics}
+}
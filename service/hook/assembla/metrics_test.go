@@ -0,0 +1,63 @@
+package assembla
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookProvider_gatherMetrics(t *testing.T) {
+	currentTime := time.Date(2024, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		pushEvent PushEventModel
+		appSlug   string
+		want      string
+	}{
+		{
+			name: "Commit webhook",
+			pushEvent: PushEventModel{
+				SpaceEventModel:   SpaceEventModel{Space: "Space name", Action: "committed", Object: "Changeset"},
+				MessageEventModel: MessageEventModel{Title: "1 commits [branchname]", Body: "ErikPoort pushed 1 commits [branchname]\n", Author: "ErikPoort"},
+				GitEventModel:     GitEventModel{RepositorySuffix: "origin", RepositoryURL: "git@git.assembla.com:username/project.git", Branch: "branchname", CommitID: "sha1chars11"},
+			},
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"pushed","provider_type":"assembla","repository":"Space name","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"Changeset:committed","user_name":"ErikPoort","git_ref":"refs/heads/branchname","commit_id_after":"sha1chars11","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name: "Push webhook",
+			pushEvent: PushEventModel{
+				SpaceEventModel:   SpaceEventModel{Space: "Space name", Action: "pushed", Object: "Git Push"},
+				MessageEventModel: MessageEventModel{Author: "ErikPoort"},
+				GitEventModel:     GitEventModel{Branch: "master", CommitID: "sha1chars12"},
+			},
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"pushed","provider_type":"assembla","repository":"Space name","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"Git Push:pushed","user_name":"ErikPoort","git_ref":"refs/heads/master","commit_id_after":"sha1chars12","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name: "Unsupported webhook",
+			pushEvent: PushEventModel{
+				SpaceEventModel: SpaceEventModel{Space: "Space name", Action: "created", Object: "Ticket"},
+			},
+			appSlug: "slug",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hp := HookProvider{}
+			got := hp.gatherMetrics(tt.pushEvent, tt.appSlug, currentTime)
+			if tt.want != "" {
+				require.Equal(t, len(got), 1)
+				gotMetrics := got[0]
+				gotBytes, err := gotMetrics.Serialise()
+				require.NoError(t, err)
+				require.Equal(t, tt.want, string(gotBytes))
+			} else {
+				require.Nil(t, got)
+			}
+		})
+	}
+}
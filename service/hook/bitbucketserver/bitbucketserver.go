@@ -300,6 +300,19 @@ func isAcceptEventType(eventKey string) bool {
 	return slices.Contains([]string{"repo:refs_changed", "pr:opened", "pr:modified", "pr:merged", "diagnostics:ping", "pr:from_ref_updated", "pr:comment:added", "pr:comment:edited"}, eventKey)
 }
 
+// DeliveryID ...
+// Bitbucket Server sends the same X-Request-Id for the redeliveries of a webhook.
+func (hp HookProvider) DeliveryID(header http.Header) string {
+	return header.Get("X-Request-Id")
+}
+
+// VerifySignature ...
+// If a secret is set for the webhook, Bitbucket Server signs the payload with it,
+// and sends the signature in the X-Hub-Signature header, in "sha256=<hex digest>" format.
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	return hookCommon.VerifySHA256SignatureHeader(header, "X-Hub-Signature", body, secret)
+}
+
 // TransformRequest ...
 func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
 	contentType, eventKey, err := detectContentTypeAndEventKey(r.Header)
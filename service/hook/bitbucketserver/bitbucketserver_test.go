@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
 )
 
 const (
@@ -1778,3 +1779,19 @@ func Test_HookProvider_TransformRequest(t *testing.T) {
 		require.Equal(t, false, hookTransformResult.DontWaitForTriggerResponse)
 	}
 }
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	// the signature check itself is tested by hookCommon.VerifySHA256SignatureHeader
+	provider := HookProvider{}
+	body := []byte(`{"ref":"refs/heads/master"}`)
+	signature := "sha256=5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"
+
+	require.NoError(t, provider.VerifySignature(http.Header{"X-Hub-Signature": {signature}}, body, "my-secret"))
+	require.ErrorIs(t, provider.VerifySignature(http.Header{"X-Hub-Signature-256": {signature}}, body, "my-secret"), hookCommon.ErrMissingSignature)
+}
+
+func Test_HookProvider_DeliveryID(t *testing.T) {
+	provider := HookProvider{}
+	require.Equal(t, "a1b2c3", provider.DeliveryID(http.Header{"X-Request-Id": {"a1b2c3"}}))
+	require.Equal(t, "", provider.DeliveryID(http.Header{}))
+}
@@ -133,6 +133,8 @@ type CommentContentModel struct {
 // HookProvider ...
 type HookProvider struct {
 	timeProvider hookCommon.TimeProvider
+	// acceptRetries if true the retries (X-Attempt-Number >= 2) are processed too
+	acceptRetries bool
 }
 
 // NewHookProvider ...
@@ -147,6 +149,15 @@ func NewDefaultHookProvider() hookCommon.Provider {
 	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
 }
 
+// NewRetryAcceptingHookProvider returns a provider which processes the retries (X-Attempt-Number >= 2) of the webhooks too.
+// It should only be used if the deliveries are deduplicated (by DeliveryID), otherwise a retry can trigger a duplicate build.
+func NewRetryAcceptingHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider:  timeProvider,
+		acceptRetries: true,
+	}
+}
+
 func detectContentTypeAttemptNumberAndEventKey(header http.Header) (string, string, string, error) {
 	contentType := header.Get("Content-Type")
 	if contentType == "" {
@@ -326,6 +337,19 @@ func isAcceptEventType(eventKey string) bool {
 	return slices.Contains([]string{"repo:push", "pullrequest:created", "pullrequest:updated", "pullrequest:comment_created", "pullrequest:comment_updated"}, eventKey)
 }
 
+// DeliveryID ...
+// Bitbucket sends the same X-Request-UUID for every attempt (X-Attempt-Number) of a webhook.
+func (hp HookProvider) DeliveryID(header http.Header) string {
+	return header.Get("X-Request-UUID")
+}
+
+// VerifySignature ...
+// If a secret is set for the webhook, Bitbucket Cloud signs the payload with it,
+// and sends the signature in the X-Hub-Signature header, in "sha256=<hex digest>" format.
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	return hookCommon.VerifySHA256SignatureHeader(header, "X-Hub-Signature", body, secret)
+}
+
 // TransformRequest ...
 func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
 	contentType, attemptNum, eventKey, err := detectContentTypeAttemptNumberAndEventKey(r.Header)
@@ -346,7 +370,7 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 		}
 	}
 	// Check: is this a re-try hook?
-	if attemptNum != "1" {
+	if attemptNum != "1" && !hp.acceptRetries {
 		return hookCommon.TransformResultModel{
 			Error: fmt.Errorf("No retry is supported (X-Attempt-Number: %s)", attemptNum),
 		}
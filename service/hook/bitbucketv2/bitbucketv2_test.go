@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
 )
 
 const (
@@ -1574,4 +1575,37 @@ func Test_HookProvider_TransformRequest(t *testing.T) {
 		require.Nil(t, hookTransformResult.TriggerAPIParams)
 		require.Equal(t, false, hookTransformResult.DontWaitForTriggerResponse)
 	}
+
+	t.Log("X-Attempt-Number=2 - accepted if the deliveries are deduplicated")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"X-Event-Key":      {"repo:push"},
+				"Content-Type":     {"application/json"},
+				"X-Attempt-Number": {"2"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(sampleCodePushData)),
+		}
+		hookTransformResult := NewRetryAcceptingHookProvider(hookCommon.NewDefaultTimeProvider()).TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Len(t, hookTransformResult.TriggerAPIParams, 2)
+		require.Equal(t, "966d0bfe79b80f97268c2f6bb45e65e79ef09b31", hookTransformResult.TriggerAPIParams[0].BuildParams.CommitHash)
+	}
+}
+
+func Test_HookProvider_DeliveryID(t *testing.T) {
+	provider := HookProvider{}
+	require.Equal(t, "a1b2c3", provider.DeliveryID(http.Header{"X-Request-Uuid": {"a1b2c3"}, "X-Attempt-Number": {"2"}}))
+	require.Equal(t, "", provider.DeliveryID(http.Header{}))
+}
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	// the signature check itself is tested by hookCommon.VerifySHA256SignatureHeader
+	provider := HookProvider{}
+	body := []byte(`{"ref":"refs/heads/master"}`)
+	signature := "sha256=5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"
+
+	require.NoError(t, provider.VerifySignature(http.Header{"X-Hub-Signature": {signature}}, body, "my-secret"))
+	require.ErrorIs(t, provider.VerifySignature(http.Header{"X-Hub-Signature-256": {signature}}, body, "my-secret"), hookCommon.ErrMissingSignature)
 }
@@ -0,0 +1,394 @@
+package codecommit
+
+// # Infos / notes:
+//
+// ## Webhook calls
+//
+// AWS CodeCommit doesn't send webhooks directly: a repository trigger publishes the events
+// to an SNS topic, and the topic's HTTPS subscription POSTs them to bitrise-webhooks.
+//
+// Official docs: https://docs.aws.amazon.com/codecommit/latest/userguide/how-to-notify-sns.html
+// and https://docs.aws.amazon.com/sns/latest/dg/sns-message-and-json-formats.html
+//
+// SNS sends two kinds of messages:
+// * `SubscriptionConfirmation`: sent once, when the subscription is created.
+//   The subscription has to be confirmed by visiting the `SubscribeURL` of the message.
+// * `Notification`: the `Message` field includes the CodeCommit event, as a JSON string.
+//
+// Every message is signed by SNS, the signature is checked against the signing certificate
+// (downloaded from the `SigningCertURL` of the message, which has to be an SNS URL).
+// As any AWS account's topic can be subscribed to the endpoint, the topics can be restricted
+// with an allowlist of topic ARNs: the messages of the other topics are rejected, and their subscriptions are not confirmed.
+
+import (
+	"context"
+	"crypto"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// --------------------------
+// --- Webhook Data Model ---
+
+const (
+	subscriptionConfirmationType = "SubscriptionConfirmation"
+	unsubscribeConfirmationType  = "UnsubscribeConfirmation"
+	notificationType             = "Notification"
+
+	testTriggerEventName = "TriggerEventTest"
+
+	// ProviderID ...
+	ProviderID = "codecommit"
+)
+
+// MessageModel is the SNS message envelope.
+type MessageModel struct {
+	Type             string `json:"Type"`
+	MessageID        string `json:"MessageId"`
+	Token            string `json:"Token"`
+	TopicArn         string `json:"TopicArn"`
+	Subject          string `json:"Subject"`
+	Message          string `json:"Message"`
+	SubscribeURL     string `json:"SubscribeURL"`
+	Timestamp        string `json:"Timestamp"`
+	SignatureVersion string `json:"SignatureVersion"`
+	Signature        string `json:"Signature"`
+	SigningCertURL   string `json:"SigningCertURL"`
+}
+
+// ReferenceModel ...
+type ReferenceModel struct {
+	Commit  string `json:"commit"`
+	Ref     string `json:"ref"`
+	Created bool   `json:"created"`
+	Deleted bool   `json:"deleted"`
+}
+
+// RecordModel ...
+type RecordModel struct {
+	AWSRegion       string `json:"awsRegion"`
+	EventName       string `json:"eventName"`
+	EventSourceARN  string `json:"eventSourceARN"`
+	EventTime       string `json:"eventTime"`
+	UserIdentityARN string `json:"userIdentityARN"`
+	CodeCommit      struct {
+		References []ReferenceModel `json:"references"`
+	} `json:"codecommit"`
+}
+
+// EventModel is the CodeCommit event, sent in the Message of the SNS notification.
+type EventModel struct {
+	Records []RecordModel `json:"Records"`
+}
+
+// ---------------------------------------
+// --- Webhook Provider Implementation ---
+
+// HookProvider ...
+type HookProvider struct {
+	httpClient *http.Client
+	// isSNSURL checks whether the signing certificate and the subscription confirmation URLs
+	// are served by SNS, to make sure that the certificate is not provided by the sender of the request.
+	isSNSURL func(u *url.URL) bool
+	// allowedTopicArns, if set, are the ARNs of the SNS topics whose messages are accepted
+	allowedTopicArns []string
+}
+
+// NewHookProvider ...
+func NewHookProvider(httpClient *http.Client, allowedTopicArns []string) hookCommon.Provider {
+	return HookProvider{
+		httpClient:       httpClient,
+		isSNSURL:         isSNSURL,
+		allowedTopicArns: allowedTopicArns,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider(allowedTopicArns []string) hookCommon.Provider {
+	return NewHookProvider(&http.Client{Timeout: 10 * time.Second}, allowedTopicArns)
+}
+
+var snsHostPattern = regexp.MustCompile(`^sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?$`)
+
+func isSNSURL(u *url.URL) bool {
+	return u.Scheme == "https" && snsHostPattern.MatchString(u.Hostname())
+}
+
+// signingCertificates caches the downloaded signing certificates by URL:
+// SNS uses the same certificate for a long time, and the providers are created for every request.
+var signingCertificates = struct {
+	sync.Mutex
+	byURL map[string]*x509.Certificate
+}{byURL: map[string]*x509.Certificate{}}
+
+func (hp HookProvider) get(ctx context.Context, u string) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	return hp.httpClient.Do(req)
+}
+
+func (hp HookProvider) signingCertificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	signingCertificates.Lock()
+	cert, ok := signingCertificates.byURL[certURL]
+	signingCertificates.Unlock()
+	if ok {
+		return cert, nil
+	}
+
+	resp, err := hp.get(ctx, certURL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signing certificate: %s", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to download signing certificate: status code %d", resp.StatusCode)
+	}
+	certPEM, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to download signing certificate: %s", err)
+	}
+
+	block, _ := pem.Decode(certPEM)
+	if block == nil {
+		return nil, errors.New("signing certificate is not PEM encoded")
+	}
+	cert, err = x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse signing certificate: %s", err)
+	}
+
+	signingCertificates.Lock()
+	signingCertificates.byURL[certURL] = cert
+	signingCertificates.Unlock()
+
+	return cert, nil
+}
+
+// stringToSign builds the string which is signed by SNS, from the fields of the message.
+func stringToSign(message MessageModel) string {
+	var fields [][2]string
+	switch message.Type {
+	case notificationType:
+		fields = append(fields, [2]string{"Message", message.Message}, [2]string{"MessageId", message.MessageID})
+		if message.Subject != "" {
+			fields = append(fields, [2]string{"Subject", message.Subject})
+		}
+		fields = append(fields, [2]string{"Timestamp", message.Timestamp}, [2]string{"TopicArn", message.TopicArn}, [2]string{"Type", message.Type})
+	default:
+		fields = [][2]string{
+			{"Message", message.Message},
+			{"MessageId", message.MessageID},
+			{"SubscribeURL", message.SubscribeURL},
+			{"Timestamp", message.Timestamp},
+			{"Token", message.Token},
+			{"TopicArn", message.TopicArn},
+			{"Type", message.Type},
+		}
+	}
+
+	var builder strings.Builder
+	for _, field := range fields {
+		builder.WriteString(field[0] + "\n" + field[1] + "\n")
+	}
+	return builder.String()
+}
+
+func (hp HookProvider) verifyMessageSignature(ctx context.Context, message MessageModel) error {
+	certURL, err := url.Parse(message.SigningCertURL)
+	if err != nil || !hp.isSNSURL(certURL) {
+		return fmt.Errorf("%w: signing certificate URL is not an SNS URL: %s", hookCommon.ErrInvalidSignature, message.SigningCertURL)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(message.Signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not base64 encoded", hookCommon.ErrInvalidSignature)
+	}
+
+	var hashFunc crypto.Hash
+	var digest []byte
+	switch message.SignatureVersion {
+	case "1":
+		hashFunc = crypto.SHA1
+		sum := sha1.Sum([]byte(stringToSign(message)))
+		digest = sum[:]
+	case "2":
+		hashFunc = crypto.SHA256
+		sum := sha256.Sum256([]byte(stringToSign(message)))
+		digest = sum[:]
+	default:
+		return fmt.Errorf("%w: unsupported signature version: %s", hookCommon.ErrInvalidSignature, message.SignatureVersion)
+	}
+
+	cert, err := hp.signingCertificate(ctx, message.SigningCertURL)
+	if err != nil {
+		return err
+	}
+	publicKey, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return errors.New("signing certificate doesn't include an RSA public key")
+	}
+	if err := rsa.VerifyPKCS1v15(publicKey, hashFunc, digest, signature); err != nil {
+		return hookCommon.ErrInvalidSignature
+	}
+	return nil
+}
+
+func (hp HookProvider) confirmSubscription(ctx context.Context, message MessageModel) hookCommon.TransformResultModel {
+	subscribeURL, err := url.Parse(message.SubscribeURL)
+	if err != nil || !hp.isSNSURL(subscribeURL) {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("subscribe URL is not an SNS URL: %s", message.SubscribeURL),
+		}
+	}
+
+	resp, err := hp.get(ctx, message.SubscribeURL)
+	if err != nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("failed to confirm subscription: %s", err),
+		}
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+	if resp.StatusCode != http.StatusOK {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("failed to confirm subscription: status code %d", resp.StatusCode),
+		}
+	}
+
+	return hookCommon.TransformResultModel{
+		Error:      fmt.Errorf("subscription confirmed for topic: %s", message.TopicArn),
+		ShouldSkip: true,
+	}
+}
+
+// userName returns the name of the IAM user or role from the user identity ARN,
+// e.g. `arn:aws:iam::123456789012:user/jane` -> `jane`.
+func userName(userIdentityARN string) string {
+	if i := strings.LastIndex(userIdentityARN, "/"); i != -1 {
+		return userIdentityARN[i+1:]
+	}
+	return userIdentityARN
+}
+
+func transformEvent(event EventModel) hookCommon.TransformResultModel {
+	var triggerAPIParams []bitriseapi.TriggerAPIParamsModel
+	var errs []string
+	for _, record := range event.Records {
+		if record.EventName == testTriggerEventName {
+			errs = append(errs, "test trigger event received")
+			continue
+		}
+
+		for _, reference := range record.CodeCommit.References {
+			if reference.Deleted {
+				errs = append(errs, fmt.Sprintf("ref (%s) was deleted", reference.Ref))
+				continue
+			}
+
+			buildParams := bitriseapi.BuildParamsModel{
+				CommitHash: reference.Commit,
+			}
+			if strings.HasPrefix(reference.Ref, "refs/heads/") {
+				buildParams.Branch = strings.TrimPrefix(reference.Ref, "refs/heads/")
+			} else if strings.HasPrefix(reference.Ref, "refs/tags/") {
+				buildParams.Tag = strings.TrimPrefix(reference.Ref, "refs/tags/")
+			} else {
+				errs = append(errs, fmt.Sprintf("ref (%s) is not a head nor a tag ref", reference.Ref))
+				continue
+			}
+
+			triggerAPIParams = append(triggerAPIParams, bitriseapi.TriggerAPIParamsModel{
+				BuildParams: buildParams,
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, userName(record.UserIdentityARN)),
+			})
+		}
+	}
+
+	if len(triggerAPIParams) == 0 {
+		if len(errs) == 0 {
+			errs = append(errs, "no references in the event")
+		}
+		return hookCommon.TransformResultModel{
+			Error:      errors.New(strings.Join(errs, ", ")),
+			ShouldSkip: true,
+		}
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: triggerAPIParams,
+	}
+}
+
+// TransformRequest ...
+func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
+	if r.Body == nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Failed to read content of request body: no or empty request body"),
+		}
+	}
+
+	// SNS sends the messages with a `text/plain` Content-Type, so it isn't checked
+	var message MessageModel
+	if err := json.NewDecoder(r.Body).Decode(&message); err != nil {
+		return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse request body: %s", err)}
+	}
+
+	if err := hp.verifyMessageSignature(r.Context(), message); err != nil {
+		return hookCommon.TransformResultModel{Error: fmt.Errorf("SNS message signature verification failed: %w", err)}
+	}
+
+	// checked after the signature, so that the topic ARN can be trusted
+	if len(hp.allowedTopicArns) > 0 && !slices.Contains(hp.allowedTopicArns, message.TopicArn) {
+		return hookCommon.TransformResultModel{Error: fmt.Errorf("SNS topic is not allowed: %s", message.TopicArn)}
+	}
+
+	switch message.Type {
+	case subscriptionConfirmationType:
+		return hp.confirmSubscription(r.Context(), message)
+	case unsubscribeConfirmationType:
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("unsubscribed from topic: %s", message.TopicArn),
+			ShouldSkip: true,
+		}
+	case notificationType:
+		var event EventModel
+		if err := json.Unmarshal([]byte(message.Message), &event); err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse notification message: %s", err)}
+		}
+
+		return transformEvent(event)
+	}
+
+	return hookCommon.TransformResultModel{
+		Error: fmt.Errorf("Unsupported SNS message type: %s", message.Type),
+	}
+}
+
+// DeliveryID ...
+// SNS sends the same message ID for the retries of a message.
+func (hp HookProvider) DeliveryID(header http.Header) string {
+	return header.Get("X-Amz-Sns-Message-Id")
+}
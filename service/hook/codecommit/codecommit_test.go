@@ -0,0 +1,354 @@
+package codecommit
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+	"github.com/stretchr/testify/require"
+)
+
+const sampleEventMessage = `{
+  "Records": [
+    {
+      "awsRegion": "us-east-1",
+      "codecommit": {
+        "references": [
+          {"commit": "5c4ef1049f1d27deadbeeff313e0730018be182b", "ref": "refs/heads/main"},
+          {"commit": "5c4ef1049f1d27deadbeeff313e0730018be182b", "ref": "refs/tags/v1.0.0", "created": true}
+        ]
+      },
+      "eventName": "ReferenceChanges",
+      "eventSource": "aws:codecommit",
+      "eventSourceARN": "arn:aws:codecommit:us-east-1:123456789012:MyDemoRepo",
+      "eventTime": "2024-03-07T10:15:30.000+0000",
+      "userIdentityARN": "arn:aws:iam::123456789012:user/jane"
+    }
+  ]
+}`
+
+// snsStub serves the signing certificate and the subscription confirmation URL, like SNS does.
+type snsStub struct {
+	server        *httptest.Server
+	key           *rsa.PrivateKey
+	confirmations atomic.Int32
+}
+
+func newSNSStub(t *testing.T) *snsStub {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "sns.us-east-1.amazonaws.com"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER})
+
+	stub := &snsStub{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/cert.pem", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write(certPEM)
+	})
+	mux.HandleFunc("/confirm", func(w http.ResponseWriter, r *http.Request) {
+		require.Equal(t, "subscription-token", r.URL.Query().Get("Token"))
+		stub.confirmations.Add(1)
+	})
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+
+	return stub
+}
+
+func (stub *snsStub) provider() HookProvider {
+	return HookProvider{
+		httpClient: stub.server.Client(),
+		isSNSURL: func(u *url.URL) bool {
+			return "http://"+u.Host == stub.server.URL
+		},
+	}
+}
+
+func (stub *snsStub) sign(t *testing.T, message MessageModel) MessageModel {
+	message.SigningCertURL = stub.server.URL + "/cert.pem"
+
+	var signature []byte
+	var err error
+	if message.SignatureVersion == "1" {
+		digest := sha1.Sum([]byte(stringToSign(message)))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, stub.key, crypto.SHA1, digest[:])
+	} else {
+		message.SignatureVersion = "2"
+		digest := sha256.Sum256([]byte(stringToSign(message)))
+		signature, err = rsa.SignPKCS1v15(rand.Reader, stub.key, crypto.SHA256, digest[:])
+	}
+	require.NoError(t, err)
+	message.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	return message
+}
+
+func newRequest(t *testing.T, message MessageModel) *http.Request {
+	body, err := json.Marshal(message)
+	require.NoError(t, err)
+	return &http.Request{
+		Header: http.Header{
+			"Content-Type":            {"text/plain; charset=UTF-8"},
+			"X-Amz-Sns-Message-Type":  {message.Type},
+			"X-Amz-Sns-Message-Id":    {message.MessageID},
+			"X-Amz-Sns-Topic-Arn":     {message.TopicArn},
+			"X-Amz-Sns-Subscription":  {"arn:aws:sns:us-east-1:123456789012:MyTopic:subscription"},
+			"X-Amz-Sns-Rawdelivery":   {"false"},
+			"X-Amz-Sns-Signature-Ver": {message.SignatureVersion},
+		},
+		Body: io.NopCloser(bytes.NewReader(body)),
+	}
+}
+
+func Test_HookProvider_TransformRequest(t *testing.T) {
+	stub := newSNSStub(t)
+	provider := stub.provider()
+
+	t.Log("Subscription confirmation - confirms the subscription")
+	{
+		message := stub.sign(t, MessageModel{
+			Type:         "SubscriptionConfirmation",
+			MessageID:    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+			Token:        "subscription-token",
+			TopicArn:     "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			Message:      "You have chosen to subscribe to the topic arn:aws:sns:us-east-1:123456789012:MyTopic.",
+			SubscribeURL: stub.server.URL + "/confirm?Action=ConfirmSubscription&Token=subscription-token",
+			Timestamp:    "2024-03-07T10:15:30.000Z",
+		})
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "subscription confirmed for topic: arn:aws:sns:us-east-1:123456789012:MyTopic")
+		require.Equal(t, int32(1), stub.confirmations.Load())
+	}
+
+	t.Log("Subscription confirmation - not an SNS subscribe URL")
+	{
+		message := stub.sign(t, MessageModel{
+			Type:         "SubscriptionConfirmation",
+			MessageID:    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+			Token:        "subscription-token",
+			TopicArn:     "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			SubscribeURL: "https://attacker.example.com/confirm",
+			Timestamp:    "2024-03-07T10:15:30.000Z",
+		})
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "subscribe URL is not an SNS URL: https://attacker.example.com/confirm")
+		require.Equal(t, int32(1), stub.confirmations.Load())
+	}
+
+	t.Log("Notification - signature version 2")
+	{
+		message := stub.sign(t, MessageModel{
+			Type:      "Notification",
+			MessageID: "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+			TopicArn:  "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			Subject:   "UPDATE: AWS CodeCommit us-east-1 push: MyDemoRepo",
+			Message:   sampleEventMessage,
+			Timestamp: "2024-03-07T10:15:30.000Z",
+		})
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitHash: "5c4ef1049f1d27deadbeeff313e0730018be182b",
+					Branch:     "main",
+				},
+				TriggeredBy: "webhook-codecommit/jane",
+			},
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitHash: "5c4ef1049f1d27deadbeeff313e0730018be182b",
+					Tag:        "v1.0.0",
+				},
+				TriggeredBy: "webhook-codecommit/jane",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Notification - signature version 1, without subject")
+	{
+		message := stub.sign(t, MessageModel{
+			Type:             "Notification",
+			MessageID:        "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+			TopicArn:         "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			Message:          sampleEventMessage,
+			Timestamp:        "2024-03-07T10:15:30.000Z",
+			SignatureVersion: "1",
+		})
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.NoError(t, hookTransformResult.Error)
+		require.Len(t, hookTransformResult.TriggerAPIParams, 2)
+	}
+
+	t.Log("Notification - tampered message")
+	{
+		message := stub.sign(t, MessageModel{
+			Type:      "Notification",
+			MessageID: "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+			TopicArn:  "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			Message:   sampleEventMessage,
+			Timestamp: "2024-03-07T10:15:30.000Z",
+		})
+		message.Message = `{"Records": []}`
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.ErrorIs(t, hookTransformResult.Error, hookCommon.ErrInvalidSignature)
+	}
+
+	t.Log("Notification - signing certificate is not served by SNS")
+	{
+		message := stub.sign(t, MessageModel{
+			Type:      "Notification",
+			MessageID: "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+			TopicArn:  "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			Message:   sampleEventMessage,
+			Timestamp: "2024-03-07T10:15:30.000Z",
+		})
+		message.SigningCertURL = "https://attacker.example.com/cert.pem"
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.ErrorIs(t, hookTransformResult.Error, hookCommon.ErrInvalidSignature)
+	}
+
+	t.Log("Topic allowlist - allowed topic")
+	{
+		provider := stub.provider()
+		provider.allowedTopicArns = []string{"arn:aws:sns:us-east-1:123456789012:OtherTopic", "arn:aws:sns:us-east-1:123456789012:MyTopic"}
+		message := stub.sign(t, MessageModel{
+			Type:      "Notification",
+			MessageID: "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+			TopicArn:  "arn:aws:sns:us-east-1:123456789012:MyTopic",
+			Message:   sampleEventMessage,
+			Timestamp: "2024-03-07T10:15:30.000Z",
+		})
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.NoError(t, hookTransformResult.Error)
+		require.Len(t, hookTransformResult.TriggerAPIParams, 2)
+	}
+
+	t.Log("Topic allowlist - the subscription of an other topic is not confirmed")
+	{
+		provider := stub.provider()
+		provider.allowedTopicArns = []string{"arn:aws:sns:us-east-1:123456789012:MyTopic"}
+		message := stub.sign(t, MessageModel{
+			Type:         "SubscriptionConfirmation",
+			MessageID:    "165545c9-2a5c-472c-8df2-7ff2be2b3b1b",
+			Token:        "subscription-token",
+			TopicArn:     "arn:aws:sns:us-east-1:210987654321:AttackerTopic",
+			SubscribeURL: stub.server.URL + "/confirm?Action=ConfirmSubscription&Token=subscription-token",
+			Timestamp:    "2024-03-07T10:15:30.000Z",
+		})
+
+		confirmations := stub.confirmations.Load()
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "SNS topic is not allowed: arn:aws:sns:us-east-1:210987654321:AttackerTopic")
+		require.Equal(t, confirmations, stub.confirmations.Load())
+	}
+
+	t.Log("Topic allowlist - the notification of an other topic is rejected")
+	{
+		provider := stub.provider()
+		provider.allowedTopicArns = []string{"arn:aws:sns:us-east-1:123456789012:MyTopic"}
+		message := stub.sign(t, MessageModel{
+			Type:      "Notification",
+			MessageID: "da41e39f-ea4d-435a-b922-c6aae3915ebe",
+			TopicArn:  "arn:aws:sns:us-east-1:210987654321:AttackerTopic",
+			Message:   sampleEventMessage,
+			Timestamp: "2024-03-07T10:15:30.000Z",
+		})
+
+		hookTransformResult := provider.TransformRequest(newRequest(t, message))
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "SNS topic is not allowed: arn:aws:sns:us-east-1:210987654321:AttackerTopic")
+		require.Empty(t, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Invalid body")
+	{
+		request := http.Request{
+			Header: http.Header{"Content-Type": {"text/plain; charset=UTF-8"}},
+			Body:   io.NopCloser(bytes.NewReader([]byte(`not json`))),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.Error(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+	}
+}
+
+func Test_transformEvent(t *testing.T) {
+	t.Log("Deleted branch - should be skipped")
+	{
+		var event EventModel
+		event.Records = []RecordModel{{EventName: "ReferenceChanges"}}
+		event.Records[0].CodeCommit.References = []ReferenceModel{{Commit: "5c4ef1049f1d27deadbeeff313e0730018be182b", Ref: "refs/heads/feature", Deleted: true}}
+
+		hookTransformResult := transformEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "ref (refs/heads/feature) was deleted")
+	}
+
+	t.Log("Test trigger - should be skipped")
+	{
+		event := EventModel{Records: []RecordModel{{EventName: "TriggerEventTest"}}}
+
+		hookTransformResult := transformEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "test trigger event received")
+	}
+}
+
+func Test_isSNSURL(t *testing.T) {
+	for rawURL, want := range map[string]bool{
+		"https://sns.us-east-1.amazonaws.com/SimpleNotificationService-abcd.pem":         true,
+		"https://sns.cn-north-1.amazonaws.com.cn/SimpleNotificationService-abcd.pem":     true,
+		"http://sns.us-east-1.amazonaws.com/SimpleNotificationService-abcd.pem":          false,
+		"https://sns.us-east-1.amazonaws.com.attacker.com/cert.pem":                      false,
+		"https://attacker.com/sns.us-east-1.amazonaws.com/SimpleNotificationService.pem": false,
+	} {
+		u, err := url.Parse(rawURL)
+		require.NoError(t, err)
+		require.Equal(t, want, isSNSURL(u), rawURL)
+	}
+}
+
+func Test_HookProvider_DeliveryID(t *testing.T) {
+	provider := HookProvider{}
+	require.Equal(t, "da41e39f-ea4d-435a-b922-c6aae3915ebe", provider.DeliveryID(http.Header{"X-Amz-Sns-Message-Id": {"da41e39f-ea4d-435a-b922-c6aae3915ebe"}}))
+	require.Equal(t, "", provider.DeliveryID(http.Header{}))
+}
@@ -0,0 +1,148 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+)
+
+// DefaultChatOpsPrefix ...
+const DefaultChatOpsPrefix = "/bitrise"
+
+// CommandType ...
+type CommandType string
+
+const (
+	// CommandRun triggers the build with the given workflow and environment variables,
+	// e.g. `/bitrise run deploy VERSION=1.2.0`
+	CommandRun CommandType = "run"
+	// CommandRetry triggers the build as-it-is
+	CommandRetry CommandType = "retry"
+	// CommandSkip doesn't trigger the build
+	CommandSkip CommandType = "skip"
+)
+
+// Command is a ChatOps command, parsed from a pull request comment.
+type Command struct {
+	Type         CommandType
+	WorkflowID   string
+	Environments []bitriseapi.EnvironmentItem
+}
+
+// ParseCommand parses the first line of the comment which starts with the prefix.
+// It returns nil (and no error) if the comment doesn't include a command.
+func ParseCommand(prefix, comment string) (*Command, error) {
+	for _, line := range strings.Split(comment, "\n") {
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != prefix {
+			continue
+		}
+
+		if len(fields) == 1 {
+			return nil, fmt.Errorf("missing command")
+		}
+		command := &Command{Type: CommandType(fields[1])}
+		args := fields[2:]
+
+		switch command.Type {
+		case CommandRun:
+			if len(args) == 0 {
+				return nil, fmt.Errorf("missing workflow of the %s command", CommandRun)
+			}
+			command.WorkflowID = args[0]
+			for _, arg := range args[1:] {
+				key, value, ok := strings.Cut(arg, "=")
+				if !ok || key == "" {
+					return nil, fmt.Errorf("invalid environment variable (%s), should be KEY=VALUE", arg)
+				}
+				command.Environments = append(command.Environments, bitriseapi.EnvironmentItem{Name: key, Value: value, IsExpand: false})
+			}
+		case CommandRetry, CommandSkip:
+			if len(args) > 0 {
+				return nil, fmt.Errorf("the %s command doesn't have arguments", command.Type)
+			}
+		default:
+			return nil, fmt.Errorf("unknown command: %s", command.Type)
+		}
+		return command, nil
+	}
+	return nil, nil
+}
+
+// ChatOpsConfig ...
+type ChatOpsConfig struct {
+	// Prefix of the commands, DefaultChatOpsPrefix if empty
+	Prefix string `json:"prefix"`
+	// AllowedAuthorAssociations, if set, restricts who can run commands (e.g. OWNER, MEMBER, COLLABORATOR).
+	//  The commands of the comments without an author association (sent only by GitHub) are rejected too.
+	AllowedAuthorAssociations []string `json:"allowed_author_associations"`
+}
+
+// ReadChatOpsFile reads the ChatOps configs from a JSON file, which maps app slugs to ChatOps configs, e.g.:
+//
+//	{"my-app-slug": {"prefix": "/bitrise", "allowed_author_associations": ["OWNER", "MEMBER"]}}
+func ReadChatOpsFile(pth string) (map[string]*ChatOpsConfig, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read ChatOps file (%s)", pth)
+	}
+
+	var chatOpsByApp map[string]*ChatOpsConfig
+	if err := json.Unmarshal(content, &chatOpsByApp); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse ChatOps file (%s)", pth)
+	}
+	for appSlug, chatOps := range chatOpsByApp {
+		if chatOps == nil {
+			chatOpsByApp[appSlug] = &ChatOpsConfig{}
+			continue
+		}
+		if strings.ContainsAny(chatOps.Prefix, " \t\n") {
+			return nil, fmt.Errorf("invalid ChatOps config for app (%s): the prefix (%s) should be a single word", appSlug, chatOps.Prefix)
+		}
+	}
+
+	return chatOpsByApp, nil
+}
+
+// Apply applies the command of the pull request comment to the build parameters.
+// It returns the reason why the build should be skipped, or an empty string if the build should be triggered.
+func (config ChatOpsConfig) Apply(buildParams *bitriseapi.BuildParamsModel) string {
+	prefix := config.Prefix
+	if prefix == "" {
+		prefix = DefaultChatOpsPrefix
+	}
+
+	command, err := ParseCommand(prefix, buildParams.PullRequestComment)
+	if err != nil {
+		return fmt.Sprintf("Build skipped because of an invalid %s command: %s", prefix, err)
+	}
+	if command == nil {
+		return fmt.Sprintf("Build skipped because the PR comment is not a %s command.", prefix)
+	}
+
+	association := buildParams.PullRequestCommentAuthorAssociation
+	if len(config.AllowedAuthorAssociations) > 0 {
+		if association == "" {
+			return fmt.Sprintf("Build skipped because the author association of the PR comment is unknown, only the allowed authors can run %s commands.", prefix)
+		}
+		if !slices.ContainsFunc(config.AllowedAuthorAssociations, func(allowed string) bool { return strings.EqualFold(allowed, association) }) {
+			return fmt.Sprintf("Build skipped because the author of the PR comment (%s) is not allowed to run %s commands.", association, prefix)
+		}
+	}
+
+	switch command.Type {
+	case CommandSkip:
+		return fmt.Sprintf("Build skipped by the %s %s command.", prefix, CommandSkip)
+	case CommandRun:
+		buildParams.WorkflowID = command.WorkflowID
+		// don't append to the env items in place, they might be shared by multiple builds
+		buildParams.Environments = append(slices.Clip(buildParams.Environments), command.Environments...)
+	}
+	return ""
+}
@@ -0,0 +1,145 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+)
+
+func TestParseCommand(t *testing.T) {
+	t.Log("Commands")
+	{
+		for comment, want := range map[string]*Command{
+			"/bitrise run deploy": {Type: CommandRun, WorkflowID: "deploy"},
+			"Looks good!\n/bitrise run deploy VERSION=1.2.0 NOTES=a=b": {
+				Type:       CommandRun,
+				WorkflowID: "deploy",
+				Environments: []bitriseapi.EnvironmentItem{
+					{Name: "VERSION", Value: "1.2.0", IsExpand: false},
+					{Name: "NOTES", Value: "a=b", IsExpand: false},
+				},
+			},
+			"  /bitrise retry  ": {Type: CommandRetry},
+			"/bitrise skip":      {Type: CommandSkip},
+		} {
+			t.Log(" * Comment:", comment)
+			command, err := ParseCommand(DefaultChatOpsPrefix, comment)
+			require.NoError(t, err)
+			require.Equal(t, want, command)
+		}
+	}
+
+	t.Log("Not a command")
+	{
+		for _, comment := range []string{"", "LGTM", "please /bitrise retry", "/bitrisex retry", "/bitrise-bot retry"} {
+			t.Log(" * Comment:", comment)
+			command, err := ParseCommand(DefaultChatOpsPrefix, comment)
+			require.NoError(t, err)
+			require.Nil(t, command)
+		}
+	}
+
+	t.Log("Invalid commands")
+	{
+		for comment, wantErr := range map[string]string{
+			"/bitrise":                  "missing command",
+			"/bitrise deploy":           "unknown command: deploy",
+			"/bitrise run":              "missing workflow of the run command",
+			"/bitrise run deploy DEBUG": "invalid environment variable (DEBUG), should be KEY=VALUE",
+			"/bitrise run deploy =1":    "invalid environment variable (=1), should be KEY=VALUE",
+			"/bitrise retry now":        "the retry command doesn't have arguments",
+		} {
+			t.Log(" * Comment:", comment)
+			_, err := ParseCommand(DefaultChatOpsPrefix, comment)
+			require.EqualError(t, err, wantErr)
+		}
+	}
+
+	t.Log("Custom prefix")
+	{
+		command, err := ParseCommand("!ci", "!ci skip")
+		require.NoError(t, err)
+		require.Equal(t, &Command{Type: CommandSkip}, command)
+	}
+}
+
+func TestChatOpsConfig_Apply(t *testing.T) {
+	config := ChatOpsConfig{AllowedAuthorAssociations: []string{"OWNER", "MEMBER"}}
+
+	t.Log("Run command")
+	{
+		buildParams := bitriseapi.BuildParamsModel{
+			WorkflowID:         "primary",
+			Environments:       []bitriseapi.EnvironmentItem{{Name: "PR", Value: "true", IsExpand: false}},
+			PullRequestComment: "/bitrise run deploy VERSION=1.2.0",
+			// the association is case insensitive
+			PullRequestCommentAuthorAssociation: "member",
+		}
+		require.Equal(t, "", config.Apply(&buildParams))
+		require.Equal(t, "deploy", buildParams.WorkflowID)
+		require.Equal(t, []bitriseapi.EnvironmentItem{
+			{Name: "PR", Value: "true", IsExpand: false},
+			{Name: "VERSION", Value: "1.2.0", IsExpand: false},
+		}, buildParams.Environments)
+	}
+
+	t.Log("Retry command - the provider doesn't send the author association")
+	{
+		buildParams := bitriseapi.BuildParamsModel{WorkflowID: "primary", PullRequestComment: "/bitrise retry"}
+		require.Equal(t, "", ChatOpsConfig{}.Apply(&buildParams))
+		require.Equal(t, "primary", buildParams.WorkflowID)
+
+		// with allowed associations the unknown author is denied
+		require.Equal(t, "Build skipped because the author association of the PR comment is unknown, only the allowed authors can run /bitrise commands.", config.Apply(&buildParams))
+	}
+
+	t.Log("Skipped builds")
+	{
+		for comment, want := range map[string]string{
+			"LGTM":                     "Build skipped because the PR comment is not a /bitrise command.",
+			"/bitrise skip":            "Build skipped by the /bitrise skip command.",
+			"/bitrise deploy":          "Build skipped because of an invalid /bitrise command: unknown command: deploy",
+			"/bitrise run deploy A=1 ": "",
+		} {
+			buildParams := bitriseapi.BuildParamsModel{PullRequestComment: comment, PullRequestCommentAuthorAssociation: "OWNER"}
+			require.Equal(t, want, config.Apply(&buildParams), comment)
+		}
+
+		buildParams := bitriseapi.BuildParamsModel{PullRequestComment: "/bitrise retry", PullRequestCommentAuthorAssociation: "CONTRIBUTOR"}
+		require.Equal(t, "Build skipped because the author of the PR comment (CONTRIBUTOR) is not allowed to run /bitrise commands.", config.Apply(&buildParams))
+	}
+}
+
+func TestReadChatOpsFile(t *testing.T) {
+	t.Log("Valid ChatOps file")
+	{
+		pth := filepath.Join(t.TempDir(), "chatops.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"app-slug": {"prefix": "!ci", "allowed_author_associations": ["OWNER"]}, "other-app-slug": null}`), 0600))
+
+		chatOpsByApp, err := ReadChatOpsFile(pth)
+		require.NoError(t, err)
+		require.Equal(t, map[string]*ChatOpsConfig{
+			"app-slug":       {Prefix: "!ci", AllowedAuthorAssociations: []string{"OWNER"}},
+			"other-app-slug": {},
+		}, chatOpsByApp)
+	}
+
+	t.Log("Invalid prefix")
+	{
+		pth := filepath.Join(t.TempDir(), "chatops.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"app-slug": {"prefix": "/bitrise run"}}`), 0600))
+
+		_, err := ReadChatOpsFile(pth)
+		require.EqualError(t, err, "invalid ChatOps config for app (app-slug): the prefix (/bitrise run) should be a single word")
+	}
+
+	t.Log("Missing file")
+	{
+		_, err := ReadChatOpsFile(filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	}
+}
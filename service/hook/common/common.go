@@ -73,6 +73,15 @@ type SkipAPIResponseModel struct {
 	Branch        string `json:"branch"`
 }
 
+// QueuedAPIResponseModel ...
+type QueuedAPIResponseModel struct {
+	Message       string `json:"message"`
+	QueueID       string `json:"queue_id"`
+	CommitHash    string `json:"commit_hash"`
+	CommitMessage string `json:"commit_message"`
+	Branch        string `json:"branch"`
+}
+
 // TransformResponseInputModel ...
 type TransformResponseInputModel struct {
 	// Errors include the errors if the build could not trigger
@@ -90,6 +99,13 @@ type TransformResponseInputModel struct {
 	// SkippedTriggerResponses include responses for the trigger calls
 	//  that were skipped
 	SkippedTriggerResponses []SkipAPIResponseModel
+	// QueuedTriggerResponses include the trigger calls which failed with
+	//  a transient error, and were queued to be retried later
+	QueuedTriggerResponses []QueuedAPIResponseModel
+
+	// AppResponses include the results per app slug, if the webhook triggered
+	//  builds on multiple apps (fan-out). The lists above include the results of every app.
+	AppResponses map[string]TransformResponseInputModel
 }
 
 // ResponseTransformer ...
@@ -19,11 +19,13 @@ type SuccessRespModel struct {
 
 // DefaultTransformResponseModel ...
 type DefaultTransformResponseModel struct {
-	Errors                       []string                             `json:"errors,omitempty"`
-	DidNotWaitForTriggerResponse bool                                 `json:"did_not_wait_for_trigger_response,omitempty"`
-	SuccessTriggerResponses      []bitriseapi.TriggerAPIResponseModel `json:"success_responses"`
-	FailedTriggerResponses       []bitriseapi.TriggerAPIResponseModel `json:"failed_responses,omitempty"`
-	SkippedTriggerResponses      []SkipAPIResponseModel               `json:"skipped_responses,omitempty"`
+	Errors                       []string                                 `json:"errors,omitempty"`
+	DidNotWaitForTriggerResponse bool                                     `json:"did_not_wait_for_trigger_response,omitempty"`
+	SuccessTriggerResponses      []bitriseapi.TriggerAPIResponseModel     `json:"success_responses"`
+	FailedTriggerResponses       []bitriseapi.TriggerAPIResponseModel     `json:"failed_responses,omitempty"`
+	SkippedTriggerResponses      []SkipAPIResponseModel                   `json:"skipped_responses,omitempty"`
+	QueuedTriggerResponses       []QueuedAPIResponseModel                 `json:"queued_responses,omitempty"`
+	AppResponses                 map[string]DefaultTransformResponseModel `json:"app_responses,omitempty"`
 }
 
 // TransformResponse ...
@@ -38,6 +40,10 @@ func (hp DefaultResponseProvider) TransformResponse(input TransformResponseInput
 		httpStatusCode = 200
 	}
 
+	if len(input.QueuedTriggerResponses) > 0 {
+		httpStatusCode = 202
+	}
+
 	if len(input.Errors) > 0 {
 		httpStatusCode = 500
 	}
@@ -50,6 +56,14 @@ func (hp DefaultResponseProvider) TransformResponse(input TransformResponseInput
 		}
 	}
 
+	var appResponses map[string]DefaultTransformResponseModel
+	if len(input.AppResponses) > 0 {
+		appResponses = map[string]DefaultTransformResponseModel{}
+		for appSlug, appInput := range input.AppResponses {
+			appResponses[appSlug] = hp.TransformResponse(appInput).Data.(DefaultTransformResponseModel)
+		}
+	}
+
 	return TransformResponseModel{
 		Data: DefaultTransformResponseModel{
 			Errors:                       input.Errors,
@@ -57,6 +71,8 @@ func (hp DefaultResponseProvider) TransformResponse(input TransformResponseInput
 			SuccessTriggerResponses:      input.SuccessTriggerResponses,
 			FailedTriggerResponses:       input.FailedTriggerResponses,
 			SkippedTriggerResponses:      input.SkippedTriggerResponses,
+			QueuedTriggerResponses:       input.QueuedTriggerResponses,
+			AppResponses:                 appResponses,
 		},
 		HTTPStatusCode: httpStatusCode,
 	}
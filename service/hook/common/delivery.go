@@ -0,0 +1,11 @@
+package common
+
+import "net/http"
+
+// DeliveryIDProvider ...
+type DeliveryIDProvider interface {
+	// DeliveryID returns the unique ID the provider assigned to the webhook delivery,
+	//  which is the same for the redeliveries of the webhook.
+	// Returns an empty string if the request does not include a delivery ID.
+	DeliveryID(header http.Header) string
+}
@@ -0,0 +1,71 @@
+package common
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+
+	"github.com/pkg/errors"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+)
+
+// LabelWorkflows maps pull request labels to workflows, e.g. `run-ui-tests` -> `ui_test_workflow`.
+type LabelWorkflows map[string]string
+
+// ReadLabelWorkflowsFile reads the label workflows from a JSON file, which maps app slugs to label workflows, e.g.:
+//
+//	{"my-app-slug": {"run-ui-tests": "ui_test_workflow", "release-candidate": "rc_pipeline"}}
+func ReadLabelWorkflowsFile(pth string) (map[string]LabelWorkflows, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read label workflows file (%s)", pth)
+	}
+
+	var labelWorkflowsByApp map[string]LabelWorkflows
+	if err := json.Unmarshal(content, &labelWorkflowsByApp); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse label workflows file (%s)", pth)
+	}
+	for appSlug, labelWorkflows := range labelWorkflowsByApp {
+		for label, workflowID := range labelWorkflows {
+			if label == "" || workflowID == "" {
+				return nil, fmt.Errorf("invalid label workflows for app (%s): empty label or workflow (%q: %q)", appSlug, label, workflowID)
+			}
+		}
+	}
+
+	return labelWorkflowsByApp, nil
+}
+
+// Apply replaces the builds of the newly added pull request labels with one build per mapped workflow.
+// The builds without a newly added mapped label (including the pull request comments,
+// which are built for the comment, not for the labels) are kept as-they-are.
+func (labelWorkflows LabelWorkflows) Apply(triggerAPIParams []bitriseapi.TriggerAPIParamsModel) []bitriseapi.TriggerAPIParamsModel {
+	if len(labelWorkflows) == 0 {
+		return triggerAPIParams
+	}
+
+	var result []bitriseapi.TriggerAPIParamsModel
+	for _, aTriggerAPIParams := range triggerAPIParams {
+		var workflowIDs []string
+		if aTriggerAPIParams.BuildParams.PullRequestComment == "" {
+			for _, label := range aTriggerAPIParams.BuildParams.PullRequestLabelsAdded {
+				if workflowID, ok := labelWorkflows[label]; ok && !slices.Contains(workflowIDs, workflowID) {
+					workflowIDs = append(workflowIDs, workflowID)
+				}
+			}
+		}
+
+		if len(workflowIDs) == 0 {
+			result = append(result, aTriggerAPIParams)
+			continue
+		}
+		for _, workflowID := range workflowIDs {
+			labelTriggerAPIParams := aTriggerAPIParams
+			labelTriggerAPIParams.BuildParams.WorkflowID = workflowID
+			result = append(result, labelTriggerAPIParams)
+		}
+	}
+	return result
+}
@@ -0,0 +1,87 @@
+package common
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+)
+
+func TestLabelWorkflows_Apply(t *testing.T) {
+	labelWorkflows := LabelWorkflows{
+		"run-ui-tests":      "ui_test_workflow",
+		"release-candidate": "rc_pipeline",
+		"rc":                "rc_pipeline",
+	}
+	pullRequestID := 4
+
+	t.Log("One build per mapped workflow of the added labels")
+	{
+		labeled := bitriseapi.TriggerAPIParamsModel{
+			BuildParams: bitriseapi.BuildParamsModel{
+				Branch:                 "feature",
+				PullRequestID:          &pullRequestID,
+				PullRequestLabelsAdded: []string{"run-ui-tests", "wip", "release-candidate", "rc"},
+				PullRequestLabels:      []string{"run-ui-tests", "wip", "release-candidate", "rc"},
+			},
+			TriggeredBy: "webhook-github/test_user",
+		}
+
+		result := labelWorkflows.Apply([]bitriseapi.TriggerAPIParamsModel{labeled})
+		require.Len(t, result, 2)
+		require.Equal(t, "ui_test_workflow", result[0].BuildParams.WorkflowID)
+		require.Equal(t, "rc_pipeline", result[1].BuildParams.WorkflowID)
+		require.Equal(t, "feature", result[1].BuildParams.Branch)
+		require.Equal(t, "webhook-github/test_user", result[1].TriggeredBy)
+		require.Equal(t, "", labeled.BuildParams.WorkflowID)
+	}
+
+	t.Log("Builds without added mapped labels are kept as-they-are")
+	{
+		for _, buildParams := range []bitriseapi.BuildParamsModel{
+			{Branch: "main"},
+			{PullRequestID: &pullRequestID, PullRequestLabels: []string{"run-ui-tests"}},
+			{PullRequestID: &pullRequestID, PullRequestLabelsAdded: []string{"wip"}},
+			{PullRequestID: &pullRequestID, PullRequestLabelsAdded: []string{"run-ui-tests"}, PullRequestComment: "LGTM"},
+		} {
+			triggerAPIParams := []bitriseapi.TriggerAPIParamsModel{{BuildParams: buildParams}}
+			require.Equal(t, triggerAPIParams, labelWorkflows.Apply(triggerAPIParams))
+		}
+	}
+
+	t.Log("No label workflows")
+	{
+		triggerAPIParams := []bitriseapi.TriggerAPIParamsModel{{BuildParams: bitriseapi.BuildParamsModel{PullRequestLabelsAdded: []string{"run-ui-tests"}}}}
+		require.Equal(t, triggerAPIParams, LabelWorkflows(nil).Apply(triggerAPIParams))
+	}
+}
+
+func TestReadLabelWorkflowsFile(t *testing.T) {
+	t.Log("Valid label workflows file")
+	{
+		pth := filepath.Join(t.TempDir(), "labels.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"app-slug": {"run-ui-tests": "ui_test_workflow"}}`), 0600))
+
+		labelWorkflowsByApp, err := ReadLabelWorkflowsFile(pth)
+		require.NoError(t, err)
+		require.Equal(t, map[string]LabelWorkflows{"app-slug": {"run-ui-tests": "ui_test_workflow"}}, labelWorkflowsByApp)
+	}
+
+	t.Log("Empty workflow")
+	{
+		pth := filepath.Join(t.TempDir(), "labels.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"app-slug": {"run-ui-tests": ""}}`), 0600))
+
+		_, err := ReadLabelWorkflowsFile(pth)
+		require.EqualError(t, err, `invalid label workflows for app (app-slug): empty label or workflow ("run-ui-tests": "")`)
+	}
+
+	t.Log("Missing file")
+	{
+		_, err := ReadLabelWorkflowsFile(filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	}
+}
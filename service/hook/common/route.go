@@ -0,0 +1,9 @@
+package common
+
+// RouteSpecificProvider ...
+type RouteSpecificProvider interface {
+	// ForRoute returns the provider configured for the route the webhook was sent to.
+	// The route is identified by its hook ID (for the /h/SERVICE/HOOK-ID route,
+	//  empty otherwise) and by the app slug.
+	ForRoute(hookID, appSlug string) Provider
+}
@@ -0,0 +1,68 @@
+package common
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"net/http"
+	"strings"
+)
+
+var (
+	// ErrMissingSignature is returned by a SignatureVerifier if the request
+	//  doesn't include a signature, but a secret is configured for the app.
+	ErrMissingSignature = errors.New("missing webhook signature")
+	// ErrInvalidSignature is returned by a SignatureVerifier if the request's
+	//  signature doesn't match the one calculated with the configured secret.
+	ErrInvalidSignature = errors.New("invalid webhook signature")
+)
+
+// SignatureVerifier ...
+type SignatureVerifier interface {
+	// VerifySignature checks the signature of the request against the raw
+	//  request body, using the secret configured for the app.
+	// The returned error should wrap either ErrMissingSignature or ErrInvalidSignature.
+	VerifySignature(header http.Header, body []byte, secret string) error
+}
+
+// UsernameVerifier is implemented by the SignatureVerifier providers which authenticate the webhooks
+// with Basic authentication: the secret is the password, and the username is only checked if one is configured.
+type UsernameVerifier interface {
+	// VerifyUsername checks the username of the request against the one configured for the app.
+	// The returned error should wrap either ErrMissingSignature or ErrInvalidSignature.
+	VerifyUsername(header http.Header, username string) error
+}
+
+// VerifyHMACSignature checks whether the hex encoded signature
+// is the HMAC of the payload, calculated with the given secret.
+func VerifyHMACSignature(hashFunc func() hash.Hash, secret string, payload []byte, signature string) error {
+	signatureBytes, err := hex.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: signature is not hex encoded", ErrInvalidSignature)
+	}
+
+	mac := hmac.New(hashFunc, []byte(secret))
+	if _, err := mac.Write(payload); err != nil {
+		return err
+	}
+	if !hmac.Equal(mac.Sum(nil), signatureBytes) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+// VerifySHA256SignatureHeader checks the signature sent in the given header in "sha256=<hex digest>" format
+// (e.g. X-Hub-Signature), against the HMAC SHA256 of the payload, calculated with the given secret.
+func VerifySHA256SignatureHeader(header http.Header, headerName string, payload []byte, secret string) error {
+	signature := header.Get(headerName)
+	if signature == "" {
+		return fmt.Errorf("%w: no %s header found", ErrMissingSignature, headerName)
+	}
+	if !strings.HasPrefix(signature, "sha256=") {
+		return fmt.Errorf("%w: unsupported %s algorithm", ErrInvalidSignature, headerName)
+	}
+	return VerifyHMACSignature(sha256.New, secret, payload, strings.TrimPrefix(signature, "sha256="))
+}
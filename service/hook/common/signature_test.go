@@ -0,0 +1,83 @@
+package common
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestVerifyHMACSignature(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/master"}`)
+
+	t.Log("Valid SHA256 signature")
+	{
+		err := VerifyHMACSignature(sha256.New, "my-secret", payload, "5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0")
+		require.NoError(t, err)
+	}
+
+	t.Log("Valid SHA1 signature")
+	{
+		err := VerifyHMACSignature(sha1.New, "my-secret", payload, "0527727fe68882dbe8997d8fcc659429cc40fcb5")
+		require.NoError(t, err)
+	}
+
+	t.Log("Signature calculated with a different secret")
+	{
+		err := VerifyHMACSignature(sha256.New, "other-secret", payload, "5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0")
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	}
+
+	t.Log("Not hex encoded signature")
+	{
+		err := VerifyHMACSignature(sha256.New, "my-secret", payload, "not-hex")
+		require.ErrorIs(t, err, ErrInvalidSignature)
+	}
+}
+
+func TestVerifySHA256SignatureHeader(t *testing.T) {
+	payload := []byte(`{"ref":"refs/heads/master"}`)
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		secret  string
+		wantErr error
+	}{
+		{
+			name:   "Valid signature",
+			header: http.Header{"X-Hub-Signature": {"sha256=5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"}},
+			secret: "my-secret",
+		},
+		{
+			name:    "Signature mismatch",
+			header:  http.Header{"X-Hub-Signature": {"sha256=5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"}},
+			secret:  "other-secret",
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Unsupported algorithm",
+			header:  http.Header{"X-Hub-Signature": {"sha1=0527727fe68882dbe8997d8fcc659429cc40fcb5"}},
+			secret:  "my-secret",
+			wantErr: ErrInvalidSignature,
+		},
+		{
+			name:    "Missing signature",
+			header:  http.Header{},
+			secret:  "my-secret",
+			wantErr: ErrMissingSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := VerifySHA256SignatureHeader(tt.header, "X-Hub-Signature", payload, tt.secret)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -13,6 +13,7 @@ import (
 )
 
 const (
+	emptyCommitHash = "0000000000000000000000000000000000000000"
 
 	// ProviderID ...
 	ProviderID = "deveo"
@@ -26,6 +27,7 @@ type CommitModel struct {
 	Distinct      bool   `json:"distinct"`
 	CommitHash    string `json:"id"`
 	CommitMessage string `json:"message"`
+	Timestamp     string `json:"timestamp"`
 }
 
 // FilesChangedModel ...
@@ -48,16 +50,27 @@ type VersionedPathModel struct {
 	Rev  string `json:"rev"`
 }
 
+// PusherModel ...
+type PusherModel struct {
+	Name string `json:"name"`
+}
+
 // PushEventModel ...
 type PushEventModel struct {
 	Ref     string            `json:"ref"`
+	Before  string            `json:"before"`
+	After   string            `json:"after"`
+	Created bool              `json:"created"`
 	Deleted bool              `json:"deleted"`
 	Commits []CommitModel     `json:"commits"`
 	Files   FilesChangedModel `json:"files"`
+	Repo    RepoInfoModel     `json:"repository"`
+	Pusher  PusherModel       `json:"pusher"`
 }
 
 // RepoInfoModel ...
 type RepoInfoModel struct {
+	Name   string `json:"name"`
 	SSHURL string `json:"ssh_url"`
 }
 
@@ -72,7 +85,21 @@ type BranchInfoModel struct {
 // --- Webhook Provider Implementation ---
 
 // HookProvider ...
-type HookProvider struct{}
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
 
 func transformPushEvent(pushEvent PushEventModel) hookCommon.TransformResultModel {
 	if pushEvent.Deleted {
@@ -0,0 +1,73 @@
+package deveo
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// GatherMetrics ...
+func (hp HookProvider) GatherMetrics(r *http.Request, appSlug string) ([]common.Metrics, error) {
+	contentType, deveoEvent, err := detectContentTypeAndEventID(r.Header)
+	if err != nil {
+		return nil, err
+	}
+	if deveoEvent != "push" {
+		return nil, nil
+	}
+
+	var payload io.Reader = r.Body
+	if contentType == common.ContentTypeApplicationXWWWFormURLEncoded {
+		payload = strings.NewReader(r.PostFormValue("payload"))
+	}
+
+	var pushEvent PushEventModel
+	if err := json.NewDecoder(payload).Decode(&pushEvent); err != nil {
+		return nil, err
+	}
+
+	currentTime := hp.timeProvider.CurrentTime()
+	metricsList := hp.gatherMetrics(pushEvent, deveoEvent, appSlug, currentTime)
+	return metricsList, nil
+}
+
+func (hp HookProvider) gatherMetrics(pushEvent PushEventModel, deveoEvent, appSlug string, currentTime time.Time) []common.Metrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, commitIDAfter string, commitIDBefore string, oldestCommitTimestamp *time.Time, latestCommitTimestamp *time.Time, masterBranch string) common.PushMetrics
+
+	switch {
+	case pushEvent.Created || pushEvent.Before == emptyCommitHash:
+		constructorFunc = common.NewPushCreatedMetrics
+	case pushEvent.Deleted:
+		constructorFunc = common.NewPushDeletedMetrics
+	default:
+		constructorFunc = common.NewPushMetrics
+	}
+
+	var oldestCommitTime, latestCommitTime *time.Time
+	if len(pushEvent.Commits) > 0 {
+		// Commits are in descending order, by commit date-time (first one is the latest)
+		latestCommitTime = parseTime(pushEvent.Commits[0].Timestamp)
+		oldestCommitTime = parseTime(pushEvent.Commits[len(pushEvent.Commits)-1].Timestamp)
+	}
+
+	generalMetrics := common.NewGeneralMetrics(ProviderID, pushEvent.Repo.Name, currentTime, nil, appSlug, common.OriginalTrigger(deveoEvent, ""), pushEvent.Pusher.Name, pushEvent.Ref)
+	metrics := constructorFunc(generalMetrics, pushEvent.After, pushEvent.Before, oldestCommitTime, latestCommitTime, "")
+	metrics.ChangedFiles = len(pushEvent.Files.Modified) + len(pushEvent.Files.Renamed)
+	metrics.Additions = len(pushEvent.Files.Added)
+	metrics.Deletions = len(pushEvent.Files.Deleted)
+
+	return []common.Metrics{metrics}
+}
+
+func parseTime(s string) *time.Time {
+	// 2024-03-07T10:15:30+01:00
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,133 @@
+package deveo
+
+import (
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sampleMetricsPushData = `{
+  "ref": "refs/heads/master",
+  "before": "28e1879d029cb852e4844d9c718537df08844e03",
+  "after": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+  "created": false,
+  "deleted": false,
+  "commits": [
+    {
+      "distinct": true,
+      "id": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+      "message": "second commit",
+      "timestamp": "2024-03-07T10:20:00+01:00"
+    },
+    {
+      "distinct": true,
+      "id": "bffeb74224043ba2feb48d137756c8a9331c449a",
+      "message": "first commit",
+      "timestamp": "2024-03-07T10:15:30+01:00"
+    }
+  ],
+  "files": {
+    "added": ["README.md"],
+    "modified": ["main.go", "go.mod"],
+    "deleted": [],
+    "renamed": []
+  },
+  "repository": {
+    "name": "webhooks"
+  },
+  "pusher": {
+    "name": "deveo-user"
+  }
+}`
+
+type testTimeProvider struct {
+	currentTime time.Time
+}
+
+func (p testTimeProvider) CurrentTime() time.Time {
+	return p.currentTime
+}
+
+func TestHookProvider_gatherMetrics(t *testing.T) {
+	currentTime := time.Date(2024, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		contentType string
+		payload     string
+		deveoEvent  string
+		appSlug     string
+		want        string
+	}{
+		{
+			name:        "Push webhook",
+			contentType: "application/json",
+			payload:     sampleMetricsPushData,
+			deveoEvent:  "push",
+			appSlug:     "slug",
+			want:        `{"event":"git_push","action":"pushed","provider_type":"deveo","repository":"webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","user_name":"deveo-user","git_ref":"refs/heads/master","commit_id_after":"83b86e5f286f546dc5a4a58db66ceef44460c85e","commit_id_before":"28e1879d029cb852e4844d9c718537df08844e03","oldest_commit_timestamp":"2024-03-07T10:15:30+01:00","latest_commit_timestamp":"2024-03-07T10:20:00+01:00","changed_files_count":2,"addition_count":1,"deletion_count":0}`,
+		},
+		{
+			name:        "Push webhook - form encoded",
+			contentType: "application/x-www-form-urlencoded",
+			payload:     url.Values{"payload": {sampleMetricsPushData}}.Encode(),
+			deveoEvent:  "push",
+			appSlug:     "slug",
+			want:        `{"event":"git_push","action":"pushed","provider_type":"deveo","repository":"webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","user_name":"deveo-user","git_ref":"refs/heads/master","commit_id_after":"83b86e5f286f546dc5a4a58db66ceef44460c85e","commit_id_before":"28e1879d029cb852e4844d9c718537df08844e03","oldest_commit_timestamp":"2024-03-07T10:15:30+01:00","latest_commit_timestamp":"2024-03-07T10:20:00+01:00","changed_files_count":2,"addition_count":1,"deletion_count":0}`,
+		},
+		{
+			name:        "Tag push webhook - created",
+			contentType: "application/json",
+			payload:     `{"ref": "refs/tags/v0.0.2", "before": "0000000000000000000000000000000000000000", "after": "2e197ebd2330183ae11338151cf3a75db0c23c92", "created": true, "pusher": {"name": "deveo-user"}}`,
+			deveoEvent:  "push",
+			appSlug:     "slug",
+			want:        `{"event":"git_push","action":"created","provider_type":"deveo","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","user_name":"deveo-user","git_ref":"refs/tags/v0.0.2","commit_id_after":"2e197ebd2330183ae11338151cf3a75db0c23c92","commit_id_before":"0000000000000000000000000000000000000000","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:        "Branch deleted webhook",
+			contentType: "application/json",
+			payload:     `{"ref": "refs/heads/feature", "before": "2e197ebd2330183ae11338151cf3a75db0c23c92", "after": "0000000000000000000000000000000000000000", "deleted": true}`,
+			deveoEvent:  "push",
+			appSlug:     "slug",
+			want:        `{"event":"git_push","action":"deleted","provider_type":"deveo","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","git_ref":"refs/heads/feature","commit_id_after":"0000000000000000000000000000000000000000","commit_id_before":"2e197ebd2330183ae11338151cf3a75db0c23c92","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:        "Unsupported webhook",
+			contentType: "application/json",
+			payload:     `{}`,
+			deveoEvent:  "issue",
+			appSlug:     "slug",
+			want:        "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			request := http.Request{
+				Method: http.MethodPost,
+				Header: http.Header{
+					"Content-Type":  {tt.contentType},
+					"X-Deveo-Event": {tt.deveoEvent},
+				},
+				Body: io.NopCloser(strings.NewReader(tt.payload)),
+			}
+
+			hp := NewHookProvider(testTimeProvider{currentTime: currentTime}).(HookProvider)
+			got, err := hp.GatherMetrics(&request, tt.appSlug)
+			require.NoError(t, err)
+			if tt.want != "" {
+				require.Equal(t, len(got), 1)
+				gotMetrics := got[0]
+				gotBytes, err := gotMetrics.Serialise()
+				require.NoError(t, err)
+				require.Equal(t, tt.want, string(gotBytes))
+			} else {
+				require.Nil(t, got)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"slices"
 	"strings"
 
 	"github.com/gorilla/mux"
@@ -16,14 +17,25 @@ import (
 	"github.com/bitrise-io/api-utils/logging"
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
 	"github.com/bitrise-io/bitrise-webhooks/config"
+	"github.com/bitrise-io/bitrise-webhooks/internal/credentials"
+	"github.com/bitrise-io/bitrise-webhooks/internal/dedup"
+	"github.com/bitrise-io/bitrise-webhooks/internal/executor"
+	"github.com/bitrise-io/bitrise-webhooks/internal/glob"
+	"github.com/bitrise-io/bitrise-webhooks/internal/outbox"
+	"github.com/bitrise-io/bitrise-webhooks/internal/projects"
 	"github.com/bitrise-io/bitrise-webhooks/internal/pubsub"
+	"github.com/bitrise-io/bitrise-webhooks/internal/rules"
 	"github.com/bitrise-io/bitrise-webhooks/metrics"
 	"github.com/bitrise-io/bitrise-webhooks/service"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/assembla"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/bitbucketserver"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/bitbucketv2"
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/codecommit"
 	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/deveo"
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/generic"
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/gerrit"
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/gitea"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/github"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/gitlab"
 	"github.com/bitrise-io/bitrise-webhooks/service/hook/gogs"
@@ -35,20 +47,55 @@ import (
 // Client ...
 type Client struct {
 	PubsubClient *pubsub.Client
+	// CredentialStore resolves the hook IDs of the /h/{service-id}/{hook-id} route
+	CredentialStore credentials.Store
+	// Outbox, if set, stores the Build Trigger calls which failed with a transient error, to be retried later
+	Outbox *outbox.Outbox
+	// DeliveryStore, if set, is used to deduplicate the redeliveries of the webhooks,
+	//  by the providers' delivery IDs
+	DeliveryStore dedup.Store
+	// TriggerExecutor runs the Build Trigger calls whose response is not waited for
+	//  (see: TransformResultModel.DontWaitForTriggerResponse).
+	//  If not set, every Build Trigger call is waited for.
+	TriggerExecutor *executor.Executor
+	// GenericMappings are the mappings of the generic provider, keyed by hook ID or app slug
+	GenericMappings map[string]*generic.Mapping
+	// CodeCommitTopicArns, if set, are the ARNs of the SNS topics whose messages the codecommit provider accepts
+	CodeCommitTopicArns []string
+	// RoutingRules decide per app whether a build should be triggered for the transformed webhook, keyed by app slug.
+	//  The rules are evaluated after the ChatOps commands, so they see the workflow of a `run` command, and have the final word.
+	RoutingRules map[string]rules.Rules
+	// ProjectMaps map the changed files of the webhooks to the affected projects of the app, keyed by app slug
+	ProjectMaps map[string]projects.Map
+	// ChatOps triggers builds for the pull request comments of an app only if they include a ChatOps command
+	//  (e.g. `/bitrise run deploy`), which can change the workflow and the environment variables of the build, keyed by app slug.
+	//  The commands are applied before the RoutingRules.
+	ChatOps map[string]*hookCommon.ChatOpsConfig
+	// LabelWorkflows map the newly added pull request labels to the workflows to trigger, keyed by app slug
+	LabelWorkflows map[string]hookCommon.LabelWorkflows
 }
 
-func supportedProviders(logger *zap.Logger) map[string]hookCommon.Provider {
+func supportedProviders(logger *zap.Logger, isDeduplicationEnabled bool, genericMappings map[string]*generic.Mapping, codeCommitTopicArns []string) map[string]hookCommon.Provider {
+	bitbucketV2Provider := bitbucketv2.NewDefaultHookProvider()
+	if isDeduplicationEnabled {
+		bitbucketV2Provider = bitbucketv2.NewRetryAcceptingHookProvider(hookCommon.NewDefaultTimeProvider())
+	}
+
 	return map[string]hookCommon.Provider{
 		github.ProviderID:                   github.NewDefaultHookProvider(),
-		bitbucketv2.ProviderID:              bitbucketv2.NewDefaultHookProvider(),
+		bitbucketv2.ProviderID:              bitbucketV2Provider,
 		bitbucketserver.ProviderID:          bitbucketserver.NewDefaultHookProvider(),
-		slack.ProviderID:                    slack.HookProvider{},
-		visualstudioteamservices.ProviderID: visualstudioteamservices.HookProvider{},
+		slack.ProviderID:                    slack.NewDefaultHookProvider(),
+		visualstudioteamservices.ProviderID: visualstudioteamservices.NewDefaultHookProvider(),
 		gitlab.ProviderID:                   gitlab.NewDefaultHookProvider(logger),
-		gogs.ProviderID:                     gogs.HookProvider{},
-		deveo.ProviderID:                    deveo.HookProvider{},
-		assembla.ProviderID:                 assembla.HookProvider{},
+		gogs.ProviderID:                     gogs.NewDefaultHookProvider(),
+		gitea.ProviderID:                    gitea.NewDefaultHookProvider(),
+		gerrit.ProviderID:                   gerrit.NewDefaultHookProvider(),
+		codecommit.ProviderID:               codecommit.NewDefaultHookProvider(codeCommitTopicArns),
+		deveo.ProviderID:                    deveo.NewDefaultHookProvider(),
+		assembla.ProviderID:                 assembla.NewDefaultHookProvider(),
 		passthrough.ProviderID:              passthrough.HookProvider{},
+		generic.ProviderID:                  generic.NewHookProvider(genericMappings),
 	}
 }
 
@@ -72,6 +119,19 @@ func respondWithErrorString(w http.ResponseWriter, provider *hookCommon.Provider
 	service.RespondWith(w, httpStatusCode, respInfo.Data)
 }
 
+func respondWithErrorStringAndStatusCode(w http.ResponseWriter, provider *hookCommon.Provider, errStr string, httpStatusCode int) {
+	responseProvider := hookCommon.ResponseTransformer(hookCommon.DefaultResponseProvider{})
+	if provider != nil {
+		if respTransformer, ok := (*provider).(hookCommon.ResponseTransformer); ok {
+			// provider can transform responses - let it do so
+			responseProvider = respTransformer
+		}
+	}
+	//
+	respInfo := responseProvider.TransformErrorMessageResponse(errStr)
+	service.RespondWith(w, httpStatusCode, respInfo.Data)
+}
+
 func respondWithSuccessMessage(w http.ResponseWriter, provider *hookCommon.Provider, msg string) {
 	responseProvider := hookCommon.ResponseTransformer(hookCommon.DefaultResponseProvider{})
 	if provider != nil {
@@ -134,6 +194,159 @@ func triggerBuild(ctx context.Context, triggerURL *url.URL, apiToken string, tri
 	return responseModel, isSuccess, nil
 }
 
+// queueForRetry stores the failed Build Trigger call in the outbox, if the outbox is configured
+// and the error is a transient one. Returns false if the call was not queued.
+func (c *Client) queueForRetry(ctx context.Context, appSlug, hookID string, triggerURL *url.URL, apiToken string, triggerAPIParams bitriseapi.TriggerAPIParamsModel, triggerErr error) (outbox.Entry, bool) {
+	logger := logging.WithContext(ctx)
+
+	if c.Outbox == nil || !bitriseapi.IsRetryableError(triggerErr) {
+		return outbox.Entry{}, false
+	}
+
+	entry, err := c.Outbox.Enqueue(appSlug, hookID, triggerURL, apiToken, triggerAPIParams, triggerErr)
+	if err != nil {
+		logger.Error(" [!] Exception: failed to queue the build trigger for retry", zap.Error(err))
+		return outbox.Entry{}, false
+	}
+	logger.Warn("Build trigger failed, queued for retry", zap.String("outbox_entry_id", entry.ID), zap.Error(triggerErr))
+	return entry, true
+}
+
+// triggerBuildInBackground is the TriggerExecutor job of a Build Trigger call whose response is not waited for:
+// as nobody sees its result in the webhook response, the outcome is logged and recorded as a metric.
+func (c *Client) triggerBuildInBackground(ctx context.Context, appSlug, hookID string, triggerURL *url.URL, apiToken string, triggerAPIParams bitriseapi.TriggerAPIParamsModel) {
+	ctx, finish := metrics.StartBackgroundTrigger(ctx)
+	logger := logging.WithContext(ctx).With(
+		zap.String("app_slug", appSlug),
+		zap.String("branch", triggerAPIParams.BuildParams.Branch),
+		zap.String("commit_hash", triggerAPIParams.BuildParams.CommitHash),
+	)
+
+	triggerResp, isSuccess, err := triggerBuild(ctx, triggerURL, apiToken, triggerAPIParams)
+	switch {
+	case err != nil:
+		if _, isQueued := c.queueForRetry(ctx, appSlug, hookID, triggerURL, apiToken, triggerAPIParams, err); isQueued {
+			finish(metrics.BackgroundTriggerQueued, nil)
+			return
+		}
+		logger.Error(" [!] Exception: background build trigger failed", zap.Error(err))
+		finish(metrics.BackgroundTriggerError, err)
+	case isSuccess:
+		logger.Info("Background build trigger succeeded")
+		finish(metrics.BackgroundTriggerSuccess, nil)
+	default:
+		logger.Warn("Background build trigger was rejected", zap.String("status", triggerResp.Status), zap.String("message", triggerResp.Message))
+		finish(metrics.BackgroundTriggerFailed, nil)
+	}
+}
+
+// readRequestBody reads the whole request body, and rewinds it
+// so that it can be read again by the next consumer.
+func readRequestBody(r *http.Request) ([]byte, error) {
+	if r.Body == nil {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+	r.Body = io.NopCloser(bytes.NewBuffer(body))
+
+	return body, nil
+}
+
+// verifyRequestSignature checks the signature of the request with the provider's
+// SignatureVerifier, against the exact raw bytes of the request body,
+// and the username of the request with the provider's UsernameVerifier, if a username is configured.
+// Returns the HTTP status code which should be used to reject the request if the check fails.
+func verifyRequestSignature(r *http.Request, hookProvider hookCommon.Provider, secret, username string) (int, error) {
+	signatureVerifier, isSignatureVerifier := hookProvider.(hookCommon.SignatureVerifier)
+	if !isSignatureVerifier {
+		return http.StatusForbidden, errors.New("a webhook secret is configured, but the provider does not support signature verification")
+	}
+
+	body, err := readRequestBody(r)
+	if err != nil {
+		return http.StatusBadRequest, errors.Wrap(err, "failed to read request body")
+	}
+
+	err = signatureVerifier.VerifySignature(r.Header, body, secret)
+	if err == nil && username != "" {
+		usernameVerifier, isUsernameVerifier := hookProvider.(hookCommon.UsernameVerifier)
+		if !isUsernameVerifier {
+			return http.StatusForbidden, errors.New("a webhook username is configured, but the provider does not support username verification")
+		}
+		err = usernameVerifier.VerifyUsername(r.Header, username)
+	}
+	if err != nil {
+		if errors.Is(err, hookCommon.ErrMissingSignature) {
+			return http.StatusUnauthorized, err
+		}
+		return http.StatusForbidden, err
+	}
+	return 0, nil
+}
+
+// deliveryKey returns the deduplication key of the request, or an empty string
+// if the provider does not send delivery IDs.
+// The key must include every part of the route which identifies the apps the builds are triggered on:
+// the same delivery ID sent to two different routes is two different deliveries, not a redelivery.
+// The requests of the /h/{service-id}/{hook-id} route are keyed by the hook ID, as a fan-out hook has no single app slug.
+func deliveryKey(r *http.Request, hookProvider hookCommon.Provider, serviceID, hookID, appSlug string) string {
+	deliveryIDProvider, isDeliveryIDProvider := hookProvider.(hookCommon.DeliveryIDProvider)
+	if !isDeliveryIDProvider {
+		return ""
+	}
+	deliveryID := deliveryIDProvider.DeliveryID(r.Header)
+	if deliveryID == "" {
+		return ""
+	}
+	if hookID != "" {
+		// prefixed, so that a hook ID can't collide with an app slug
+		return fmt.Sprintf("%s/hook:%s/%s", serviceID, hookID, deliveryID)
+	}
+	return fmt.Sprintf("%s/%s/%s", serviceID, appSlug, deliveryID)
+}
+
+// responseRecorder records the response of the first delivery of a webhook,
+// so that it can be sent again for the redeliveries.
+type responseRecorder struct {
+	http.ResponseWriter
+	statusCode int
+	body       bytes.Buffer
+}
+
+func (rec *responseRecorder) WriteHeader(statusCode int) {
+	rec.statusCode = statusCode
+	rec.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (rec *responseRecorder) Write(b []byte) (int, error) {
+	if rec.statusCode == 0 {
+		rec.statusCode = http.StatusOK
+	}
+	rec.body.Write(b)
+	return rec.ResponseWriter.Write(b)
+}
+
+func (rec *responseRecorder) response() dedup.Response {
+	return dedup.Response{
+		StatusCode:  rec.statusCode,
+		ContentType: rec.Header().Get("Content-Type"),
+		Body:        rec.body.Bytes(),
+	}
+}
+
+func respondWithRecordedResponse(w http.ResponseWriter, response dedup.Response) {
+	w.Header().Set("Content-Type", response.ContentType)
+	w.Header().Set("X-Bitrise-Duplicate-Delivery", "true")
+	w.WriteHeader(response.StatusCode)
+	if _, err := w.Write(response.Body); err != nil {
+		logging.WithContext(nil).Error(" [!] Exception: respondWithRecordedResponse", zap.Error(err))
+	}
+}
+
 // ------------------------------
 // --- Main HTTP Handler code ---
 
@@ -143,6 +356,7 @@ func (c *Client) HTTPHandler(w http.ResponseWriter, r *http.Request) {
 	serviceID := vars["service-id"]
 	appSlug := vars["app-slug"]
 	apiToken := vars["api-token"]
+	hookID := vars["hook-id"]
 
 	reqContext := r.Context()
 
@@ -152,19 +366,104 @@ func (c *Client) HTTPHandler(w http.ResponseWriter, r *http.Request) {
 		respondWithErrorString(w, nil, "No service-id defined")
 		return
 	}
-	hookProvider, isSupported := supportedProviders(logger)[serviceID]
+	hookProvider, isSupported := supportedProviders(logger, c.DeliveryStore != nil, c.GenericMappings, c.CodeCommitTopicArns)[serviceID]
 	if !isSupported {
 		respondWithErrorString(w, nil, fmt.Sprintf("Unsupported Webhook Type / Provider: %s", serviceID))
 		return
 	}
 
-	if appSlug == "" {
-		respondWithErrorString(w, &hookProvider, "No App Slug parameter defined")
-		return
+	secret, username := "", ""
+	var targets []credentials.Target
+	if hookID != "" {
+		if c.CredentialStore == nil {
+			respondWithErrorString(w, &hookProvider, "No credential store configured, hook IDs can't be resolved")
+			return
+		}
+		hookCredentials, isRegistered, err := c.CredentialStore.Get(hookID)
+		if err != nil {
+			logger.Error(" [!] Exception: hookHandler: failed to resolve hook ID", zap.String("hook_id", hookID), zap.Error(err))
+			respondWithErrorStringAndStatusCode(w, &hookProvider, "Failed to resolve hook ID", http.StatusInternalServerError)
+			return
+		}
+		if !isRegistered {
+			respondWithErrorStringAndStatusCode(w, &hookProvider, fmt.Sprintf("Unknown hook ID: %s", hookID), http.StatusNotFound)
+			return
+		}
+		appSlug = hookCredentials.AppSlug
+		apiToken = hookCredentials.APIToken
+		secret = hookCredentials.Secret
+		username = hookCredentials.Username
+		targets = hookCredentials.Targets
 	}
-	if apiToken == "" {
-		respondWithErrorString(w, &hookProvider, "No API Token parameter defined")
-		return
+
+	if len(targets) == 0 {
+		if appSlug == "" {
+			respondWithErrorString(w, &hookProvider, "No App Slug parameter defined")
+			return
+		}
+		if apiToken == "" {
+			respondWithErrorString(w, &hookProvider, "No API Token parameter defined")
+			return
+		}
+		targets = []credentials.Target{{AppSlug: appSlug, APIToken: apiToken}}
+	}
+
+	if routeSpecificProvider, ok := hookProvider.(hookCommon.RouteSpecificProvider); ok {
+		hookProvider = routeSpecificProvider.ForRoute(hookID, appSlug)
+	}
+
+	if secret == "" {
+		secret = config.WebhookSecret(serviceID, appSlug)
+		username = config.WebhookUsername(serviceID, appSlug)
+	}
+	if secret != "" {
+		var httpStatusCode int
+		var err error
+		metrics.Trace("Hook: VerifySignature", func() {
+			httpStatusCode, err = verifyRequestSignature(r, hookProvider, secret, username)
+		})
+		if err != nil {
+			logger.Warn("Webhook signature verification failed", zap.String("app_slug", appSlug), zap.String("service_id", serviceID), zap.Error(err))
+			respondWithErrorStringAndStatusCode(w, &hookProvider, fmt.Sprintf("Webhook signature verification failed: %s", err), httpStatusCode)
+			return
+		}
+	}
+
+	// set once a build was triggered (or queued) for the webhook
+	isAnyBuildTriggered := false
+	if c.DeliveryStore != nil {
+		if key := deliveryKey(r, hookProvider, serviceID, hookID, appSlug); key != "" {
+			response, isReserved, err := c.DeliveryStore.Reserve(key)
+			switch {
+			case err != nil:
+				// don't drop the webhook just because it can't be deduplicated
+				logger.Error(" [!] Exception: hookHandler: failed to check delivery", zap.String("delivery_key", key), zap.Error(err))
+			case !isReserved && response == nil:
+				logger.Info("Duplicate delivery, the original delivery is still being processed", zap.String("delivery_key", key))
+				respondWithSuccessMessage(w, &hookProvider, "Acknowledged, but skipping. Reason: this delivery is already being processed")
+				return
+			case !isReserved:
+				logger.Info("Duplicate delivery, responding with the original result", zap.String("delivery_key", key))
+				respondWithRecordedResponse(w, *response)
+				return
+			default:
+				recorder := &responseRecorder{ResponseWriter: w}
+				w = recorder
+				defer func() {
+					// server errors are not stored, so that a redelivery can trigger the build(s) again,
+					// unless a build was already triggered: a redelivery would trigger that build twice
+					if (recorder.statusCode == 0 || recorder.statusCode >= 500) && !isAnyBuildTriggered {
+						if err := c.DeliveryStore.Release(key); err != nil {
+							logger.Error(" [!] Exception: hookHandler: failed to release delivery", zap.String("delivery_key", key), zap.Error(err))
+						}
+						return
+					}
+					if err := c.DeliveryStore.Complete(key, recorder.response()); err != nil {
+						logger.Error(" [!] Exception: hookHandler: failed to store delivery result", zap.String("delivery_key", key), zap.Error(err))
+					}
+				}()
+			}
+		}
 	}
 
 	metricsProvider, isMetricsProvider := hookProvider.(hookCommon.MetricsProvider)
@@ -174,24 +473,23 @@ func (c *Client) HTTPHandler(w http.ResponseWriter, r *http.Request) {
 
 		metrics.Trace("Hook: GatherMetrics", func() {
 			// GatherMetrics reads the request body, so it needs to be rewinded
-			var originalBody []byte
-			if r.Body != nil {
-				body, err := io.ReadAll(r.Body)
-				if err != nil {
-					logger.Error(" [!] Exception: failed to read request body", zap.Error(err))
-					return
-				}
-
-				originalBody = body
-			}
-			if originalBody != nil {
-				r.Body = io.NopCloser(bytes.NewBuffer(originalBody))
+			originalBody, readErr := readRequestBody(r)
+			if readErr != nil {
+				logger.Error(" [!] Exception: failed to read request body", zap.Error(readErr))
+				return
 			}
 
-			webhookMetricsList, err = metricsProvider.GatherMetrics(r, appSlug)
+			for _, target := range targets {
+				var targetMetricsList []hookCommon.Metrics
+				targetMetricsList, err = metricsProvider.GatherMetrics(r, target.AppSlug)
 
-			if originalBody != nil {
-				r.Body = io.NopCloser(bytes.NewBuffer(originalBody))
+				if originalBody != nil {
+					r.Body = io.NopCloser(bytes.NewBuffer(originalBody))
+				}
+				if err != nil {
+					break
+				}
+				webhookMetricsList = append(webhookMetricsList, targetMetricsList...)
 			}
 		})
 
@@ -245,15 +543,19 @@ func (c *Client) HTTPHandler(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Let's Trigger a build / some builds!
-	triggerURL := config.SendRequestToURL
-	if triggerURL == nil {
-		u, err := bitriseapi.BuildTriggerURL(config.BuildTriggerURL, appSlug)
-		if err != nil {
-			logger.Error(" [!] Exception: hookHandler: failed to create Build Trigger URL", zap.Error(err))
-			respondWithErrorString(w, &hookProvider, fmt.Sprintf("Failed to create Build Trigger URL: %s", err))
-			return
+	triggerURLs := make([]*url.URL, len(targets))
+	for i, target := range targets {
+		triggerURL := config.SendRequestToURL
+		if triggerURL == nil {
+			u, err := bitriseapi.BuildTriggerURL(config.BuildTriggerURL, target.AppSlug)
+			if err != nil {
+				logger.Error(" [!] Exception: hookHandler: failed to create Build Trigger URL", zap.Error(err))
+				respondWithErrorString(w, &hookProvider, fmt.Sprintf("Failed to create Build Trigger URL: %s", err))
+				return
+			}
+			triggerURL = u
 		}
-		triggerURL = u
+		triggerURLs[i] = triggerURL
 	}
 
 	buildTriggerCount := len(hookTransformResult.TriggerAPIParams)
@@ -266,58 +568,177 @@ func (c *Client) HTTPHandler(w http.ResponseWriter, r *http.Request) {
 		logger.Warn(fmt.Sprintf("[skipped by pr description] app: %s, service: %s", appSlug, serviceID))
 	}
 
-	respondWith := hookCommon.TransformResponseInputModel{
+	var respondWith hookCommon.TransformResponseInputModel
+	metrics.Trace("Hook: Trigger Builds", func() {
+		if len(targets) == 1 {
+			respondWith = c.triggerBuilds(reqContext, serviceID, hookID, targets[0], triggerURLs[0], hookTransformResult)
+			return
+		}
+
+		// fan-out: the results are aggregated, and reported per app too
+		respondWith = newTransformResponseInputModel()
+		respondWith.AppResponses = map[string]hookCommon.TransformResponseInputModel{}
+		for i, target := range targets {
+			appResponses := c.triggerBuilds(reqContext, serviceID, hookID, target, triggerURLs[i], hookTransformResult)
+			respondWith.AppResponses[target.AppSlug] = appResponses
+
+			respondWith.Errors = append(respondWith.Errors, appResponses.Errors...)
+			respondWith.SuccessTriggerResponses = append(respondWith.SuccessTriggerResponses, appResponses.SuccessTriggerResponses...)
+			respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, appResponses.SkippedTriggerResponses...)
+			respondWith.FailedTriggerResponses = append(respondWith.FailedTriggerResponses, appResponses.FailedTriggerResponses...)
+			respondWith.QueuedTriggerResponses = append(respondWith.QueuedTriggerResponses, appResponses.QueuedTriggerResponses...)
+			respondWith.DidNotWaitForTriggerResponse = respondWith.DidNotWaitForTriggerResponse || appResponses.DidNotWaitForTriggerResponse
+		}
+	})
+	isAnyBuildTriggered = len(respondWith.SuccessTriggerResponses) > 0 || len(respondWith.QueuedTriggerResponses) > 0 || respondWith.DidNotWaitForTriggerResponse
+
+	respondWithResults(w, &hookProvider, respondWith)
+}
+
+// newTransformResponseInputModel ...
+func newTransformResponseInputModel() hookCommon.TransformResponseInputModel {
+	return hookCommon.TransformResponseInputModel{
 		Errors:                       []string{},
 		SuccessTriggerResponses:      []bitriseapi.TriggerAPIResponseModel{},
 		SkippedTriggerResponses:      []hookCommon.SkipAPIResponseModel{},
 		FailedTriggerResponses:       []bitriseapi.TriggerAPIResponseModel{},
+		QueuedTriggerResponses:       []hookCommon.QueuedAPIResponseModel{},
 		DidNotWaitForTriggerResponse: false,
 	}
-	metrics.Trace("Hook: Trigger Builds", func() {
-		for _, aBuildTriggerParam := range hookTransformResult.TriggerAPIParams {
-			commitMessage := aBuildTriggerParam.BuildParams.CommitMessage
+}
+
+// triggerBuilds triggers the builds of the transformed webhook on the target app.
+func (c *Client) triggerBuilds(ctx context.Context, serviceID, hookID string, target credentials.Target, triggerURL *url.URL, hookTransformResult hookCommon.TransformResultModel) hookCommon.TransformResponseInputModel {
+	logger := logging.WithContext(ctx)
+	appSlug := target.AppSlug
+	apiToken := target.APIToken
+
+	respondWith := newTransformResponseInputModel()
+	for _, aBuildTriggerParam := range c.LabelWorkflows[appSlug].Apply(hookTransformResult.TriggerAPIParams) {
+		commitMessage := aBuildTriggerParam.BuildParams.CommitMessage
+
+		if hookCommon.ContainsSkipInstruction(commitMessage) {
+			respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, hookCommon.SkipAPIResponseModel{
+				Message:       "Build skipped because the commit message included a skip ci keyword ([skip ci] or [ci skip]).",
+				CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
+				CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
+				Branch:        aBuildTriggerParam.BuildParams.Branch,
+			})
+			continue
+		} else if hookCommon.ContainsSkipInstruction(aBuildTriggerParam.BuildParams.PullRequestComment) {
+			respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, hookCommon.SkipAPIResponseModel{
+				Message:       "Build skipped because the PR comment included a skip ci keyword ([skip ci] or [ci skip]).",
+				CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
+				CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
+				Branch:        aBuildTriggerParam.BuildParams.Branch,
+			})
+			continue
+		}
 
-			if hookCommon.ContainsSkipInstruction(commitMessage) {
+		if len(target.Paths) > 0 {
+			// the webhooks which don't include the changed files (e.g. pull requests) are not filtered
+			if changedPaths := aBuildTriggerParam.BuildParams.ChangedPaths(); len(changedPaths) > 0 && !glob.MatchAnyOf(target.Paths, changedPaths) {
 				respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, hookCommon.SkipAPIResponseModel{
-					Message:       "Build skipped because the commit message included a skip ci keyword ([skip ci] or [ci skip]).",
+					Message:       "Build skipped because none of the changed files matches the paths of the app.",
 					CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
 					CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
 					Branch:        aBuildTriggerParam.BuildParams.Branch,
 				})
 				continue
-			} else if hookCommon.ContainsSkipInstruction(aBuildTriggerParam.BuildParams.PullRequestComment) {
+			}
+		}
+
+		if chatOps := c.ChatOps[appSlug]; chatOps != nil && aBuildTriggerParam.BuildParams.PullRequestComment != "" {
+			if skipReason := chatOps.Apply(&aBuildTriggerParam.BuildParams); skipReason != "" {
 				respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, hookCommon.SkipAPIResponseModel{
-					Message:       "Build skipped because the PR comment included a skip ci keyword ([skip ci] or [ci skip]).",
+					Message:       skipReason,
 					CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
 					CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
 					Branch:        aBuildTriggerParam.BuildParams.Branch,
 				})
 				continue
 			}
+		}
 
-			triggerBuildAndPrepareRespondWith := func(ctx context.Context) {
-				if aBuildTriggerParam.TriggeredBy == "" {
-					aBuildTriggerParam.TriggeredBy = hookCommon.DefaultTriggeredBy
+		if rule := c.RoutingRules[appSlug].Match(serviceID, aBuildTriggerParam); rule != nil {
+			switch rule.Action {
+			case rules.ActionSkip:
+				message := "Build skipped by a routing rule."
+				if rule.Reason != "" {
+					message = fmt.Sprintf("Build skipped by a routing rule: %s", rule.Reason)
 				}
-				if triggerResp, isSuccess, err := triggerBuild(ctx, triggerURL, apiToken, aBuildTriggerParam); err != nil {
-					respondWith.Errors = append(respondWith.Errors, fmt.Sprintf("Failed to Trigger Build: %s", err))
-				} else if isSuccess {
-					respondWith.SuccessTriggerResponses = append(respondWith.SuccessTriggerResponses, triggerResp)
-				} else {
-					respondWith.FailedTriggerResponses = append(respondWith.FailedTriggerResponses, triggerResp)
+				respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, hookCommon.SkipAPIResponseModel{
+					Message:       message,
+					CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
+					CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
+					Branch:        aBuildTriggerParam.BuildParams.Branch,
+				})
+				continue
+			case rules.ActionSetWorkflow:
+				aBuildTriggerParam.BuildParams.WorkflowID = rule.WorkflowID
+			}
+		}
+
+		// the webhooks which don't include the changed files (e.g. pull requests) don't have affected projects
+		if projectMap, ok := c.ProjectMaps[appSlug]; ok {
+			if changedPaths := aBuildTriggerParam.BuildParams.ChangedPaths(); len(changedPaths) > 0 {
+				affected := projectMap.Affected(changedPaths)
+				if len(affected) == 0 && projectMap.SkipIfUnaffected {
+					respondWith.SkippedTriggerResponses = append(respondWith.SkippedTriggerResponses, hookCommon.SkipAPIResponseModel{
+						Message:       "Build skipped because none of the changed files belongs to a project of the app.",
+						CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
+						CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
+						Branch:        aBuildTriggerParam.BuildParams.Branch,
+					})
+					continue
 				}
+				// the env items are shared by the target apps of the webhook, don't append to them in place
+				aBuildTriggerParam.BuildParams.Environments = append(slices.Clip(aBuildTriggerParam.BuildParams.Environments), bitriseapi.EnvironmentItem{
+					Name:     projects.AffectedProjectsEnvKey,
+					Value:    projects.EnvValue(affected),
+					IsExpand: false,
+				})
 			}
+		}
+
+		if aBuildTriggerParam.TriggeredBy == "" {
+			aBuildTriggerParam.TriggeredBy = hookCommon.DefaultTriggeredBy
+		}
 
-			if hookTransformResult.DontWaitForTriggerResponse {
-				// send it, but don't wait for response
-				go triggerBuildAndPrepareRespondWith(reqContext)
+		if hookTransformResult.DontWaitForTriggerResponse && c.TriggerExecutor != nil {
+			// send it, but don't wait for response
+			err := c.TriggerExecutor.Submit(ctx, func(ctx context.Context) {
+				c.triggerBuildInBackground(ctx, appSlug, hookID, triggerURL, apiToken, aBuildTriggerParam)
+			})
+			if err == nil {
 				respondWith.DidNotWaitForTriggerResponse = true
-			} else {
-				// send and wait
-				triggerBuildAndPrepareRespondWith(reqContext)
+				continue
 			}
+			// rather wait for the response than lose the build
+			logger.Warn("Failed to start background build trigger, triggering the build synchronously", zap.Error(err))
 		}
-	})
 
-	respondWithResults(w, &hookProvider, respondWith)
+		// send and wait
+		triggerResp, isSuccess, err := triggerBuild(ctx, triggerURL, apiToken, aBuildTriggerParam)
+		if err != nil {
+			entry, isQueued := c.queueForRetry(ctx, appSlug, hookID, triggerURL, apiToken, aBuildTriggerParam, err)
+			if !isQueued {
+				respondWith.Errors = append(respondWith.Errors, fmt.Sprintf("Failed to Trigger Build: %s", err))
+				continue
+			}
+			respondWith.QueuedTriggerResponses = append(respondWith.QueuedTriggerResponses, hookCommon.QueuedAPIResponseModel{
+				Message:       "Build trigger failed with a temporary error, it will be retried.",
+				QueueID:       entry.ID,
+				CommitHash:    aBuildTriggerParam.BuildParams.CommitHash,
+				CommitMessage: aBuildTriggerParam.BuildParams.CommitMessage,
+				Branch:        aBuildTriggerParam.BuildParams.Branch,
+			})
+		} else if isSuccess {
+			respondWith.SuccessTriggerResponses = append(respondWith.SuccessTriggerResponses, triggerResp)
+		} else {
+			respondWith.FailedTriggerResponses = append(respondWith.FailedTriggerResponses, triggerResp)
+		}
+	}
+
+	return respondWith
 }
@@ -0,0 +1,641 @@
+package hook
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/stretchr/testify/require"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	"github.com/bitrise-io/bitrise-webhooks/config"
+	"github.com/bitrise-io/bitrise-webhooks/internal/credentials"
+	"github.com/bitrise-io/bitrise-webhooks/internal/dedup"
+	"github.com/bitrise-io/bitrise-webhooks/internal/executor"
+	"github.com/bitrise-io/bitrise-webhooks/internal/projects"
+	"github.com/bitrise-io/bitrise-webhooks/internal/rules"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+const gitlabPushPayload = `{"object_kind": "push", "ref": "refs/heads/develop", "checkout_sha": "1606d3dd4c4dc83ee8fed8d3cfd911da851bf740", "user_username": "test_user", "commits": [{"id": "1606d3dd4c4dc83ee8fed8d3cfd911da851bf740", "message": "second commit message"}]}`
+
+const githubPushPayload = `{"ref": "refs/heads/master", "deleted": false, "head_commit": {"distinct": true, "id": "83b86e5f286f546dc5a4a58db66ceef44460c85e", "message": "re-structuring"}, "repository": {"clone_url": "https://github.com/bitrise-team/bitrise-webhooks.git"}}`
+
+const visualStudioPushPayload = `{"subscriptionId": "f0c23515-bcd1-4e30-9613-56a0a129c732", "eventType": "git.push", "publisherId": "tfs", "resourceVersion": "1.0", "resource": {"commits": [{"commitId": "33b55f7cb7e7e245323987634f960cf4a6e6bc74", "comment": "Fixed bug"}], "refUpdates": [{"name": "refs/heads/master", "oldObjectId": "aad331d8d3b131fa9ae03cf5e53965b51942618a", "newObjectId": "33b55f7cb7e7e245323987634f960cf4a6e6bc74"}]}}`
+
+const triggeredBuildResponse = `{"status":"ok","message":"triggered build","slug":"app-slug"}`
+
+// triggerCall is a Build Trigger API call received by the trigger server of the tests.
+type triggerCall struct {
+	apiToken string
+	params   bitriseapi.TriggerAPIParamsModel
+}
+
+// newTriggerServer starts a Build Trigger API stub which responds with a triggered build to every call,
+// and sends the Build Trigger API calls to it until the test ends.
+// The received calls are sent to the returned channel.
+func newTriggerServer(t *testing.T) <-chan triggerCall {
+	return newTriggerServerWithResponses(t, nil)
+}
+
+// newTriggerServerWithResponses is newTriggerServer, responding with the given response bodies
+// to the calls of the given API tokens.
+func newTriggerServerWithResponses(t *testing.T, responses map[string]string) <-chan triggerCall {
+	triggered := make(chan triggerCall, 100)
+	triggerServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		call := triggerCall{apiToken: r.Header.Get("Api-Token")}
+		require.NoError(t, json.Unmarshal(body, &call.params))
+		triggered <- call
+
+		response, ok := responses[call.apiToken]
+		if !ok {
+			response = triggeredBuildResponse
+		}
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(triggerServer.Close)
+
+	triggerURL, err := url.Parse(triggerServer.URL)
+	require.NoError(t, err)
+	originalSendRequestToURL := config.SendRequestToURL
+	config.SendRequestToURL = triggerURL
+	t.Cleanup(func() {
+		config.SendRequestToURL = originalSendRequestToURL
+	})
+
+	return triggered
+}
+
+// receivedAPITokens returns the API tokens of the calls received so far, in order.
+func receivedAPITokens(triggered <-chan triggerCall) []string {
+	var apiTokens []string
+	for len(triggered) > 0 {
+		apiTokens = append(apiTokens, (<-triggered).apiToken)
+	}
+	return apiTokens
+}
+
+func TestClient_HTTPHandler_DeliveryDeduplication(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{DeliveryStore: dedup.NewMemoryStore(time.Hour)}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(deliveryID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/h/github/app-slug/api-token", strings.NewReader(githubPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "push")
+		if deliveryID != "" {
+			req.Header.Set("X-GitHub-Delivery", deliveryID)
+		}
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("First delivery - triggers a build")
+	first := send("delivery-1")
+	require.Equal(t, http.StatusCreated, first.Code)
+	require.Len(t, triggered, 1)
+
+	t.Log("Redelivery - responds with the original result, without triggering a build")
+	{
+		redelivery := send("delivery-1")
+		require.Equal(t, http.StatusCreated, redelivery.Code)
+		require.Equal(t, first.Body.String(), redelivery.Body.String())
+		require.Equal(t, "true", redelivery.Header().Get("X-Bitrise-Duplicate-Delivery"))
+		require.Len(t, triggered, 1)
+	}
+
+	t.Log("An other delivery - triggers a build")
+	{
+		require.Equal(t, http.StatusCreated, send("delivery-2").Code)
+		require.Len(t, triggered, 2)
+	}
+
+	t.Log("No delivery ID - not deduplicated")
+	{
+		require.Equal(t, http.StatusCreated, send("").Code)
+		require.Equal(t, http.StatusCreated, send("").Code)
+		require.Len(t, triggered, 4)
+	}
+}
+
+func TestClient_HTTPHandler_BackgroundTrigger(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{TriggerExecutor: executor.New(1, 1, time.Minute)}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	req := httptest.NewRequest(http.MethodPost, "/h/gitlab/app-slug/api-token", strings.NewReader(gitlabPushPayload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Gitlab-Event", "Push Hook")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.JSONEq(t, `{"did_not_wait_for_trigger_response":true,"success_responses":[]}`, rec.Body.String())
+
+	select {
+	case call := <-triggered:
+		require.Equal(t, "api-token", call.apiToken)
+	case <-time.After(5 * time.Second):
+		t.Fatal("the build was not triggered in the background")
+	}
+}
+
+func TestClient_HTTPHandler_RoutingRules(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{RoutingRules: map[string]rules.Rules{
+		"skipping-app": {
+			{If: rules.Conditions{Providers: []string{"github"}, Branches: []string{"master"}}, Action: rules.ActionSkip, Reason: "master is built by the nightly schedule"},
+		},
+		"workflow-app": {
+			{If: rules.Conditions{Events: []string{"pull_request"}}, Action: rules.ActionSkip},
+			{If: rules.Conditions{Branches: []string{"ma*"}}, Action: rules.ActionSetWorkflow, WorkflowID: "deploy"},
+		},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(appSlug string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/h/github/"+appSlug+"/api-token", strings.NewReader(githubPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "push")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Skip rule - the build is not triggered")
+	{
+		rec := send("skipping-app")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.JSONEq(t, `{"success_responses":[],"skipped_responses":[{"message":"Build skipped by a routing rule: master is built by the nightly schedule","commit_hash":"83b86e5f286f546dc5a4a58db66ceef44460c85e","commit_message":"re-structuring","branch":"master"}]}`, rec.Body.String())
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("Set workflow rule - the build is triggered with the workflow")
+	{
+		rec := send("workflow-app")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "deploy", params.BuildParams.WorkflowID)
+	}
+
+	t.Log("No rules for the app - the build is triggered as-it-is")
+	{
+		rec := send("other-app")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "", params.BuildParams.WorkflowID)
+	}
+}
+
+func TestClient_HTTPHandler_ProjectMaps(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{ProjectMaps: map[string]projects.Map{
+		"monorepo-app": {
+			Projects:         map[string][]string{"ios": {"ios/**", "shared/**"}, "android": {"android/**", "shared/**"}},
+			SkipIfUnaffected: true,
+		},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(appSlug string, modified string) *httptest.ResponseRecorder {
+		payload := `{"ref": "refs/heads/master", "deleted": false, "head_commit": {"distinct": true, "id": "83b86e5f286f546dc5a4a58db66ceef44460c85e", "message": "re-structuring", "modified": ["` + modified + `"]}}`
+		req := httptest.NewRequest(http.MethodPost, "/h/github/"+appSlug+"/api-token", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "push")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Affected projects are set")
+	{
+		rec := send("monorepo-app", "shared/strings.json")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, []bitriseapi.EnvironmentItem{
+			{Name: "BITRISE_AFFECTED_PROJECTS", Value: "android,ios", IsExpand: false},
+		}, params.BuildParams.Environments)
+	}
+
+	t.Log("No affected project - the build is skipped")
+	{
+		rec := send("monorepo-app", "scripts/release.sh")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Build skipped because none of the changed files belongs to a project of the app.")
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("No project map for the app")
+	{
+		rec := send("other-app", "ios/App.swift")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Empty(t, params.BuildParams.Environments)
+	}
+}
+
+func TestClient_HTTPHandler_ChatOps(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{ChatOps: map[string]*hookCommon.ChatOpsConfig{
+		"app-slug": {Prefix: "/bitrise", AllowedAuthorAssociations: []string{"OWNER", "MEMBER"}},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(appSlug, comment, authorAssociation string) *httptest.ResponseRecorder {
+		payload := `{"action": "created", "issue": {"number": 4, "title": "new PR", "state": "open", "pull_request": {}, "user": {"login": "test_user"}}, "comment": {"id": 1, "body": "` + comment + `", "author_association": "` + authorAssociation + `"}, "sender": {"login": "test_user"}}`
+		req := httptest.NewRequest(http.MethodPost, "/h/github/"+appSlug+"/api-token", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "issue_comment")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Run command - the build is triggered with the workflow and the env vars")
+	{
+		rec := send("app-slug", "/bitrise run deploy VERSION=1.2.0", "MEMBER")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "deploy", params.BuildParams.WorkflowID)
+		require.Equal(t, []bitriseapi.EnvironmentItem{{Name: "VERSION", Value: "1.2.0", IsExpand: false}}, params.BuildParams.Environments)
+	}
+
+	t.Log("Not a command - the build is skipped")
+	{
+		rec := send("app-slug", "LGTM", "MEMBER")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Build skipped because the PR comment is not a /bitrise command.")
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("Author is not allowed to run commands - the build is skipped")
+	{
+		rec := send("app-slug", "/bitrise retry", "CONTRIBUTOR")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "is not allowed to run /bitrise commands.")
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("App without ChatOps - every comment triggers a build")
+	{
+		rec := send("other-app-slug", "LGTM", "CONTRIBUTOR")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "LGTM", params.BuildParams.PullRequestComment)
+		require.Equal(t, "", params.BuildParams.WorkflowID)
+	}
+}
+
+func TestClient_HTTPHandler_ChatOpsAndRoutingRules(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{
+		ChatOps: map[string]*hookCommon.ChatOpsConfig{"app-slug": {}},
+		RoutingRules: map[string]rules.Rules{
+			"app-slug": {
+				{If: rules.Conditions{Authors: []string{"renovate-bot"}}, Action: rules.ActionSkip, Reason: "dependency updates are built nightly"},
+				{If: rules.Conditions{Authors: []string{"release-manager"}}, Action: rules.ActionSetWorkflow, WorkflowID: "release"},
+			},
+		},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(pullRequestAuthor, comment string) *httptest.ResponseRecorder {
+		payload := `{"action": "created", "issue": {"number": 4, "title": "new PR", "state": "open", "pull_request": {}, "user": {"login": "` + pullRequestAuthor + `"}}, "comment": {"id": 1, "body": "` + comment + `", "author_association": "OWNER"}, "sender": {"login": "test_user"}}`
+		req := httptest.NewRequest(http.MethodPost, "/h/github/app-slug/api-token", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "issue_comment")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Not a command - skipped by ChatOps, before the rules are evaluated")
+	{
+		rec := send("renovate-bot", "LGTM")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Build skipped because the PR comment is not a /bitrise command.")
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("Command - skipped by the rule")
+	{
+		rec := send("renovate-bot", "/bitrise retry")
+		require.Equal(t, http.StatusOK, rec.Code)
+		require.Contains(t, rec.Body.String(), "Build skipped by a routing rule: dependency updates are built nightly")
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("Run command - the rule has the final word on the workflow")
+	{
+		rec := send("release-manager", "/bitrise run deploy VERSION=1.2.0")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "release", params.BuildParams.WorkflowID)
+		require.Equal(t, []bitriseapi.EnvironmentItem{{Name: "VERSION", Value: "1.2.0", IsExpand: false}}, params.BuildParams.Environments)
+	}
+
+	t.Log("Run command - without a matching rule")
+	{
+		rec := send("test_user", "/bitrise run deploy")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "deploy", params.BuildParams.WorkflowID)
+	}
+}
+
+func TestClient_HTTPHandler_LabelWorkflows(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{LabelWorkflows: map[string]hookCommon.LabelWorkflows{
+		"app-slug": {"run-ui-tests": "ui_test_workflow"},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(label string) *httptest.ResponseRecorder {
+		payload := `{"action": "labeled", "number": 4, "label": {"name": "` + label + `"}, "pull_request": {"title": "new PR", "mergeable": true, "head": {"ref": "feature", "sha": "83b86e5f286f546dc5a4a58db66ceef44460c85e"}, "base": {"ref": "master"}, "labels": [{"name": "` + label + `"}]}}`
+		req := httptest.NewRequest(http.MethodPost, "/h/github/app-slug/api-token", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "pull_request")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Mapped label - the build is triggered with the label's workflow")
+	{
+		rec := send("run-ui-tests")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "ui_test_workflow", params.BuildParams.WorkflowID)
+		require.Len(t, triggered, 0)
+	}
+
+	t.Log("Not mapped label - the build is triggered as-it-is")
+	{
+		rec := send("wip")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		params := (<-triggered).params
+		require.Equal(t, "", params.BuildParams.WorkflowID)
+	}
+}
+
+type credentialStoreStub map[string]credentials.Credentials
+
+func (s credentialStoreStub) Get(hookID string) (credentials.Credentials, bool, error) {
+	hookCredentials, ok := s[hookID]
+	return hookCredentials, ok, nil
+}
+
+type failingCredentialStore struct{}
+
+func (failingCredentialStore) Get(hookID string) (credentials.Credentials, bool, error) {
+	return credentials.Credentials{}, false, errors.New("credentials file is not readable")
+}
+
+func TestClient_HTTPHandler_HookID(t *testing.T) {
+	triggered := newTriggerServer(t)
+	originalWebhookSecrets := config.WebhookSecrets
+	config.WebhookSecrets = map[string]string{"app-slug": "app-secret"}
+	defer func() {
+		config.WebhookSecrets = originalWebhookSecrets
+	}()
+
+	send := func(store credentials.Store, hookID, secret string) *httptest.ResponseRecorder {
+		router := mux.NewRouter()
+		router.HandleFunc("/h/{service-id}/{hook-id}", (&Client{CredentialStore: store}).HTTPHandler)
+
+		mac := hmac.New(sha256.New, []byte(secret))
+		mac.Write([]byte(githubPushPayload))
+		req := httptest.NewRequest(http.MethodPost, "/h/github/"+hookID, strings.NewReader(githubPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+	store := credentialStoreStub{
+		"my-hook":      {AppSlug: "other-app-slug", APIToken: "hook-api-token"},
+		"secured-hook": {AppSlug: "app-slug", APIToken: "secured-api-token", Secret: "hook-secret"},
+	}
+
+	t.Log("Hook ID resolves to an app")
+	{
+		rec := send(store, "my-hook", "")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, []string{"hook-api-token"}, receivedAPITokens(triggered))
+	}
+
+	t.Log("Unknown hook ID")
+	{
+		rec := send(store, "unknown-hook", "")
+		require.Equal(t, http.StatusNotFound, rec.Code)
+		require.Contains(t, rec.Body.String(), "Unknown hook ID: unknown-hook")
+	}
+
+	t.Log("Credential store error")
+	{
+		rec := send(failingCredentialStore{}, "my-hook", "")
+		require.Equal(t, http.StatusInternalServerError, rec.Code)
+		require.Contains(t, rec.Body.String(), "Failed to resolve hook ID")
+	}
+
+	t.Log("The secret of the hook takes precedence over the secret of the app")
+	{
+		rec := send(store, "secured-hook", "app-secret")
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Len(t, triggered, 0)
+
+		rec = send(store, "secured-hook", "hook-secret")
+		require.Equal(t, http.StatusCreated, rec.Code)
+		require.Equal(t, []string{"secured-api-token"}, receivedAPITokens(triggered))
+	}
+}
+
+func TestClient_HTTPHandler_FanOut(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{CredentialStore: credentialStoreStub{
+		"monorepo": {Targets: []credentials.Target{
+			{AppSlug: "ios-slug", APIToken: "ios-token", Paths: []string{"ios/**"}},
+			{AppSlug: "android-slug", APIToken: "android-token", Paths: []string{"android/**"}},
+			{AppSlug: "backend-slug", APIToken: "backend-token"},
+		}},
+	}}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{hook-id}", client.HTTPHandler)
+
+	payload := `{"ref": "refs/heads/master", "deleted": false, "head_commit": {"distinct": true, "id": "83b86e5f286f546dc5a4a58db66ceef44460c85e", "message": "Update the iOS app", "modified": ["ios/App.swift"]}}`
+	req := httptest.NewRequest(http.MethodPost, "/h/github/monorepo", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Github-Event", "push")
+	rec := httptest.NewRecorder()
+	router.ServeHTTP(rec, req)
+
+	require.Equal(t, http.StatusCreated, rec.Code)
+	require.Equal(t, []string{"ios-token", "backend-token"}, receivedAPITokens(triggered))
+
+	var response hookCommon.DefaultTransformResponseModel
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	require.Len(t, response.SuccessTriggerResponses, 2)
+	require.Len(t, response.SkippedTriggerResponses, 1)
+	require.Len(t, response.AppResponses, 3)
+	require.Len(t, response.AppResponses["ios-slug"].SuccessTriggerResponses, 1)
+	require.Equal(t, "Build skipped because none of the changed files matches the paths of the app.", response.AppResponses["android-slug"].SkippedTriggerResponses[0].Message)
+	require.Len(t, response.AppResponses["backend-slug"].SuccessTriggerResponses, 1)
+}
+
+func TestClient_HTTPHandler_FanOutDeliveryDeduplication(t *testing.T) {
+	triggered := newTriggerServer(t)
+
+	client := Client{
+		DeliveryStore: dedup.NewMemoryStore(time.Hour),
+		CredentialStore: credentialStoreStub{
+			"mobile": {Targets: []credentials.Target{{AppSlug: "ios-slug", APIToken: "ios-token"}, {AppSlug: "android-slug", APIToken: "android-token"}}},
+			"web":    {Targets: []credentials.Target{{AppSlug: "web-slug", APIToken: "web-token"}}},
+		},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{hook-id}", client.HTTPHandler)
+
+	send := func(hookID string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/h/gitlab/"+hookID, strings.NewReader(gitlabPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Gitlab-Event", "Push Hook")
+		// GitLab sends the same event UUID to every webhook of the event
+		req.Header.Set("X-Gitlab-Event-UUID", "event-uuid")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("The same delivery ID sent to two fan-out hooks - both trigger their builds")
+	{
+		require.Equal(t, http.StatusCreated, send("mobile").Code)
+		require.Equal(t, http.StatusCreated, send("web").Code)
+		require.Equal(t, []string{"ios-token", "android-token", "web-token"}, receivedAPITokens(triggered))
+	}
+
+	t.Log("Redelivery to a fan-out hook - deduplicated")
+	{
+		rec := send("mobile")
+		require.Equal(t, "true", rec.Header().Get("X-Bitrise-Duplicate-Delivery"))
+		require.Len(t, triggered, 0)
+	}
+}
+
+func TestClient_HTTPHandler_FanOutPartialFailureDeliveryDeduplication(t *testing.T) {
+	// an invalid response is not a retryable error
+	triggered := newTriggerServerWithResponses(t, map[string]string{"android-token": "invalid response"})
+
+	client := Client{
+		DeliveryStore: dedup.NewMemoryStore(time.Hour),
+		CredentialStore: credentialStoreStub{
+			"mobile": {Targets: []credentials.Target{{AppSlug: "ios-slug", APIToken: "ios-token"}, {AppSlug: "android-slug", APIToken: "android-token"}}},
+		},
+	}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{hook-id}", client.HTTPHandler)
+
+	send := func() *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/h/gitlab/mobile", strings.NewReader(gitlabPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Gitlab-Event", "Push Hook")
+		req.Header.Set("X-Gitlab-Event-UUID", "event-uuid")
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("One of the targets fails - server error")
+	first := send()
+	require.Equal(t, http.StatusInternalServerError, first.Code)
+	require.Equal(t, []string{"ios-token", "android-token"}, receivedAPITokens(triggered))
+
+	t.Log("Redelivery - the build of the other target is not triggered again")
+	{
+		rec := send()
+		require.Equal(t, "true", rec.Header().Get("X-Bitrise-Duplicate-Delivery"))
+		require.Equal(t, first.Body.String(), rec.Body.String())
+		require.Len(t, triggered, 0)
+	}
+}
+
+func TestClient_HTTPHandler_WebhookUsername(t *testing.T) {
+	triggered := newTriggerServer(t)
+	originalWebhookSecrets, originalWebhookUsernames := config.WebhookSecrets, config.WebhookUsernames
+	config.WebhookSecrets = map[string]string{"app-slug": "user:s3cr3t", "github/app-slug": "github-secret"}
+	config.WebhookUsernames = map[string]string{"app-slug": "bitrise", "github/app-slug": "bitrise"}
+	defer func() {
+		config.WebhookSecrets, config.WebhookUsernames = originalWebhookSecrets, originalWebhookUsernames
+	}()
+
+	client := Client{}
+	router := mux.NewRouter()
+	router.HandleFunc("/h/{service-id}/{app-slug}/{api-token}", client.HTTPHandler)
+
+	send := func(username, password string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/h/visualstudio/app-slug/api-token", strings.NewReader(visualStudioPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.SetBasicAuth(username, password)
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Log("Matching username and password - the password can start with user:")
+	{
+		require.Equal(t, http.StatusCreated, send("bitrise", "user:s3cr3t").Code)
+		require.Len(t, triggered, 1)
+	}
+
+	t.Log("Other username")
+	{
+		require.Equal(t, http.StatusForbidden, send("other", "user:s3cr3t").Code)
+		require.Len(t, triggered, 1)
+	}
+
+	t.Log("The provider doesn't support username verification")
+	{
+		mac := hmac.New(sha256.New, []byte("github-secret"))
+		mac.Write([]byte(githubPushPayload))
+		req := httptest.NewRequest(http.MethodPost, "/h/github/app-slug/api-token", strings.NewReader(githubPushPayload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("X-Github-Event", "push")
+		req.Header.Set("X-Hub-Signature-256", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, req)
+
+		require.Equal(t, http.StatusForbidden, rec.Code)
+		require.Contains(t, rec.Body.String(), "a webhook username is configured, but the provider does not support username verification")
+		require.Len(t, triggered, 1)
+	}
+}
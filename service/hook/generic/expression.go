@@ -0,0 +1,223 @@
+package generic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// expression is a JSONPath expression or a Go template, evaluated on the decoded payload.
+type expression interface {
+	evaluate(payload interface{}, header http.Header) (string, error)
+}
+
+// compileExpression compiles the expression: if it starts with `$` it's a JSONPath expression,
+// otherwise it's a Go template (which can be a plain string as well).
+// Returns nil for an empty expression.
+func compileExpression(expr string) (expression, error) {
+	if expr == "" {
+		return nil, nil
+	}
+	if expr == "$" || strings.HasPrefix(expr, "$.") || strings.HasPrefix(expr, "$[") {
+		return compileJSONPath(expr)
+	}
+	return compileTemplate(expr)
+}
+
+// evaluate returns an empty string for a nil expression.
+func evaluate(expr expression, payload interface{}, header http.Header) (string, error) {
+	if expr == nil {
+		return "", nil
+	}
+	return expr.evaluate(payload, header)
+}
+
+// formatValue converts a value of the decoded payload into a string:
+// objects and arrays are JSON serialized, a missing value (or null) is an empty string.
+func formatValue(value interface{}) (string, error) {
+	switch value := value.(type) {
+	case nil:
+		return "", nil
+	case string:
+		return value, nil
+	case json.Number:
+		return value.String(), nil
+	case bool:
+		return strconv.FormatBool(value), nil
+	}
+
+	b, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// ---------------------------------------
+// --- JSONPath ---
+
+// jsonPath supports a subset of JSONPath: the root (`$`), child keys (`.key`, `['key']` or `["key"]`)
+// and array indexes (`[0]`).
+type jsonPath struct {
+	// steps are either string (object key) or int (array index) steps
+	steps []interface{}
+}
+
+func compileJSONPath(expr string) (jsonPath, error) {
+	var steps []interface{}
+	rest := strings.TrimPrefix(expr, "$")
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end == -1 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return jsonPath{}, fmt.Errorf("invalid JSONPath (%s): empty key", expr)
+			}
+			steps = append(steps, rest[:end])
+			rest = rest[end:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end == -1 {
+				return jsonPath{}, fmt.Errorf("invalid JSONPath (%s): missing ]", expr)
+			}
+			selector := rest[1:end]
+			rest = rest[end+1:]
+
+			if len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0] {
+				steps = append(steps, selector[1:len(selector)-1])
+				continue
+			}
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return jsonPath{}, fmt.Errorf("invalid JSONPath (%s): unsupported selector: [%s]", expr, selector)
+			}
+			steps = append(steps, index)
+		default:
+			return jsonPath{}, fmt.Errorf("invalid JSONPath (%s)", expr)
+		}
+	}
+	return jsonPath{steps: steps}, nil
+}
+
+func (p jsonPath) evaluate(payload interface{}, header http.Header) (string, error) {
+	value := payload
+	for _, step := range p.steps {
+		switch step := step.(type) {
+		case string:
+			object, ok := value.(map[string]interface{})
+			if !ok {
+				return "", nil
+			}
+			value = object[step]
+		case int:
+			array, ok := value.([]interface{})
+			if !ok || step >= len(array) {
+				return "", nil
+			}
+			value = array[step]
+		}
+	}
+	return formatValue(value)
+}
+
+// ---------------------------------------
+// --- Go template ---
+
+// goTemplate is executed with the decoded payload as its data.
+type goTemplate struct {
+	tmpl *template.Template
+}
+
+var templateFuncs = template.FuncMap{
+	"hasPrefix":  strings.HasPrefix,
+	"hasSuffix":  strings.HasSuffix,
+	"trimPrefix": func(prefix, s string) string { return strings.TrimPrefix(s, prefix) },
+	"trimSuffix": func(suffix, s string) string { return strings.TrimSuffix(s, suffix) },
+	"contains":   func(substr, s string) bool { return strings.Contains(s, substr) },
+	"lower":      strings.ToLower,
+	"upper":      strings.ToUpper,
+	"toJSON":     formatValue,
+	// header is replaced with the request's headers, when the template is executed
+	"header": func(key string) string { return "" },
+	// printValueFunc is appended to the pipeline of every action which prints a value
+	printValueFunc: printValue,
+}
+
+const printValueFunc = "printValue"
+
+// printValue prints the value of an action as the template would,
+// except that a missing value (or null) is printed as an empty string, instead of "<no value>".
+func printValue(value interface{}) string {
+	if value == nil {
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func compileTemplate(expr string) (goTemplate, error) {
+	tmpl, err := template.New("").Funcs(templateFuncs).Parse(expr)
+	if err != nil {
+		return goTemplate{}, fmt.Errorf("invalid template (%s): %s", expr, err)
+	}
+	for _, aTemplate := range tmpl.Templates() {
+		if aTemplate.Tree != nil {
+			appendPrintValue(aTemplate.Tree.Root)
+		}
+	}
+	return goTemplate{tmpl: tmpl}, nil
+}
+
+// appendPrintValue rewrites every action which prints a value, e.g. {{ .issue.key }},
+// to print it with printValue: {{ .issue.key | printValue }}.
+func appendPrintValue(node parse.Node) {
+	switch node := node.(type) {
+	case *parse.ListNode:
+		if node == nil {
+			return
+		}
+		for _, child := range node.Nodes {
+			appendPrintValue(child)
+		}
+	case *parse.ActionNode:
+		// the variable declarations don't print anything
+		if len(node.Pipe.Decl) > 0 {
+			return
+		}
+		node.Pipe.Cmds = append(node.Pipe.Cmds, &parse.CommandNode{
+			NodeType: parse.NodeCommand,
+			Pos:      node.Pos,
+			Args:     []parse.Node{parse.NewIdentifier(printValueFunc).SetPos(node.Pos)},
+		})
+	case *parse.IfNode:
+		appendPrintValue(node.List)
+		appendPrintValue(node.ElseList)
+	case *parse.RangeNode:
+		appendPrintValue(node.List)
+		appendPrintValue(node.ElseList)
+	case *parse.WithNode:
+		appendPrintValue(node.List)
+		appendPrintValue(node.ElseList)
+	}
+}
+
+func (t goTemplate) evaluate(payload interface{}, header http.Header) (string, error) {
+	tmpl, err := t.tmpl.Clone()
+	if err != nil {
+		return "", err
+	}
+	tmpl = tmpl.Funcs(template.FuncMap{"header": header.Get})
+
+	var builder strings.Builder
+	if err := tmpl.Execute(&builder, payload); err != nil {
+		return "", err
+	}
+	return builder.String(), nil
+}
@@ -0,0 +1,83 @@
+package generic
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+const samplePayload = `{
+  "webhookEvent": "jira:issue_updated",
+  "issue": {
+    "id": 10002,
+    "key": "MOB-42",
+    "fields": {"summary": "Crash on startup", "description": "<no value>", "labels": ["ios", "release"], "flagged": true, "assignee": null}
+  }
+}`
+
+func decodePayload(t *testing.T, payload string) interface{} {
+	var decoded interface{}
+	decoder := json.NewDecoder(strings.NewReader(payload))
+	decoder.UseNumber()
+	require.NoError(t, decoder.Decode(&decoded))
+	return decoded
+}
+
+func Test_compileExpression(t *testing.T) {
+	payload := decodePayload(t, samplePayload)
+	header := http.Header{"X-Atlassian-Webhook-Identifier": {"a1b2c3"}}
+
+	for expr, want := range map[string]string{
+		`$.issue.key`:              "MOB-42",
+		`$['issue']["key"]`:        "MOB-42",
+		`$.issue.id`:               "10002",
+		`$.issue.fields.labels[1]`: "release",
+		`$.issue.fields.labels`:    `["ios","release"]`,
+		`$.issue.fields.flagged`:   "true",
+		`$.issue.fields.assignee`:  "",
+		`$.issue.missing.key`:      "",
+		`$.issue.fields.labels[5]`: "",
+		`master`:                   "master",
+		`{{ .issue.key }}: {{ .issue.fields.summary }}`:                       "MOB-42: Crash on startup",
+		`{{ .issue.missing }}`:                                                "",
+		`{{ .issue.fields.assignee }}`:                                        "",
+		`{{ .issue.fields.description }}`:                                     "<no value>",
+		`{{ with .issue.fields }}{{ .missing }}-{{ .summary }}{{ end }}`:      "-Crash on startup",
+		`{{ $key := .issue.key }}{{ $key }}`:                                  "MOB-42",
+		`{{ .issue.id }}`:                                                     "10002",
+		`{{ eq .webhookEvent "jira:issue_updated" }}`:                         "true",
+		`{{ .webhookEvent | trimPrefix "jira:" }}`:                            "issue_updated",
+		`{{ if contains "crash" (lower .issue.fields.summary) }}yes{{ end }}`: "yes",
+		`{{ header "X-Atlassian-Webhook-Identifier" }}`:                       "a1b2c3",
+		`{{ toJSON .issue.fields.labels }}`:                                   `["ios","release"]`,
+	} {
+		compiled, err := compileExpression(expr)
+		require.NoError(t, err, expr)
+
+		got, err := evaluate(compiled, payload, header)
+		require.NoError(t, err, expr)
+		require.Equal(t, want, got, expr)
+	}
+
+	t.Log("Empty expression")
+	{
+		compiled, err := compileExpression("")
+		require.NoError(t, err)
+		require.Nil(t, compiled)
+
+		got, err := evaluate(compiled, payload, header)
+		require.NoError(t, err)
+		require.Equal(t, "", got)
+	}
+
+	t.Log("Invalid expressions")
+	{
+		for _, expr := range []string{`$.`, `$.issue[`, `$.issue[*]`, `{{ .issue.key `} {
+			_, err := compileExpression(expr)
+			require.Error(t, err, expr)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package generic
+
+// # Infos / notes:
+//
+// The generic provider can be used for any service which sends JSON webhooks (e.g. Jira or Sentry),
+// the build parameters are mapped from the payload by a mapping definition, configured per route.
+//
+// Every field of the mapping is either a JSONPath expression (if it starts with `$`, e.g. `$.issue.key`)
+// or a Go template, executed with the payload as its data (e.g. `{{ .issue.key }}: {{ .issue.fields.summary }}`).
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+const (
+	// ProviderID ...
+	ProviderID = "generic"
+)
+
+// MappingModel defines how the webhook's payload is mapped to the build parameters.
+type MappingModel struct {
+	Branch        string            `json:"branch"`
+	Tag           string            `json:"tag"`
+	WorkflowID    string            `json:"workflow_id"`
+	CommitHash    string            `json:"commit_hash"`
+	CommitMessage string            `json:"commit_message"`
+	Environments  map[string]string `json:"environments"`
+	// SkipIf is evaluated first, if its value is `true` no build is triggered
+	SkipIf string `json:"skip_if"`
+}
+
+// Mapping is the compiled MappingModel.
+type Mapping struct {
+	branch        expression
+	tag           expression
+	workflowID    expression
+	commitHash    expression
+	commitMessage expression
+	envNames      []string
+	environments  map[string]expression
+	skipIf        expression
+}
+
+// NewMapping compiles the expressions of the mapping.
+func NewMapping(model MappingModel) (*Mapping, error) {
+	mapping := &Mapping{environments: map[string]expression{}}
+	for _, field := range []struct {
+		name   string
+		expr   string
+		target *expression
+	}{
+		{name: "branch", expr: model.Branch, target: &mapping.branch},
+		{name: "tag", expr: model.Tag, target: &mapping.tag},
+		{name: "workflow_id", expr: model.WorkflowID, target: &mapping.workflowID},
+		{name: "commit_hash", expr: model.CommitHash, target: &mapping.commitHash},
+		{name: "commit_message", expr: model.CommitMessage, target: &mapping.commitMessage},
+		{name: "skip_if", expr: model.SkipIf, target: &mapping.skipIf},
+	} {
+		expr, err := compileExpression(field.expr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid %s", field.name)
+		}
+		*field.target = expr
+	}
+
+	if mapping.branch == nil && mapping.tag == nil && mapping.workflowID == nil {
+		return nil, errors.New("at least one of branch, tag and workflow_id has to be mapped")
+	}
+
+	for name, exprStr := range model.Environments {
+		if name == "" {
+			return nil, errors.New("invalid environments: empty env var name")
+		}
+		expr, err := compileExpression(exprStr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid environments (%s)", name)
+		}
+		mapping.envNames = append(mapping.envNames, name)
+		mapping.environments[name] = expr
+	}
+	sort.Strings(mapping.envNames)
+
+	return mapping, nil
+}
+
+// ReadMappingsFile reads the mappings from a JSON file, which maps hook IDs or app slugs to mappings, e.g.:
+//
+//	{"my-app-slug": {"branch": "$.ref", "workflow_id": "deploy", "environments": {"ISSUE_KEY": "$.issue.key"}}}
+func ReadMappingsFile(pth string) (map[string]*Mapping, error) {
+	content, err := os.ReadFile(pth)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read mappings file (%s)", pth)
+	}
+
+	var models map[string]MappingModel
+	if err := json.Unmarshal(content, &models); err != nil {
+		return nil, errors.Wrapf(err, "failed to parse mappings file (%s)", pth)
+	}
+
+	mappings := map[string]*Mapping{}
+	for route, model := range models {
+		mapping, err := NewMapping(model)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid mapping for route (%s)", route)
+		}
+		mappings[route] = mapping
+	}
+	return mappings, nil
+}
+
+// ---------------------------------------
+// --- Webhook Provider Implementation ---
+
+// HookProvider ...
+type HookProvider struct {
+	mappings map[string]*Mapping
+	// mapping is the mapping of the route, set by ForRoute
+	mapping *Mapping
+}
+
+// NewHookProvider ...
+func NewHookProvider(mappings map[string]*Mapping) hookCommon.Provider {
+	return HookProvider{
+		mappings: mappings,
+	}
+}
+
+// ForRoute ...
+// The mapping defined for the hook ID takes precedence over the one defined for the app.
+func (hp HookProvider) ForRoute(hookID, appSlug string) hookCommon.Provider {
+	mapping, ok := hp.mappings[hookID]
+	if !ok || hookID == "" {
+		mapping = hp.mappings[appSlug]
+	}
+	hp.mapping = mapping
+	return hp
+}
+
+func (mapping *Mapping) transform(payload interface{}, header http.Header) hookCommon.TransformResultModel {
+	skip, err := evaluate(mapping.skipIf, payload, header)
+	if err != nil {
+		return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to evaluate skip_if: %s", err)}
+	}
+	if strings.TrimSpace(skip) == "true" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("skip_if condition of the mapping is met"),
+			ShouldSkip: true,
+		}
+	}
+
+	var buildParams bitriseapi.BuildParamsModel
+	for _, field := range []struct {
+		name   string
+		expr   expression
+		target *string
+	}{
+		{name: "branch", expr: mapping.branch, target: &buildParams.Branch},
+		{name: "tag", expr: mapping.tag, target: &buildParams.Tag},
+		{name: "workflow_id", expr: mapping.workflowID, target: &buildParams.WorkflowID},
+		{name: "commit_hash", expr: mapping.commitHash, target: &buildParams.CommitHash},
+		{name: "commit_message", expr: mapping.commitMessage, target: &buildParams.CommitMessage},
+	} {
+		value, err := evaluate(field.expr, payload, header)
+		if err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to evaluate %s: %s", field.name, err)}
+		}
+		*field.target = value
+	}
+
+	for _, name := range mapping.envNames {
+		value, err := evaluate(mapping.environments[name], payload, header)
+		if err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to evaluate environments (%s): %s", name, err)}
+		}
+		buildParams.Environments = append(buildParams.Environments, bitriseapi.EnvironmentItem{Name: name, Value: value, IsExpand: false})
+	}
+
+	if buildParams.Branch == "" && buildParams.Tag == "" && buildParams.WorkflowID == "" {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("The mapping resulted in an empty branch, tag and workflow_id, can't start a build"),
+		}
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: buildParams,
+			},
+		},
+	}
+}
+
+// TransformRequest ...
+func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
+	if hp.mapping == nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("No mapping configured for this route"),
+		}
+	}
+
+	if r.Body == nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Failed to read content of request body: no or empty request body"),
+		}
+	}
+
+	var payload interface{}
+	decoder := json.NewDecoder(r.Body)
+	// keep the numbers (e.g. IDs) as they were sent
+	decoder.UseNumber()
+	if err := decoder.Decode(&payload); err != nil {
+		return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse request body as JSON: %s", err)}
+	}
+
+	return hp.mapping.transform(payload, r.Header)
+}
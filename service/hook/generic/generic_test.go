@@ -0,0 +1,164 @@
+package generic
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	"github.com/stretchr/testify/require"
+)
+
+func testMapping(t *testing.T, model MappingModel) *Mapping {
+	mapping, err := NewMapping(model)
+	require.NoError(t, err)
+	return mapping
+}
+
+func Test_HookProvider_TransformRequest(t *testing.T) {
+	mappings := map[string]*Mapping{
+		"app-slug": testMapping(t, MappingModel{
+			Branch:        "master",
+			WorkflowID:    `{{ if eq .webhookEvent "jira:issue_updated" }}triage{{ else }}primary{{ end }}`,
+			CommitMessage: "{{ .issue.key }}: {{ .issue.fields.summary }}",
+			Environments: map[string]string{
+				"JIRA_ISSUE_KEY":    "$.issue.key",
+				"JIRA_ISSUE_LABELS": "$.issue.fields.labels",
+			},
+			SkipIf: `{{ not .issue.fields.flagged }}`,
+		}),
+		"hook-id": testMapping(t, MappingModel{
+			Tag: "$.issue.key",
+		}),
+	}
+	provider := NewHookProvider(mappings).(HookProvider)
+
+	newRequest := func(payload string) *http.Request {
+		return &http.Request{
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   io.NopCloser(bytes.NewReader([]byte(payload))),
+		}
+	}
+
+	t.Log("Mapping of the app")
+	{
+		hookTransformResult := provider.ForRoute("", "app-slug").TransformRequest(newRequest(samplePayload))
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Branch:        "master",
+					WorkflowID:    "triage",
+					CommitMessage: "MOB-42: Crash on startup",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "JIRA_ISSUE_KEY", Value: "MOB-42", IsExpand: false},
+						{Name: "JIRA_ISSUE_LABELS", Value: `["ios","release"]`, IsExpand: false},
+					},
+				},
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Mapping of the hook ID takes precedence")
+	{
+		hookTransformResult := provider.ForRoute("hook-id", "app-slug").TransformRequest(newRequest(samplePayload))
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag: "MOB-42",
+				},
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Hook ID without mapping - falls back to the mapping of the app")
+	{
+		hookTransformResult := provider.ForRoute("other-hook-id", "app-slug").TransformRequest(newRequest(samplePayload))
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, "triage", hookTransformResult.TriggerAPIParams[0].BuildParams.WorkflowID)
+	}
+
+	t.Log("Skip condition is met")
+	{
+		hookTransformResult := provider.ForRoute("", "app-slug").TransformRequest(newRequest(`{"webhookEvent": "jira:issue_updated", "issue": {"fields": {"flagged": false}}}`))
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "skip_if condition of the mapping is met")
+	}
+
+	t.Log("Empty tag")
+	{
+		hookTransformResult := provider.ForRoute("hook-id", "").TransformRequest(newRequest(`{}`))
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "The mapping resulted in an empty branch, tag and workflow_id, can't start a build")
+	}
+
+	t.Log("No mapping for the route")
+	{
+		hookTransformResult := provider.ForRoute("", "other-app-slug").TransformRequest(newRequest(samplePayload))
+		require.EqualError(t, hookTransformResult.Error, "No mapping configured for this route")
+	}
+
+	t.Log("Invalid body")
+	{
+		hookTransformResult := provider.ForRoute("", "app-slug").TransformRequest(newRequest(`not json`))
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Error(t, hookTransformResult.Error)
+	}
+}
+
+func TestNewMapping(t *testing.T) {
+	t.Log("Nothing to build")
+	{
+		_, err := NewMapping(MappingModel{CommitMessage: "$.message"})
+		require.EqualError(t, err, "at least one of branch, tag and workflow_id has to be mapped")
+	}
+
+	t.Log("Invalid expression")
+	{
+		_, err := NewMapping(MappingModel{Branch: "{{ .ref "})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "invalid branch")
+	}
+
+	t.Log("Invalid env var")
+	{
+		_, err := NewMapping(MappingModel{Branch: "master", Environments: map[string]string{"ISSUE": "$.issue["}})
+		require.EqualError(t, err, "invalid environments (ISSUE): invalid JSONPath ($.issue[): missing ]")
+	}
+}
+
+func TestReadMappingsFile(t *testing.T) {
+	t.Log("Valid mappings file")
+	{
+		pth := filepath.Join(t.TempDir(), "mappings.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{
+  "app-slug": {"branch": "$.ref", "skip_if": "{{ .deleted }}"},
+  "hook-id": {"workflow_id": "deploy", "environments": {"VERSION": "$.version"}}
+}`), 0600))
+
+		mappings, err := ReadMappingsFile(pth)
+		require.NoError(t, err)
+		require.Len(t, mappings, 2)
+		require.Equal(t, []string{"VERSION"}, mappings["hook-id"].envNames)
+	}
+
+	t.Log("Invalid mapping")
+	{
+		pth := filepath.Join(t.TempDir(), "mappings.json")
+		require.NoError(t, os.WriteFile(pth, []byte(`{"app-slug": {"commit_message": "$.message"}}`), 0600))
+
+		_, err := ReadMappingsFile(pth)
+		require.EqualError(t, err, "invalid mapping for route (app-slug): at least one of branch, tag and workflow_id has to be mapped")
+	}
+
+	t.Log("Missing file")
+	{
+		_, err := ReadMappingsFile(filepath.Join(t.TempDir(), "missing.json"))
+		require.Error(t, err)
+	}
+}
@@ -0,0 +1,326 @@
+package gerrit
+
+// # Infos / notes:
+//
+// ## Webhook calls
+//
+// Official docs: https://gerrit.googlesource.com/plugins/webhooks/+/refs/heads/master/src/main/resources/Documentation/config.md
+// and the event formats: https://gerrit-review.googlesource.com/Documentation/cmd-stream-events.html#events
+//
+// The webhooks plugin POSTs the stream events as JSON, without an event header:
+// the type of the event is in the `type` field of the payload.
+//
+// Gerrit has no branches for the changes under review: every patch set of a change
+// is available on a `refs/changes/NN/CHANGE/PATCHSET` ref, where NN is the last two digits of the change number.
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// --------------------------
+// --- Webhook Data Model ---
+
+const (
+	patchsetCreatedEventID = "patchset-created"
+	changeMergedEventID    = "change-merged"
+	commentAddedEventID    = "comment-added"
+	refUpdatedEventID      = "ref-updated"
+
+	emptyCommitHash = "0000000000000000000000000000000000000000"
+
+	// ProviderID ...
+	ProviderID = "gerrit"
+)
+
+// AccountModel ...
+type AccountModel struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Username string `json:"username"`
+}
+
+// ChangeModel ...
+type ChangeModel struct {
+	Project       string       `json:"project"`
+	Branch        string       `json:"branch"`
+	ID            string       `json:"id"`
+	Number        int          `json:"number"`
+	Subject       string       `json:"subject"`
+	CommitMessage string       `json:"commitMessage"`
+	Owner         AccountModel `json:"owner"`
+	URL           string       `json:"url"`
+	Status        string       `json:"status"`
+	WIP           bool         `json:"wip"`
+}
+
+// PatchSetModel ...
+type PatchSetModel struct {
+	Number         int          `json:"number"`
+	Revision       string       `json:"revision"`
+	Ref            string       `json:"ref"`
+	Uploader       AccountModel `json:"uploader"`
+	Author         AccountModel `json:"author"`
+	SizeInsertions int          `json:"sizeInsertions"`
+	SizeDeletions  int          `json:"sizeDeletions"`
+}
+
+// RefUpdateModel ...
+type RefUpdateModel struct {
+	OldRev  string `json:"oldRev"`
+	NewRev  string `json:"newRev"`
+	RefName string `json:"refName"`
+	Project string `json:"project"`
+}
+
+// EventModel ...
+// The fields of every supported event type, as the type is only known after the payload is parsed.
+type EventModel struct {
+	Type           string         `json:"type"`
+	EventCreatedOn int64          `json:"eventCreatedOn"`
+	Change         ChangeModel    `json:"change"`
+	PatchSet       PatchSetModel  `json:"patchSet"`
+	Uploader       AccountModel   `json:"uploader"`
+	Submitter      AccountModel   `json:"submitter"`
+	Author         AccountModel   `json:"author"`
+	Comment        string         `json:"comment"`
+	NewRev         string         `json:"newRev"`
+	RefUpdate      RefUpdateModel `json:"refUpdate"`
+}
+
+// ---------------------------------------
+// --- Webhook Provider Implementation ---
+
+// HookProvider ...
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
+
+func (account AccountModel) name() string {
+	if account.Username != "" {
+		return account.Username
+	}
+	return account.Name
+}
+
+func changeEnvironments(change ChangeModel, patchSet PatchSetModel) []bitriseapi.EnvironmentItem {
+	return []bitriseapi.EnvironmentItem{
+		{Name: "GERRIT_PROJECT", Value: change.Project, IsExpand: false},
+		{Name: "GERRIT_CHANGE_ID", Value: change.ID, IsExpand: false},
+		{Name: "GERRIT_CHANGE_NUMBER", Value: strconv.Itoa(change.Number), IsExpand: false},
+		{Name: "GERRIT_CHANGE_URL", Value: change.URL, IsExpand: false},
+		{Name: "GERRIT_PATCHSET_NUMBER", Value: strconv.Itoa(patchSet.Number), IsExpand: false},
+	}
+}
+
+func changeBuildParams(change ChangeModel, patchSet PatchSetModel) bitriseapi.BuildParamsModel {
+	readyState := bitriseapi.PullRequestReadyStateReadyForReview
+	if change.WIP {
+		readyState = bitriseapi.PullRequestReadyStateDraft
+	}
+
+	commitMessage := change.CommitMessage
+	if commitMessage == "" {
+		commitMessage = change.Subject
+	}
+
+	changeNumber := change.Number
+	return bitriseapi.BuildParamsModel{
+		CommitHash:            patchSet.Revision,
+		CommitMessage:         commitMessage,
+		Branch:                patchSet.Ref,
+		BranchDest:            change.Branch,
+		PullRequestID:         &changeNumber,
+		PullRequestHeadBranch: patchSet.Ref,
+		PullRequestAuthor:     change.Owner.name(),
+		PullRequestReadyState: readyState,
+		Environments:          changeEnvironments(change, patchSet),
+	}
+}
+
+func transformPatchsetCreatedEvent(event EventModel) hookCommon.TransformResultModel {
+	if !strings.HasPrefix(event.PatchSet.Ref, "refs/changes/") {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("patch set ref (%s) is not a change ref", event.PatchSet.Ref),
+		}
+	}
+	if event.PatchSet.Revision == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing patch set revision"),
+		}
+	}
+
+	uploader := event.Uploader
+	if uploader.name() == "" {
+		uploader = event.PatchSet.Uploader
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: changeBuildParams(event.Change, event.PatchSet),
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, uploader.name()),
+			},
+		},
+		// The subject is the first line of the commit message:
+		// if only the rest of it includes the skip instruction it's similar to a PR description.
+		SkippedByPrDescription: !hookCommon.ContainsSkipInstruction(event.Change.Subject) &&
+			hookCommon.ContainsSkipInstruction(event.Change.CommitMessage),
+	}
+}
+
+// commentText strips the "Patch Set N: Code-Review+1" header which Gerrit adds to every review message.
+func commentText(comment string) string {
+	if !strings.HasPrefix(comment, "Patch Set ") {
+		return comment
+	}
+	_, text, _ := strings.Cut(comment, "\n")
+	return strings.TrimSpace(text)
+}
+
+func transformCommentAddedEvent(event EventModel) hookCommon.TransformResultModel {
+	comment := commentText(event.Comment)
+	if comment == "" {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("review has no comment, only votes"),
+			ShouldSkip: true,
+		}
+	}
+	if event.Change.Status != "" && event.Change.Status != "NEW" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("change is not open: %s", event.Change.Status),
+			ShouldSkip: true,
+		}
+	}
+	if event.PatchSet.Revision == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing patch set revision"),
+		}
+	}
+
+	buildParams := changeBuildParams(event.Change, event.PatchSet)
+	buildParams.PullRequestComment = comment
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: buildParams,
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, event.Author.name()),
+			},
+		},
+	}
+}
+
+func transformRefUpdatedEvent(event EventModel) hookCommon.TransformResultModel {
+	refUpdate := event.RefUpdate
+	if refUpdate.NewRev == emptyCommitHash {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("this is a 'Deleted' event, no build can be started"),
+			ShouldSkip: true,
+		}
+	}
+	if refUpdate.NewRev == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing new revision"),
+		}
+	}
+
+	buildParams := bitriseapi.BuildParamsModel{
+		CommitHash: refUpdate.NewRev,
+	}
+	switch {
+	case strings.HasPrefix(refUpdate.RefName, "refs/heads/"):
+		buildParams.Branch = strings.TrimPrefix(refUpdate.RefName, "refs/heads/")
+	case strings.HasPrefix(refUpdate.RefName, "refs/tags/"):
+		buildParams.Tag = strings.TrimPrefix(refUpdate.RefName, "refs/tags/")
+	case !strings.HasPrefix(refUpdate.RefName, "refs/"):
+		// older Gerrit versions send the branch name, without the refs/heads/ prefix
+		buildParams.Branch = refUpdate.RefName
+	default:
+		// e.g. refs/changes/* (handled by patchset-created) or refs/meta/*
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("ref (%s) is not a head nor a tag ref", refUpdate.RefName),
+			ShouldSkip: true,
+		}
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: buildParams,
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, event.Submitter.name()),
+			},
+		},
+	}
+}
+
+// TransformRequest ...
+func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
+	contentType := r.Header.Get("Content-Type")
+	if !strings.HasPrefix(contentType, hookCommon.ContentTypeApplicationJSON) {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Content-Type is not supported: %s", contentType),
+		}
+	}
+
+	if r.Body == nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Failed to read content of request body: no or empty request body"),
+		}
+	}
+
+	event, err := parseEvent(r.Body)
+	if err != nil {
+		return hookCommon.TransformResultModel{Error: err}
+	}
+
+	switch event.Type {
+	case patchsetCreatedEventID:
+		return transformPatchsetCreatedEvent(event)
+	case commentAddedEventID:
+		return transformCommentAddedEvent(event)
+	case refUpdatedEventID:
+		return transformRefUpdatedEvent(event)
+	case changeMergedEventID:
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("change merged, the target branch is built on the ref-updated event"),
+			ShouldSkip: true,
+		}
+	}
+
+	// Unsupported Event
+	return hookCommon.TransformResultModel{
+		Error: fmt.Errorf("Unsupported Webhook event: %s", event.Type),
+	}
+}
+
+func parseEvent(body io.Reader) (EventModel, error) {
+	var event EventModel
+	if err := json.NewDecoder(body).Decode(&event); err != nil {
+		return EventModel{}, fmt.Errorf("Failed to parse request body: %s", err)
+	}
+	if event.Type == "" {
+		return EventModel{}, errors.New("Missing event type")
+	}
+	return event, nil
+}
@@ -0,0 +1,295 @@
+package gerrit
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePatchsetCreatedData = `{
+  "type": "patchset-created",
+  "eventCreatedOn": 1709806530,
+  "change": {
+    "project": "android/app",
+    "branch": "main",
+    "id": "I5d1c9b6b1a8c2d6e0f3a4b5c6d7e8f9a0b1c2d3e",
+    "number": 12345,
+    "subject": "Add login screen",
+    "commitMessage": "Add login screen\n\nChange-Id: I5d1c9b6b1a8c2d6e0f3a4b5c6d7e8f9a0b1c2d3e\n",
+    "owner": {"name": "Change Owner", "email": "owner@example.com", "username": "owner"},
+    "url": "https://gerrit.example.com/c/android/app/+/12345",
+    "status": "NEW"
+  },
+  "patchSet": {
+    "number": 2,
+    "revision": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+    "ref": "refs/changes/45/12345/2",
+    "uploader": {"name": "Uploader", "username": "uploader"},
+    "sizeInsertions": 10,
+    "sizeDeletions": 2
+  },
+  "uploader": {"name": "Uploader", "username": "uploader"}
+}`
+
+const sampleCommentAddedData = `{
+  "type": "comment-added",
+  "eventCreatedOn": 1709806530,
+  "change": {
+    "project": "android/app",
+    "branch": "main",
+    "id": "I5d1c9b6b1a8c2d6e0f3a4b5c6d7e8f9a0b1c2d3e",
+    "number": 12345,
+    "subject": "Add login screen",
+    "owner": {"username": "owner"},
+    "url": "https://gerrit.example.com/c/android/app/+/12345",
+    "status": "NEW"
+  },
+  "patchSet": {
+    "number": 2,
+    "revision": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+    "ref": "refs/changes/45/12345/2"
+  },
+  "author": {"name": "Reviewer", "username": "reviewer"},
+  "comment": "Patch Set 2: Code-Review+1\n\nrecheck"
+}`
+
+const sampleRefUpdatedData = `{
+  "type": "ref-updated",
+  "eventCreatedOn": 1709806530,
+  "submitter": {"name": "Submitter", "username": "submitter"},
+  "refUpdate": {
+    "oldRev": "28e1879d029cb852e4844d9c718537df08844e03",
+    "newRev": "bffeb74224043ba2feb48d137756c8a9331c449a",
+    "refName": "refs/heads/main",
+    "project": "android/app"
+  }
+}`
+
+var intChangeNumber = 12345
+
+func testChangeEnvironments() []bitriseapi.EnvironmentItem {
+	return []bitriseapi.EnvironmentItem{
+		{Name: "GERRIT_PROJECT", Value: "android/app", IsExpand: false},
+		{Name: "GERRIT_CHANGE_ID", Value: "I5d1c9b6b1a8c2d6e0f3a4b5c6d7e8f9a0b1c2d3e", IsExpand: false},
+		{Name: "GERRIT_CHANGE_NUMBER", Value: "12345", IsExpand: false},
+		{Name: "GERRIT_CHANGE_URL", Value: "https://gerrit.example.com/c/android/app/+/12345", IsExpand: false},
+		{Name: "GERRIT_PATCHSET_NUMBER", Value: "2", IsExpand: false},
+	}
+}
+
+func Test_transformPatchsetCreatedEvent(t *testing.T) {
+	t.Log("Patch set created")
+	{
+		event, err := parseEvent(strings.NewReader(samplePatchsetCreatedData))
+		require.NoError(t, err)
+
+		hookTransformResult := transformPatchsetCreatedEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.False(t, hookTransformResult.SkippedByPrDescription)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitHash:            "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+					CommitMessage:         "Add login screen\n\nChange-Id: I5d1c9b6b1a8c2d6e0f3a4b5c6d7e8f9a0b1c2d3e\n",
+					Branch:                "refs/changes/45/12345/2",
+					BranchDest:            "main",
+					PullRequestID:         &intChangeNumber,
+					PullRequestHeadBranch: "refs/changes/45/12345/2",
+					PullRequestAuthor:     "owner",
+					PullRequestReadyState: bitriseapi.PullRequestReadyStateReadyForReview,
+					Environments:          testChangeEnvironments(),
+				},
+				TriggeredBy: "webhook-gerrit/uploader",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Work in progress change")
+	{
+		event, err := parseEvent(strings.NewReader(samplePatchsetCreatedData))
+		require.NoError(t, err)
+		event.Change.WIP = true
+
+		hookTransformResult := transformPatchsetCreatedEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, bitriseapi.PullRequestReadyStateDraft, hookTransformResult.TriggerAPIParams[0].BuildParams.PullRequestReadyState)
+	}
+
+	t.Log("Skip instruction in the commit message body")
+	{
+		event, err := parseEvent(strings.NewReader(samplePatchsetCreatedData))
+		require.NoError(t, err)
+		event.Change.CommitMessage = "Add login screen\n\n[skip ci]\n"
+
+		hookTransformResult := transformPatchsetCreatedEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.True(t, hookTransformResult.SkippedByPrDescription)
+	}
+
+	t.Log("Not a change ref")
+	{
+		hookTransformResult := transformPatchsetCreatedEvent(EventModel{PatchSet: PatchSetModel{Ref: "refs/heads/main", Revision: "83b86e5f286f546dc5a4a58db66ceef44460c85e"}})
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "patch set ref (refs/heads/main) is not a change ref")
+	}
+}
+
+func Test_transformCommentAddedEvent(t *testing.T) {
+	t.Log("Comment added")
+	{
+		event, err := parseEvent(strings.NewReader(sampleCommentAddedData))
+		require.NoError(t, err)
+
+		hookTransformResult := transformCommentAddedEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitHash:            "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+					CommitMessage:         "Add login screen",
+					Branch:                "refs/changes/45/12345/2",
+					BranchDest:            "main",
+					PullRequestID:         &intChangeNumber,
+					PullRequestHeadBranch: "refs/changes/45/12345/2",
+					PullRequestAuthor:     "owner",
+					PullRequestReadyState: bitriseapi.PullRequestReadyStateReadyForReview,
+					Environments:          testChangeEnvironments(),
+					PullRequestComment:    "recheck",
+				},
+				TriggeredBy: "webhook-gerrit/reviewer",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Vote only - should be skipped")
+	{
+		event, err := parseEvent(strings.NewReader(sampleCommentAddedData))
+		require.NoError(t, err)
+		event.Comment = "Patch Set 2: Code-Review+2"
+
+		hookTransformResult := transformCommentAddedEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "review has no comment, only votes")
+	}
+
+	t.Log("Merged change - should be skipped")
+	{
+		event, err := parseEvent(strings.NewReader(sampleCommentAddedData))
+		require.NoError(t, err)
+		event.Change.Status = "MERGED"
+
+		hookTransformResult := transformCommentAddedEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "change is not open: MERGED")
+	}
+}
+
+func Test_transformRefUpdatedEvent(t *testing.T) {
+	t.Log("Branch update")
+	{
+		event, err := parseEvent(strings.NewReader(sampleRefUpdatedData))
+		require.NoError(t, err)
+
+		hookTransformResult := transformRefUpdatedEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitHash: "bffeb74224043ba2feb48d137756c8a9331c449a",
+					Branch:     "main",
+				},
+				TriggeredBy: "webhook-gerrit/submitter",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Branch update - legacy ref name")
+	{
+		hookTransformResult := transformRefUpdatedEvent(EventModel{RefUpdate: RefUpdateModel{RefName: "main", NewRev: "bffeb74224043ba2feb48d137756c8a9331c449a"}})
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, "main", hookTransformResult.TriggerAPIParams[0].BuildParams.Branch)
+	}
+
+	t.Log("Tag")
+	{
+		hookTransformResult := transformRefUpdatedEvent(EventModel{RefUpdate: RefUpdateModel{RefName: "refs/tags/v1.0.0", NewRev: "bffeb74224043ba2feb48d137756c8a9331c449a"}})
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, "v1.0.0", hookTransformResult.TriggerAPIParams[0].BuildParams.Tag)
+	}
+
+	t.Log("Change ref - should be skipped")
+	{
+		hookTransformResult := transformRefUpdatedEvent(EventModel{RefUpdate: RefUpdateModel{RefName: "refs/changes/45/12345/2", NewRev: "bffeb74224043ba2feb48d137756c8a9331c449a"}})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "ref (refs/changes/45/12345/2) is not a head nor a tag ref")
+	}
+
+	t.Log("Branch delete - should be skipped")
+	{
+		hookTransformResult := transformRefUpdatedEvent(EventModel{RefUpdate: RefUpdateModel{RefName: "refs/heads/main", NewRev: "0000000000000000000000000000000000000000"}})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "this is a 'Deleted' event, no build can be started")
+	}
+}
+
+func Test_HookProvider_TransformRequest(t *testing.T) {
+	provider := HookProvider{}
+
+	t.Log("Patch set created")
+	{
+		request := http.Request{
+			Header: http.Header{"Content-Type": {"application/json; charset=utf-8"}},
+			Body:   io.NopCloser(strings.NewReader(samplePatchsetCreatedData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.Len(t, hookTransformResult.TriggerAPIParams, 1)
+		require.Equal(t, "refs/changes/45/12345/2", hookTransformResult.TriggerAPIParams[0].BuildParams.Branch)
+	}
+
+	t.Log("Change merged - should be skipped")
+	{
+		request := http.Request{
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"type": "change-merged"}`)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "change merged, the target branch is built on the ref-updated event")
+	}
+
+	t.Log("Unsupported event")
+	{
+		request := http.Request{
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{"type": "reviewer-added"}`)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Unsupported Webhook event: reviewer-added")
+	}
+
+	t.Log("Missing event type")
+	{
+		request := http.Request{
+			Header: http.Header{"Content-Type": {"application/json"}},
+			Body:   io.NopCloser(strings.NewReader(`{}`)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.EqualError(t, hookTransformResult.Error, "Missing event type")
+	}
+
+	t.Log("Unsupported Content-Type")
+	{
+		request := http.Request{
+			Header: http.Header{"Content-Type": {"not/supported"}},
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.EqualError(t, hookTransformResult.Error, "Content-Type is not supported: not/supported")
+	}
+}
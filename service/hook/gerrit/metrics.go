@@ -0,0 +1,101 @@
+package gerrit
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// GatherMetrics ...
+func (hp HookProvider) GatherMetrics(r *http.Request, appSlug string) ([]common.Metrics, error) {
+	event, err := parseEvent(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	currentTime := hp.timeProvider.CurrentTime()
+	metricsList := hp.gatherMetrics(event, appSlug, currentTime)
+	return metricsList, nil
+}
+
+func (hp HookProvider) gatherMetrics(event EventModel, appSlug string, currentTime time.Time) []common.Metrics {
+	var metrics common.Metrics
+	switch event.Type {
+	case refUpdatedEventID:
+		metrics = newPushMetrics(event, appSlug, currentTime)
+	case patchsetCreatedEventID, changeMergedEventID:
+		metrics = newPullRequestMetrics(event, appSlug, currentTime)
+	case commentAddedEventID:
+		generalMetrics := newGeneralMetrics(event, event.Change.Project, event.Author, event.PatchSet.Ref, appSlug, currentTime)
+		metrics = common.NewPullRequestCommentMetrics(generalMetrics, strconv.Itoa(event.Change.Number))
+	}
+
+	if metrics == nil {
+		return nil
+	}
+
+	return []common.Metrics{metrics}
+}
+
+func newGeneralMetrics(event EventModel, repo string, user AccountModel, gitRef, appSlug string, currentTime time.Time) common.GeneralMetrics {
+	var timestamp *time.Time
+	if event.EventCreatedOn != 0 {
+		t := time.Unix(event.EventCreatedOn, 0).UTC()
+		timestamp = &t
+	}
+	originalTrigger := common.OriginalTrigger(event.Type, "")
+
+	return common.NewGeneralMetrics(ProviderID, repo, currentTime, timestamp, appSlug, originalTrigger, user.name(), gitRef)
+}
+
+func newPushMetrics(event EventModel, appSlug string, currentTime time.Time) common.PushMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, commitIDAfter string, commitIDBefore string, oldestCommitTimestamp *time.Time, latestCommitTimestamp *time.Time, masterBranch string) common.PushMetrics
+
+	refUpdate := event.RefUpdate
+	switch {
+	case refUpdate.OldRev == emptyCommitHash:
+		constructorFunc = common.NewPushCreatedMetrics
+	case refUpdate.NewRev == emptyCommitHash:
+		constructorFunc = common.NewPushDeletedMetrics
+	default:
+		constructorFunc = common.NewPushMetrics
+	}
+
+	generalMetrics := newGeneralMetrics(event, refUpdate.Project, event.Submitter, refUpdate.RefName, appSlug, currentTime)
+	return constructorFunc(generalMetrics, refUpdate.NewRev, refUpdate.OldRev, nil, nil, "")
+}
+
+func newPullRequestMetrics(event EventModel, appSlug string, currentTime time.Time) common.PullRequestMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, generalPullRequestMetrics common.GeneralPullRequestMetrics) common.PullRequestMetrics
+
+	user := event.Uploader
+	switch {
+	case event.Type == changeMergedEventID:
+		constructorFunc = common.NewPullRequestClosedMetrics
+		user = event.Submitter
+	case event.PatchSet.Number == 1:
+		constructorFunc = common.NewPullRequestOpenedMetrics
+	default:
+		constructorFunc = common.NewPullRequestUpdatedMetrics
+	}
+
+	change := event.Change
+	generalMetrics := newGeneralMetrics(event, change.Project, user, event.PatchSet.Ref, appSlug, currentTime)
+
+	generalPullRequestMetrics := common.GeneralPullRequestMetrics{
+		PullRequestTitle: change.Subject,
+		PullRequestID:    fmt.Sprintf("%d", change.Number),
+		PullRequestURL:   change.URL,
+		TargetBranch:     change.Branch,
+		CommitID:         event.PatchSet.Revision,
+		Additions:        event.PatchSet.SizeInsertions,
+		Deletions:        event.PatchSet.SizeDeletions,
+		MergeCommitSHA:   event.NewRev,
+		Status:           change.Status, // NEW, MERGED or ABANDONED
+	}
+
+	return constructorFunc(generalMetrics, generalPullRequestMetrics)
+}
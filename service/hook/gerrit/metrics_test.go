@@ -0,0 +1,63 @@
+package gerrit
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookProvider_gatherMetrics(t *testing.T) {
+	currentTime := time.Date(2024, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		payload string
+		appSlug string
+		want    string
+	}{
+		{
+			name:    "Ref updated webhook",
+			payload: sampleRefUpdatedData,
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"pushed","provider_type":"gerrit","repository":"android/app","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30Z","app_slug":"slug","original_trigger":"ref-updated:","user_name":"submitter","git_ref":"refs/heads/main","commit_id_after":"bffeb74224043ba2feb48d137756c8a9331c449a","commit_id_before":"28e1879d029cb852e4844d9c718537df08844e03","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:    "Patch set created webhook",
+			payload: samplePatchsetCreatedData,
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"updated","provider_type":"gerrit","repository":"android/app","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30Z","app_slug":"slug","original_trigger":"patchset-created:","user_name":"uploader","git_ref":"refs/changes/45/12345/2","pull_request_title":"Add login screen","pull_request_id":"12345","pull_request_url":"https://gerrit.example.com/c/android/app/+/12345","target_branch":"main","commit_id":"83b86e5f286f546dc5a4a58db66ceef44460c85e","changed_files_count":0,"addition_count":10,"deletion_count":2,"commit_count":0,"status":"NEW"}`,
+		},
+		{
+			name:    "Comment added webhook",
+			payload: sampleCommentAddedData,
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"comment","provider_type":"gerrit","repository":"android/app","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30Z","app_slug":"slug","original_trigger":"comment-added:","user_name":"reviewer","git_ref":"refs/changes/45/12345/2","pull_request_id":"12345"}`,
+		},
+		{
+			name:    "Unsupported webhook",
+			payload: `{"type": "reviewer-added"}`,
+			appSlug: "slug",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			event, err := parseEvent(strings.NewReader(tt.payload))
+			require.NoError(t, err)
+
+			hp := HookProvider{}
+			got := hp.gatherMetrics(event, tt.appSlug, currentTime)
+			if tt.want != "" {
+				require.Equal(t, len(got), 1)
+				gotMetrics := got[0]
+				gotBytes, err := gotMetrics.Serialise()
+				require.NoError(t, err)
+				require.Equal(t, tt.want, string(gotBytes))
+			} else {
+				require.Nil(t, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,529 @@
+package gitea
+
+// # Infos / notes:
+//
+// ## Webhook calls
+//
+// Official API docs: https://docs.gitea.com/usage/webhooks
+// and https://forgejo.org/docs/latest/user/webhooks/
+//
+// Forgejo is a fork of Gitea, and sends the same payloads,
+// but with X-Forgejo-* headers (besides the X-Gitea-* ones, in the current versions).
+//
+// The X-Gitea-Event header includes the "main" event type (e.g. `pull_request`),
+// while the X-Gitea-Event-Type header includes the specific one (e.g. `pull_request_sync`).
+// Old Gitea versions only send the X-Gitea-Event header, with the specific event type.
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// --------------------------
+// --- Webhook Data Model ---
+
+const (
+	pushEventID               = "push"
+	pullRequestEventID        = "pull_request"
+	issueCommentEventID       = "issue_comment"
+	pullRequestCommentEventID = "pull_request_comment"
+	releaseEventID            = "release"
+
+	emptyCommitHash = "0000000000000000000000000000000000000000"
+
+	// ProviderID ...
+	ProviderID = "gitea"
+)
+
+// UserModel ...
+type UserModel struct {
+	Login    string `json:"login"`
+	Username string `json:"username"`
+}
+
+// CommitModel ...
+type CommitModel struct {
+	CommitHash    string `json:"id"`
+	CommitMessage string `json:"message"`
+	Timestamp     string `json:"timestamp"`
+	bitriseapi.CommitPaths
+}
+
+// RepoInfoModel ...
+type RepoInfoModel struct {
+	FullName      string `json:"full_name"`
+	Private       bool   `json:"private"`
+	SSHURL        string `json:"ssh_url"`
+	CloneURL      string `json:"clone_url"`
+	DefaultBranch string `json:"default_branch"`
+}
+
+// PushEventModel ...
+type PushEventModel struct {
+	Ref        string        `json:"ref"`
+	Before     string        `json:"before"`
+	After      string        `json:"after"`
+	HeadCommit *CommitModel  `json:"head_commit"`
+	Commits    []CommitModel `json:"commits"`
+	Repo       RepoInfoModel `json:"repository"`
+	Pusher     UserModel     `json:"pusher"`
+}
+
+// BranchInfoModel ...
+type BranchInfoModel struct {
+	Ref        string        `json:"ref"`
+	CommitHash string        `json:"sha"`
+	Repo       RepoInfoModel `json:"repo"`
+}
+
+// LabelModel ...
+type LabelModel struct {
+	Name string `json:"name"`
+}
+
+// PullRequestInfoModel ...
+type PullRequestInfoModel struct {
+	Number         int             `json:"number"`
+	Title          string          `json:"title"`
+	Body           string          `json:"body"`
+	State          string          `json:"state"`
+	Draft          bool            `json:"draft"`
+	Merged         bool            `json:"merged"`
+	HTMLURL        string          `json:"html_url"`
+	DiffURL        string          `json:"diff_url"`
+	UpdatedAt      string          `json:"updated_at"`
+	User           UserModel       `json:"user"`
+	Labels         []LabelModel    `json:"labels"`
+	BaseBranchInfo BranchInfoModel `json:"base"`
+	HeadBranchInfo BranchInfoModel `json:"head"`
+
+	// MergeCommitSHA is only set once the pull request is merged, it's only reported in the pull request metrics:
+	//  Gitea has no merge ref, the builds are started on the head branch
+	MergeCommitSHA string `json:"merge_commit_sha"`
+}
+
+// PullRequestEventModel ...
+type PullRequestEventModel struct {
+	Action      string               `json:"action"`
+	Number      int                  `json:"number"`
+	PullRequest PullRequestInfoModel `json:"pull_request"`
+	Repo        RepoInfoModel        `json:"repository"`
+	Sender      UserModel            `json:"sender"`
+}
+
+// IssueModel ...
+type IssueModel struct {
+	Number int       `json:"number"`
+	User   UserModel `json:"user"`
+	// PullRequest is set only if the issue is a pull request
+	PullRequest *struct{} `json:"pull_request"`
+}
+
+// CommentModel ...
+type CommentModel struct {
+	ID   int64     `json:"id"`
+	Body string    `json:"body"`
+	User UserModel `json:"user"`
+}
+
+// IssueCommentEventModel ...
+type IssueCommentEventModel struct {
+	Action  string        `json:"action"`
+	IsPull  bool          `json:"is_pull"`
+	Issue   IssueModel    `json:"issue"`
+	Comment CommentModel  `json:"comment"`
+	Repo    RepoInfoModel `json:"repository"`
+	Sender  UserModel     `json:"sender"`
+	// PullRequest is only sent by the newer Gitea versions
+	PullRequest *PullRequestInfoModel `json:"pull_request"`
+}
+
+// ReleaseModel ...
+type ReleaseModel struct {
+	TagName         string    `json:"tag_name"`
+	TargetCommitish string    `json:"target_commitish"`
+	Name            string    `json:"name"`
+	Body            string    `json:"body"`
+	Draft           bool      `json:"draft"`
+	Prerelease      bool      `json:"prerelease"`
+	HTMLURL         string    `json:"html_url"`
+	Author          UserModel `json:"author"`
+}
+
+// ReleaseEventModel ...
+type ReleaseEventModel struct {
+	Action  string        `json:"action"`
+	Release ReleaseModel  `json:"release"`
+	Repo    RepoInfoModel `json:"repository"`
+	Sender  UserModel     `json:"sender"`
+}
+
+// ---------------------------------------
+// --- Webhook Provider Implementation ---
+
+// HookProvider ...
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
+
+// firstHeader returns the value of the first header which is set,
+// to support both the X-Gitea-* and the X-Forgejo-* headers.
+func firstHeader(header http.Header, keys ...string) string {
+	for _, key := range keys {
+		if value := header.Get(key); value != "" {
+			return value
+		}
+	}
+	return ""
+}
+
+func detectContentTypeAndEventID(header http.Header) (string, string, error) {
+	contentType := header.Get("Content-Type")
+	if contentType == "" {
+		return "", "", errors.New("No Content-Type Header found")
+	}
+
+	eventID := firstHeader(header, "X-Gitea-Event-Type", "X-Forgejo-Event-Type", "X-Gitea-Event", "X-Forgejo-Event")
+	if eventID == "" {
+		return "", "", errors.New("No X-Gitea-Event nor X-Forgejo-Event Header found")
+	}
+
+	return contentType, eventID, nil
+}
+
+func (user UserModel) name() string {
+	if user.Login != "" {
+		return user.Login
+	}
+	return user.Username
+}
+
+func (repoInfoModel RepoInfoModel) getRepositoryURL() string {
+	if repoInfoModel.Private {
+		return repoInfoModel.SSHURL
+	}
+	return repoInfoModel.CloneURL
+}
+
+func transformPushEvent(pushEvent PushEventModel) hookCommon.TransformResultModel {
+	if pushEvent.After == emptyCommitHash {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("this is a 'Deleted' event, no build can be started"),
+			ShouldSkip: true,
+		}
+	}
+
+	if !strings.HasPrefix(pushEvent.Ref, "refs/heads/") && !strings.HasPrefix(pushEvent.Ref, "refs/tags/") {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("ref (%s) is not a head nor a tag ref", pushEvent.Ref),
+			ShouldSkip: true,
+		}
+	}
+
+	commits := pushEvent.Commits
+	headCommit := CommitModel{CommitHash: pushEvent.After}
+	if pushEvent.HeadCommit != nil {
+		headCommit = *pushEvent.HeadCommit
+	} else {
+		for _, aCommit := range commits {
+			if aCommit.CommitHash == pushEvent.After {
+				headCommit = aCommit
+				break
+			}
+		}
+	}
+	if len(headCommit.CommitHash) == 0 {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("missing commit hash"),
+		}
+	}
+	if len(commits) == 0 {
+		commits = []CommitModel{headCommit}
+	}
+
+	var commitPaths []bitriseapi.CommitPaths
+	var commitMessages []string
+	for _, commit := range commits {
+		commitPaths = append(commitPaths, commit.CommitPaths)
+		commitMessages = append(commitMessages, commit.CommitMessage)
+	}
+
+	buildParams := bitriseapi.BuildParamsModel{
+		CommitHash:        headCommit.CommitHash,
+		CommitMessage:     headCommit.CommitMessage,
+		CommitMessages:    commitMessages,
+		PushCommitPaths:   commitPaths,
+		BaseRepositoryURL: pushEvent.Repo.getRepositoryURL(),
+	}
+	if strings.HasPrefix(pushEvent.Ref, "refs/heads/") {
+		buildParams.Branch = strings.TrimPrefix(pushEvent.Ref, "refs/heads/")
+	} else {
+		buildParams.Tag = strings.TrimPrefix(pushEvent.Ref, "refs/tags/")
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: buildParams,
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, pushEvent.Pusher.name()),
+			},
+		},
+	}
+}
+
+// isPullRequestEventID returns true for the pull request event and its specific types
+// (e.g. `pull_request_sync`, `pull_request_label`, `pull_request_review_approved`), which send the pull request payload.
+// The pull request comments send the issue comment payload.
+func isPullRequestEventID(eventID string) bool {
+	return eventID == pullRequestEventID ||
+		eventID != pullRequestCommentEventID && strings.HasPrefix(eventID, pullRequestEventID+"_")
+}
+
+func isAcceptPullRequestAction(prAction string) bool {
+	return slices.Contains([]string{"opened", "reopened", "synchronized", "edited", "label_updated"}, prAction)
+}
+
+func pullRequestBuildParams(pullRequest PullRequestInfoModel) bitriseapi.BuildParamsModel {
+	var labels []string
+	for _, label := range pullRequest.Labels {
+		labels = append(labels, label.Name)
+	}
+
+	readyState := bitriseapi.PullRequestReadyStateReadyForReview
+	if pullRequest.Draft {
+		readyState = bitriseapi.PullRequestReadyStateDraft
+	}
+
+	commitMessage := pullRequest.Title
+	if pullRequest.Body != "" {
+		commitMessage = fmt.Sprintf("%s\n\n%s", commitMessage, pullRequest.Body)
+	}
+
+	return bitriseapi.BuildParamsModel{
+		CommitMessage:            commitMessage,
+		CommitHash:               pullRequest.HeadBranchInfo.CommitHash,
+		Branch:                   pullRequest.HeadBranchInfo.Ref,
+		BranchDest:               pullRequest.BaseBranchInfo.Ref,
+		PullRequestID:            &pullRequest.Number,
+		BaseRepositoryURL:        pullRequest.BaseBranchInfo.Repo.getRepositoryURL(),
+		HeadRepositoryURL:        pullRequest.HeadBranchInfo.Repo.getRepositoryURL(),
+		PullRequestRepositoryURL: pullRequest.HeadBranchInfo.Repo.getRepositoryURL(),
+		PullRequestAuthor:        pullRequest.User.name(),
+		PullRequestHeadBranch:    fmt.Sprintf("pull/%d/head", pullRequest.Number),
+		DiffURL:                  pullRequest.DiffURL,
+		PullRequestReadyState:    readyState,
+		PullRequestLabels:        labels,
+	}
+}
+
+func transformPullRequestEvent(pullRequest PullRequestEventModel) hookCommon.TransformResultModel {
+	if pullRequest.Action == "" {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("no Pull Request action specified"),
+			ShouldSkip: true,
+		}
+	}
+	if !isAcceptPullRequestAction(pullRequest.Action) {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("pull Request action doesn't require a build: %s", pullRequest.Action),
+			ShouldSkip: true,
+		}
+	}
+	if pullRequest.PullRequest.Merged {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("pull Request already merged"),
+			ShouldSkip: true,
+		}
+	}
+	if pullRequest.PullRequest.HeadBranchInfo.CommitHash == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing commit hash"),
+		}
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: pullRequestBuildParams(pullRequest.PullRequest),
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, pullRequest.Sender.name()),
+			},
+		},
+	}
+}
+
+func transformIssueCommentEvent(eventModel IssueCommentEventModel) hookCommon.TransformResultModel {
+	if !eventModel.IsPull && eventModel.Issue.PullRequest == nil && eventModel.PullRequest == nil {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("issue comment is not on a pull request"),
+			ShouldSkip: true,
+		}
+	}
+	if eventModel.Action != "created" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("issue comment action doesn't require a build: %s", eventModel.Action),
+			ShouldSkip: true,
+		}
+	}
+
+	// The older Gitea versions don't send the pull request of the comment:
+	// without its head branch, commit and base branch the build can't be started.
+	if eventModel.PullRequest == nil {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("pull request comment doesn't include the pull request (sent by older Gitea versions)"),
+			ShouldSkip: true,
+		}
+	}
+
+	buildParams := pullRequestBuildParams(*eventModel.PullRequest)
+	buildParams.PullRequestComment = eventModel.Comment.Body
+	buildParams.PullRequestCommentID = strconv.FormatInt(eventModel.Comment.ID, 10)
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: buildParams,
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, eventModel.Sender.name()),
+			},
+		},
+	}
+}
+
+func transformReleaseEvent(eventModel ReleaseEventModel) hookCommon.TransformResultModel {
+	if eventModel.Action != "published" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("release action doesn't require a build: %s", eventModel.Action),
+			ShouldSkip: true,
+		}
+	}
+	release := eventModel.Release
+	if release.Draft {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("release is a draft"),
+			ShouldSkip: true,
+		}
+	}
+	if release.TagName == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing release tag name"),
+		}
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               release.TagName,
+					CommitMessage:     release.Name,
+					BaseRepositoryURL: eventModel.Repo.getRepositoryURL(),
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITEA_RELEASE_NAME", Value: release.Name, IsExpand: false},
+						{Name: "GITEA_RELEASE_TARGET", Value: release.TargetCommitish, IsExpand: false},
+						{Name: "GITEA_RELEASE_URL", Value: release.HTMLURL, IsExpand: false},
+						{Name: "GITEA_RELEASE_IS_PRERELEASE", Value: strconv.FormatBool(release.Prerelease), IsExpand: false},
+					},
+				},
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, eventModel.Sender.name()),
+			},
+		},
+	}
+}
+
+// TransformRequest ...
+func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
+	contentType, eventID, err := detectContentTypeAndEventID(r.Header)
+	if err != nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Issue with Headers: %s", err),
+		}
+	}
+
+	if contentType != hookCommon.ContentTypeApplicationJSON {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Content-Type is not supported: %s", contentType),
+		}
+	}
+
+	if r.Body == nil {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Failed to read content of request body: no or empty request body"),
+		}
+	}
+
+	if isPullRequestEventID(eventID) {
+		eventID = pullRequestEventID
+	}
+
+	switch eventID {
+	case pushEventID:
+		var pushEvent PushEventModel
+		if err := json.NewDecoder(r.Body).Decode(&pushEvent); err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse request body: %s", err)}
+		}
+
+		return transformPushEvent(pushEvent)
+	case pullRequestEventID:
+		var pullRequestEvent PullRequestEventModel
+		if err := json.NewDecoder(r.Body).Decode(&pullRequestEvent); err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse request body: %s", err)}
+		}
+
+		return transformPullRequestEvent(pullRequestEvent)
+	case issueCommentEventID, pullRequestCommentEventID:
+		var issueCommentEvent IssueCommentEventModel
+		if err := json.NewDecoder(r.Body).Decode(&issueCommentEvent); err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse request body: %s", err)}
+		}
+
+		return transformIssueCommentEvent(issueCommentEvent)
+	case releaseEventID:
+		var releaseEvent ReleaseEventModel
+		if err := json.NewDecoder(r.Body).Decode(&releaseEvent); err != nil {
+			return hookCommon.TransformResultModel{Error: fmt.Errorf("Failed to parse request body: %s", err)}
+		}
+
+		return transformReleaseEvent(releaseEvent)
+	}
+
+	// Unsupported Event
+	return hookCommon.TransformResultModel{
+		Error: fmt.Errorf("Unsupported Webhook event: %s", eventID),
+	}
+}
+
+// DeliveryID ...
+// Gitea sends the same X-Gitea-Delivery GUID for the redeliveries of a webhook.
+func (hp HookProvider) DeliveryID(header http.Header) string {
+	return firstHeader(header, "X-Gitea-Delivery", "X-Forgejo-Delivery")
+}
+
+// VerifySignature ...
+// Gitea signs the payload with the webhook's secret (HMAC SHA256),
+// and sends the hex encoded signature in the X-Gitea-Signature header (X-Forgejo-Signature on Forgejo).
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	signature := firstHeader(header, "X-Gitea-Signature", "X-Forgejo-Signature")
+	if signature == "" {
+		return hookCommon.ErrMissingSignature
+	}
+	return hookCommon.VerifyHMACSignature(sha256.New, secret, body, signature)
+}
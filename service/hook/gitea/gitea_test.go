@@ -0,0 +1,579 @@
+package gitea
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+	"github.com/stretchr/testify/require"
+)
+
+const samplePushData = `{
+  "ref": "refs/heads/main",
+  "before": "28e1879d029cb852e4844d9c718537df08844e03",
+  "after": "bffeb74224043ba2feb48d137756c8a9331c449a",
+  "commits": [
+    {
+      "id": "bffeb74224043ba2feb48d137756c8a9331c449a",
+      "message": "Update README\n",
+      "timestamp": "2024-03-07T10:15:30+01:00",
+      "added": [],
+      "removed": [],
+      "modified": ["README.md"]
+    }
+  ],
+  "head_commit": {
+    "id": "bffeb74224043ba2feb48d137756c8a9331c449a",
+    "message": "Update README\n",
+    "timestamp": "2024-03-07T10:15:30+01:00",
+    "added": [],
+    "removed": [],
+    "modified": ["README.md"]
+  },
+  "repository": {
+    "full_name": "gitea/webhooks",
+    "private": false,
+    "clone_url": "https://gitea.example.com/gitea/webhooks.git",
+    "ssh_url": "git@gitea.example.com:gitea/webhooks.git",
+    "default_branch": "main"
+  },
+  "pusher": {"login": "gitea-user", "username": "gitea-user"}
+}`
+
+const samplePullRequestData = `{
+  "action": "synchronized",
+  "number": 2,
+  "pull_request": {
+    "number": 2,
+    "title": "Add feature",
+    "body": "Feature description",
+    "state": "open",
+    "draft": false,
+    "merged": false,
+    "html_url": "https://gitea.example.com/gitea/webhooks/pulls/2",
+    "diff_url": "https://gitea.example.com/gitea/webhooks/pulls/2.diff",
+    "updated_at": "2024-03-07T10:15:30+01:00",
+    "user": {"login": "pr-author"},
+    "labels": [{"name": "bug"}],
+    "base": {
+      "ref": "main",
+      "sha": "28e1879d029cb852e4844d9c718537df08844e03",
+      "repo": {"full_name": "gitea/webhooks", "clone_url": "https://gitea.example.com/gitea/webhooks.git"}
+    },
+    "head": {
+      "ref": "feature",
+      "sha": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+      "repo": {"full_name": "gitea/webhooks", "clone_url": "https://gitea.example.com/gitea/webhooks.git"}
+    }
+  },
+  "repository": {"full_name": "gitea/webhooks", "clone_url": "https://gitea.example.com/gitea/webhooks.git"},
+  "sender": {"login": "gitea-user"}
+}`
+
+var intTwo = 2
+
+func Test_detectContentTypeAndEventID(t *testing.T) {
+	t.Log("Gitea event")
+	{
+		header := http.Header{
+			"X-Gitea-Event": {"push"},
+			"Content-Type":  {"application/json"},
+		}
+		contentType, eventID, err := detectContentTypeAndEventID(header)
+		require.NoError(t, err)
+		require.Equal(t, "application/json", contentType)
+		require.Equal(t, "push", eventID)
+	}
+
+	t.Log("Forgejo event")
+	{
+		header := http.Header{
+			"X-Forgejo-Event": {"push"},
+			"Content-Type":    {"application/json"},
+		}
+		_, eventID, err := detectContentTypeAndEventID(header)
+		require.NoError(t, err)
+		require.Equal(t, "push", eventID)
+	}
+
+	t.Log("Specific event type is preferred")
+	{
+		header := http.Header{
+			"X-Gitea-Event":      {"pull_request"},
+			"X-Gitea-Event-Type": {"pull_request_sync"},
+			"Content-Type":       {"application/json"},
+		}
+		_, eventID, err := detectContentTypeAndEventID(header)
+		require.NoError(t, err)
+		require.Equal(t, "pull_request_sync", eventID)
+	}
+
+	t.Log("Missing event header")
+	{
+		header := http.Header{
+			"Content-Type": {"application/json"},
+		}
+		_, _, err := detectContentTypeAndEventID(header)
+		require.EqualError(t, err, "No X-Gitea-Event nor X-Forgejo-Event Header found")
+	}
+
+	t.Log("Missing Content-Type")
+	{
+		header := http.Header{
+			"X-Gitea-Event": {"push"},
+		}
+		_, _, err := detectContentTypeAndEventID(header)
+		require.EqualError(t, err, "No Content-Type Header found")
+	}
+}
+
+func Test_transformPushEvent(t *testing.T) {
+	t.Log("Branch push")
+	{
+		pushEvent := PushEventModel{
+			Ref:   "refs/heads/main",
+			After: "bffeb74224043ba2feb48d137756c8a9331c449a",
+			HeadCommit: &CommitModel{
+				CommitHash:    "bffeb74224043ba2feb48d137756c8a9331c449a",
+				CommitMessage: "Update README",
+				CommitPaths:   bitriseapi.CommitPaths{Modified: []string{"README.md"}},
+			},
+			Commits: []CommitModel{
+				{
+					CommitHash:    "bffeb74224043ba2feb48d137756c8a9331c449a",
+					CommitMessage: "Update README",
+					CommitPaths:   bitriseapi.CommitPaths{Modified: []string{"README.md"}},
+				},
+			},
+			Repo:   RepoInfoModel{CloneURL: "https://gitea.example.com/gitea/webhooks.git"},
+			Pusher: UserModel{Login: "gitea-user"},
+		}
+		hookTransformResult := transformPushEvent(pushEvent)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Branch:            "main",
+					CommitHash:        "bffeb74224043ba2feb48d137756c8a9331c449a",
+					CommitMessage:     "Update README",
+					CommitMessages:    []string{"Update README"},
+					PushCommitPaths:   []bitriseapi.CommitPaths{{Modified: []string{"README.md"}}},
+					BaseRepositoryURL: "https://gitea.example.com/gitea/webhooks.git",
+				},
+				TriggeredBy: "webhook-gitea/gitea-user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Tag push - private repository")
+	{
+		pushEvent := PushEventModel{
+			Ref:        "refs/tags/v1.0.0",
+			After:      "bffeb74224043ba2feb48d137756c8a9331c449a",
+			HeadCommit: &CommitModel{CommitHash: "bffeb74224043ba2feb48d137756c8a9331c449a", CommitMessage: "Release"},
+			Repo:       RepoInfoModel{Private: true, SSHURL: "git@gitea.example.com:gitea/webhooks.git"},
+			Pusher:     UserModel{Username: "gitea-user"},
+		}
+		hookTransformResult := transformPushEvent(pushEvent)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               "v1.0.0",
+					CommitHash:        "bffeb74224043ba2feb48d137756c8a9331c449a",
+					CommitMessage:     "Release",
+					CommitMessages:    []string{"Release"},
+					PushCommitPaths:   []bitriseapi.CommitPaths{{}},
+					BaseRepositoryURL: "git@gitea.example.com:gitea/webhooks.git",
+				},
+				TriggeredBy: "webhook-gitea/gitea-user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Branch delete - should be skipped")
+	{
+		pushEvent := PushEventModel{
+			Ref:   "refs/heads/feature",
+			After: "0000000000000000000000000000000000000000",
+		}
+		hookTransformResult := transformPushEvent(pushEvent)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "this is a 'Deleted' event, no build can be started")
+	}
+
+	t.Log("Not a head nor a tag ref - should be skipped")
+	{
+		pushEvent := PushEventModel{
+			Ref:   "refs/notes/commits",
+			After: "bffeb74224043ba2feb48d137756c8a9331c449a",
+		}
+		hookTransformResult := transformPushEvent(pushEvent)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "ref (refs/notes/commits) is not a head nor a tag ref")
+	}
+}
+
+func Test_transformPullRequestEvent(t *testing.T) {
+	pullRequest := PullRequestInfoModel{
+		Number:  2,
+		Title:   "Add feature",
+		DiffURL: "https://gitea.example.com/gitea/webhooks/pulls/2.diff",
+		User:    UserModel{Login: "pr-author"},
+		Labels:  []LabelModel{{Name: "bug"}},
+		BaseBranchInfo: BranchInfoModel{
+			Ref:  "main",
+			Repo: RepoInfoModel{CloneURL: "https://gitea.example.com/gitea/webhooks.git"},
+		},
+		HeadBranchInfo: BranchInfoModel{
+			Ref:        "feature",
+			CommitHash: "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+			Repo:       RepoInfoModel{CloneURL: "https://gitea.example.com/fork/webhooks.git"},
+		},
+	}
+
+	t.Log("Opened")
+	{
+		hookTransformResult := transformPullRequestEvent(PullRequestEventModel{Action: "opened", PullRequest: pullRequest, Sender: UserModel{Login: "gitea-user"}})
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitMessage:            "Add feature",
+					CommitHash:               "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+					Branch:                   "feature",
+					BranchDest:               "main",
+					PullRequestID:            &intTwo,
+					BaseRepositoryURL:        "https://gitea.example.com/gitea/webhooks.git",
+					HeadRepositoryURL:        "https://gitea.example.com/fork/webhooks.git",
+					PullRequestRepositoryURL: "https://gitea.example.com/fork/webhooks.git",
+					PullRequestAuthor:        "pr-author",
+					PullRequestHeadBranch:    "pull/2/head",
+					DiffURL:                  "https://gitea.example.com/gitea/webhooks/pulls/2.diff",
+					PullRequestReadyState:    bitriseapi.PullRequestReadyStateReadyForReview,
+					PullRequestLabels:        []string{"bug"},
+				},
+				TriggeredBy: "webhook-gitea/gitea-user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Draft")
+	{
+		draftPullRequest := pullRequest
+		draftPullRequest.Draft = true
+		hookTransformResult := transformPullRequestEvent(PullRequestEventModel{Action: "synchronized", PullRequest: draftPullRequest})
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, bitriseapi.PullRequestReadyStateDraft, hookTransformResult.TriggerAPIParams[0].BuildParams.PullRequestReadyState)
+	}
+
+	t.Log("Closed - should be skipped")
+	{
+		hookTransformResult := transformPullRequestEvent(PullRequestEventModel{Action: "closed", PullRequest: pullRequest})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull Request action doesn't require a build: closed")
+	}
+
+	t.Log("Merged - should be skipped")
+	{
+		mergedPullRequest := pullRequest
+		mergedPullRequest.Merged = true
+		hookTransformResult := transformPullRequestEvent(PullRequestEventModel{Action: "edited", PullRequest: mergedPullRequest})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull Request already merged")
+	}
+
+	t.Log("Missing commit hash")
+	{
+		hookTransformResult := transformPullRequestEvent(PullRequestEventModel{Action: "opened", PullRequest: PullRequestInfoModel{Number: 2}})
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "missing commit hash")
+	}
+}
+
+func Test_transformIssueCommentEvent(t *testing.T) {
+	t.Log("Comment on a pull request - with the pull request")
+	{
+		eventModel := IssueCommentEventModel{
+			Action:  "created",
+			IsPull:  true,
+			Issue:   IssueModel{Number: 2},
+			Comment: CommentModel{ID: 42, Body: "bitrise run"},
+			Sender:  UserModel{Login: "commenter"},
+			PullRequest: &PullRequestInfoModel{
+				Number:         2,
+				Title:          "Add feature",
+				User:           UserModel{Login: "pr-author"},
+				BaseBranchInfo: BranchInfoModel{Ref: "main"},
+				HeadBranchInfo: BranchInfoModel{Ref: "feature", CommitHash: "83b86e5f286f546dc5a4a58db66ceef44460c85e"},
+			},
+		}
+		hookTransformResult := transformIssueCommentEvent(eventModel)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitMessage:         "Add feature",
+					CommitHash:            "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+					Branch:                "feature",
+					BranchDest:            "main",
+					PullRequestID:         &intTwo,
+					PullRequestAuthor:     "pr-author",
+					PullRequestHeadBranch: "pull/2/head",
+					PullRequestReadyState: bitriseapi.PullRequestReadyStateReadyForReview,
+					PullRequestComment:    "bitrise run",
+					PullRequestCommentID:  "42",
+				},
+				TriggeredBy: "webhook-gitea/commenter",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Comment on a pull request - without the pull request (older Gitea versions) - should be skipped")
+	{
+		eventModel := IssueCommentEventModel{
+			Action:  "created",
+			IsPull:  true,
+			Issue:   IssueModel{Number: 2, User: UserModel{Login: "pr-author"}},
+			Comment: CommentModel{ID: 42, Body: "bitrise run"},
+			Repo:    RepoInfoModel{CloneURL: "https://gitea.example.com/gitea/webhooks.git"},
+			Sender:  UserModel{Login: "commenter"},
+		}
+		hookTransformResult := transformIssueCommentEvent(eventModel)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull request comment doesn't include the pull request (sent by older Gitea versions)")
+		require.Nil(t, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Comment on an issue - should be skipped")
+	{
+		hookTransformResult := transformIssueCommentEvent(IssueCommentEventModel{Action: "created", Issue: IssueModel{Number: 3}})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "issue comment is not on a pull request")
+	}
+
+	t.Log("Edited comment - should be skipped")
+	{
+		hookTransformResult := transformIssueCommentEvent(IssueCommentEventModel{Action: "edited", IsPull: true})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "issue comment action doesn't require a build: edited")
+	}
+}
+
+func Test_transformReleaseEvent(t *testing.T) {
+	t.Log("Published")
+	{
+		eventModel := ReleaseEventModel{
+			Action: "published",
+			Release: ReleaseModel{
+				TagName:         "v1.0.0",
+				TargetCommitish: "main",
+				Name:            "First release",
+				Prerelease:      true,
+				HTMLURL:         "https://gitea.example.com/gitea/webhooks/releases/tag/v1.0.0",
+			},
+			Repo:   RepoInfoModel{CloneURL: "https://gitea.example.com/gitea/webhooks.git"},
+			Sender: UserModel{Login: "gitea-user"},
+		}
+		hookTransformResult := transformReleaseEvent(eventModel)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               "v1.0.0",
+					CommitMessage:     "First release",
+					BaseRepositoryURL: "https://gitea.example.com/gitea/webhooks.git",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITEA_RELEASE_NAME", Value: "First release", IsExpand: false},
+						{Name: "GITEA_RELEASE_TARGET", Value: "main", IsExpand: false},
+						{Name: "GITEA_RELEASE_URL", Value: "https://gitea.example.com/gitea/webhooks/releases/tag/v1.0.0", IsExpand: false},
+						{Name: "GITEA_RELEASE_IS_PRERELEASE", Value: "true", IsExpand: false},
+					},
+				},
+				TriggeredBy: "webhook-gitea/gitea-user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Updated - should be skipped")
+	{
+		hookTransformResult := transformReleaseEvent(ReleaseEventModel{Action: "updated", Release: ReleaseModel{TagName: "v1.0.0"}})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "release action doesn't require a build: updated")
+	}
+
+	t.Log("Draft - should be skipped")
+	{
+		hookTransformResult := transformReleaseEvent(ReleaseEventModel{Action: "published", Release: ReleaseModel{TagName: "v1.0.0", Draft: true}})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "release is a draft")
+	}
+}
+
+func Test_HookProvider_TransformRequest(t *testing.T) {
+	provider := HookProvider{}
+
+	t.Log("Push event")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"Content-Type":  {"application/json"},
+				"X-Gitea-Event": {"push"},
+			},
+			Body: io.NopCloser(strings.NewReader(samplePushData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.Len(t, hookTransformResult.TriggerAPIParams, 1)
+		require.Equal(t, "main", hookTransformResult.TriggerAPIParams[0].BuildParams.Branch)
+		require.Equal(t, "bffeb74224043ba2feb48d137756c8a9331c449a", hookTransformResult.TriggerAPIParams[0].BuildParams.CommitHash)
+	}
+
+	t.Log("Forgejo pull request sync event")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"Content-Type":         {"application/json"},
+				"X-Forgejo-Event":      {"pull_request"},
+				"X-Forgejo-Event-Type": {"pull_request_sync"},
+			},
+			Body: io.NopCloser(strings.NewReader(samplePullRequestData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.Len(t, hookTransformResult.TriggerAPIParams, 1)
+		require.Equal(t, "feature", hookTransformResult.TriggerAPIParams[0].BuildParams.Branch)
+		require.Equal(t, &intTwo, hookTransformResult.TriggerAPIParams[0].BuildParams.PullRequestID)
+	}
+
+	t.Log("Specific pull request event types")
+	{
+		for eventType, want := range map[string]struct {
+			action     string
+			shouldSkip bool
+			err        string
+		}{
+			"pull_request_label":           {action: "label_updated"},
+			"pull_request_assign":          {action: "assigned", shouldSkip: true, err: "pull Request action doesn't require a build: assigned"},
+			"pull_request_milestone":       {action: "milestoned", shouldSkip: true, err: "pull Request action doesn't require a build: milestoned"},
+			"pull_request_review_approved": {action: "reviewed", shouldSkip: true, err: "pull Request action doesn't require a build: reviewed"},
+			"pull_request_review_rejected": {action: "reviewed", shouldSkip: true, err: "pull Request action doesn't require a build: reviewed"},
+			"pull_request_review_comment":  {action: "reviewed", shouldSkip: true, err: "pull Request action doesn't require a build: reviewed"},
+			"pull_request_review_request":  {action: "review_requested", shouldSkip: true, err: "pull Request action doesn't require a build: review_requested"},
+		} {
+			t.Log(" * Event type:", eventType)
+			request := http.Request{
+				Header: http.Header{
+					"Content-Type":       {"application/json"},
+					"X-Gitea-Event":      {"pull_request"},
+					"X-Gitea-Event-Type": {eventType},
+				},
+				Body: io.NopCloser(strings.NewReader(strings.Replace(samplePullRequestData, `"action": "synchronized"`, `"action": "`+want.action+`"`, 1))),
+			}
+			hookTransformResult := provider.TransformRequest(&request)
+			require.Equal(t, want.shouldSkip, hookTransformResult.ShouldSkip)
+			if want.err != "" {
+				require.EqualError(t, hookTransformResult.Error, want.err)
+				continue
+			}
+			require.NoError(t, hookTransformResult.Error)
+			require.Len(t, hookTransformResult.TriggerAPIParams, 1)
+			require.Equal(t, []string{"bug"}, hookTransformResult.TriggerAPIParams[0].BuildParams.PullRequestLabels)
+		}
+	}
+
+	t.Log("Unsupported Content-Type")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"Content-Type":  {"not/supported"},
+				"X-Gitea-Event": {"push"},
+			},
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Content-Type is not supported: not/supported")
+	}
+
+	t.Log("Unsupported event")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"Content-Type":  {"application/json"},
+				"X-Gitea-Event": {"fork"},
+			},
+			Body: io.NopCloser(strings.NewReader(`{}`)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Unsupported Webhook event: fork")
+	}
+
+	t.Log("Missing body")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"Content-Type":  {"application/json"},
+				"X-Gitea-Event": {"push"},
+			},
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.EqualError(t, hookTransformResult.Error, "Failed to read content of request body: no or empty request body")
+	}
+}
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	provider := HookProvider{}
+	body := []byte(`{"ref":"refs/heads/master"}`)
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		secret  string
+		wantErr error
+	}{
+		{
+			name:   "Valid X-Gitea-Signature",
+			header: http.Header{"X-Gitea-Signature": {"5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"}},
+			secret: "my-secret",
+		},
+		{
+			name:   "Valid X-Forgejo-Signature",
+			header: http.Header{"X-Forgejo-Signature": {"5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"}},
+			secret: "my-secret",
+		},
+		{
+			name:    "Signature mismatch",
+			header:  http.Header{"X-Gitea-Signature": {"5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"}},
+			secret:  "other-secret",
+			wantErr: hookCommon.ErrInvalidSignature,
+		},
+		{
+			name:    "Missing signature",
+			header:  http.Header{},
+			secret:  "my-secret",
+			wantErr: hookCommon.ErrMissingSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := provider.VerifySignature(tt.header, body, tt.secret)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_HookProvider_DeliveryID(t *testing.T) {
+	provider := HookProvider{}
+	require.Equal(t, "a1b2c3", provider.DeliveryID(http.Header{"X-Gitea-Delivery": {"a1b2c3"}}))
+	require.Equal(t, "d4e5f6", provider.DeliveryID(http.Header{"X-Forgejo-Delivery": {"d4e5f6"}}))
+	require.Equal(t, "", provider.DeliveryID(http.Header{}))
+}
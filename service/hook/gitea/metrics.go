@@ -0,0 +1,139 @@
+package gitea
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// GatherMetrics ...
+func (hp HookProvider) GatherMetrics(r *http.Request, appSlug string) ([]common.Metrics, error) {
+	_, eventID, err := detectContentTypeAndEventID(r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var event interface{}
+	switch {
+	case eventID == pushEventID:
+		event = &PushEventModel{}
+	case isPullRequestEventID(eventID):
+		event = &PullRequestEventModel{}
+	case eventID == issueCommentEventID, eventID == pullRequestCommentEventID:
+		event = &IssueCommentEventModel{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	currentTime := hp.timeProvider.CurrentTime()
+	metricsList := hp.gatherMetrics(event, eventID, appSlug, currentTime)
+	return metricsList, nil
+}
+
+func (hp HookProvider) gatherMetrics(event interface{}, eventID, appSlug string, currentTime time.Time) []common.Metrics {
+	var metrics common.Metrics
+	switch event := event.(type) {
+	case *PushEventModel:
+		metrics = newPushMetrics(event, eventID, appSlug, currentTime)
+	case *PullRequestEventModel:
+		metrics = newPullRequestMetrics(event, eventID, appSlug, currentTime)
+	case *IssueCommentEventModel:
+		if !event.IsPull && event.Issue.PullRequest == nil && event.PullRequest == nil {
+			return nil
+		}
+		metrics = newPullRequestCommentMetrics(event, eventID, appSlug, currentTime)
+	}
+
+	if metrics == nil {
+		return nil
+	}
+
+	return []common.Metrics{metrics}
+}
+
+func newPushMetrics(event *PushEventModel, eventID, appSlug string, currentTime time.Time) common.PushMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, commitIDAfter string, commitIDBefore string, oldestCommitTimestamp *time.Time, latestCommitTimestamp *time.Time, masterBranch string) common.PushMetrics
+
+	switch {
+	case event.Before == emptyCommitHash:
+		constructorFunc = common.NewPushCreatedMetrics
+	case event.After == emptyCommitHash:
+		constructorFunc = common.NewPushDeletedMetrics
+	default:
+		constructorFunc = common.NewPushMetrics
+	}
+
+	var oldestCommitTime, latestCommitTime *time.Time
+	if len(event.Commits) > 0 {
+		// Gitea lists the commits from the newest to the oldest
+		latestCommitTime = parseTime(event.Commits[0].Timestamp)
+		oldestCommitTime = parseTime(event.Commits[len(event.Commits)-1].Timestamp)
+	}
+
+	generalMetrics := common.NewGeneralMetrics(ProviderID, event.Repo.FullName, currentTime, nil, appSlug, common.OriginalTrigger(eventID, ""), event.Pusher.name(), event.Ref)
+	pushMetrics := constructorFunc(generalMetrics, event.After, event.Before, oldestCommitTime, latestCommitTime, event.Repo.DefaultBranch)
+	if event.HeadCommit != nil {
+		pushMetrics.ChangedFiles, pushMetrics.Additions, pushMetrics.Deletions = len(event.HeadCommit.Modified), len(event.HeadCommit.Added), len(event.HeadCommit.Removed)
+	}
+	return pushMetrics
+}
+
+func newPullRequestMetrics(event *PullRequestEventModel, eventID, appSlug string, currentTime time.Time) common.PullRequestMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, generalPullRequestMetrics common.GeneralPullRequestMetrics) common.PullRequestMetrics
+
+	switch event.Action {
+	case "opened":
+		constructorFunc = common.NewPullRequestOpenedMetrics
+	case "closed":
+		constructorFunc = common.NewPullRequestClosedMetrics
+	default:
+		constructorFunc = common.NewPullRequestUpdatedMetrics
+	}
+
+	pullRequest := event.PullRequest
+	timestamp := parseTime(pullRequest.UpdatedAt)
+	generalMetrics := common.NewGeneralMetrics(ProviderID, event.Repo.FullName, currentTime, timestamp, appSlug, common.OriginalTrigger(eventID, event.Action), event.Sender.name(), pullRequest.HeadBranchInfo.Ref)
+
+	generalPullRequestMetrics := common.GeneralPullRequestMetrics{
+		PullRequestTitle: pullRequest.Title,
+		PullRequestID:    fmt.Sprintf("%d", pullRequest.Number),
+		PullRequestURL:   pullRequest.HTMLURL,
+		TargetBranch:     pullRequest.BaseBranchInfo.Ref,
+		CommitID:         pullRequest.HeadBranchInfo.CommitHash,
+		MergeCommitSHA:   pullRequest.MergeCommitSHA,
+		Status:           pullRequest.State, // open or closed
+	}
+
+	return constructorFunc(generalMetrics, generalPullRequestMetrics)
+}
+
+func newPullRequestCommentMetrics(event *IssueCommentEventModel, eventID, appSlug string, currentTime time.Time) common.PullRequestCommentMetrics {
+	var gitRef string
+	if event.PullRequest != nil {
+		gitRef = event.PullRequest.HeadBranchInfo.Ref
+	}
+
+	generalMetrics := common.NewGeneralMetrics(ProviderID, event.Repo.FullName, currentTime, nil, appSlug, common.OriginalTrigger(eventID, event.Action), event.Sender.name(), gitRef)
+	return common.NewPullRequestCommentMetrics(generalMetrics, fmt.Sprintf("%d", event.Issue.Number))
+}
+
+func parseTime(s string) *time.Time {
+	// 2024-03-07T10:15:30+01:00
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,78 @@
+package gitea
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookProvider_gatherMetrics(t *testing.T) {
+	currentTime := time.Date(2024, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		event   interface{}
+		eventID string
+		appSlug string
+		want    string
+	}{
+		{
+			name:    "Push webhook",
+			event:   testEvent[PushEventModel](t, samplePushData),
+			eventID: "push",
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"pushed","provider_type":"gitea","repository":"gitea/webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","user_name":"gitea-user","git_ref":"refs/heads/main","commit_id_after":"bffeb74224043ba2feb48d137756c8a9331c449a","commit_id_before":"28e1879d029cb852e4844d9c718537df08844e03","oldest_commit_timestamp":"2024-03-07T10:15:30+01:00","latest_commit_timestamp":"2024-03-07T10:15:30+01:00","master_branch":"main","changed_files_count":1,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:    "Pull request sync webhook",
+			event:   testEvent[PullRequestEventModel](t, samplePullRequestData),
+			eventID: "pull_request_sync",
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"updated","provider_type":"gitea","repository":"gitea/webhooks","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30+01:00","app_slug":"slug","original_trigger":"pull_request_sync:synchronized","user_name":"gitea-user","git_ref":"feature","pull_request_title":"Add feature","pull_request_id":"2","pull_request_url":"https://gitea.example.com/gitea/webhooks/pulls/2","target_branch":"main","commit_id":"83b86e5f286f546dc5a4a58db66ceef44460c85e","changed_files_count":0,"addition_count":0,"deletion_count":0,"commit_count":0,"status":"open"}`,
+		},
+		{
+			name:    "Pull request comment webhook",
+			event:   &IssueCommentEventModel{Action: "created", IsPull: true, Issue: IssueModel{Number: 2}, Repo: RepoInfoModel{FullName: "gitea/webhooks"}, Sender: UserModel{Login: "commenter"}},
+			eventID: "pull_request_comment",
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"comment","provider_type":"gitea","repository":"gitea/webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"pull_request_comment:created","user_name":"commenter","pull_request_id":"2"}`,
+		},
+		{
+			name:    "Issue comment webhook",
+			event:   &IssueCommentEventModel{Action: "created", Issue: IssueModel{Number: 3}},
+			eventID: "issue_comment",
+			appSlug: "slug",
+			want:    "",
+		},
+		{
+			name:    "Unsupported webhook",
+			event:   &ReleaseEventModel{Action: "published"},
+			eventID: "release",
+			appSlug: "slug",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hp := HookProvider{}
+			got := hp.gatherMetrics(tt.event, tt.eventID, tt.appSlug, currentTime)
+			if tt.want != "" {
+				require.Equal(t, len(got), 1)
+				gotMetrics := got[0]
+				gotBytes, err := gotMetrics.Serialise()
+				require.NoError(t, err)
+				require.Equal(t, tt.want, string(gotBytes))
+			} else {
+				require.Nil(t, got)
+			}
+		})
+	}
+}
+
+func testEvent[T any](t *testing.T, payload string) *T {
+	var event T
+	require.NoError(t, json.Unmarshal([]byte(payload), &event))
+	return &event
+}
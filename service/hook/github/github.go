@@ -1,6 +1,7 @@
 package github
 
 import (
+	"crypto/sha1"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -80,9 +81,12 @@ type PullRequestInfoModel struct {
 	HeadBranchInfo BranchInfoModel `json:"head"`
 	// destination branch for the pull request
 	BaseBranchInfo BranchInfoModel  `json:"base"`
+	Number         int              `json:"number"`
 	Title          string           `json:"title"`
 	Body           string           `json:"body"`
+	State          string           `json:"state"`
 	Merged         bool             `json:"merged"`
+	MergedAt       string           `json:"merged_at"`
 	Mergeable      *bool            `json:"mergeable"`
 	Draft          bool             `json:"draft"`
 	DiffURL        string           `json:"diff_url"`
@@ -112,6 +116,25 @@ type PullRequestEventModel struct {
 	Sender          UserModel                   `json:"sender"`
 }
 
+// PullRequestReviewEventModel ...
+type PullRequestReviewEventModel struct {
+	Action          string               `json:"action"`
+	Review          ReviewInfoModel      `json:"review"`
+	PullRequestInfo PullRequestInfoModel `json:"pull_request"`
+	Repo            RepoInfoModel        `json:"repository"`
+	Sender          UserModel            `json:"sender"`
+}
+
+// ReviewInfoModel ...
+type ReviewInfoModel struct {
+	ID int64 `json:"id"`
+	// approved, changes_requested, commented or dismissed
+	State      string    `json:"state"`
+	Body       string    `json:"body"`
+	CommitHash string    `json:"commit_id"`
+	User       UserModel `json:"user"`
+}
+
 // IssueCommentEventModel ...
 type IssueCommentEventModel struct {
 	Action  string           `json:"action"`
@@ -123,8 +146,9 @@ type IssueCommentEventModel struct {
 
 // CommentInfoModel ...
 type CommentInfoModel struct {
-	ID   int64  `json:"id"`
-	Body string `json:"body"`
+	ID                int64  `json:"id"`
+	Body              string `json:"body"`
+	AuthorAssociation string `json:"author_association"`
 }
 
 // IssueInfoModel ...
@@ -148,6 +172,45 @@ type IssuePullRequestInfoModel struct {
 	MergedAt string `json:"merged_at"`
 }
 
+// ReleaseEventModel ...
+type ReleaseEventModel struct {
+	Action  string           `json:"action"`
+	Release ReleaseInfoModel `json:"release"`
+	Repo    RepoInfoModel    `json:"repository"`
+	Sender  UserModel        `json:"sender"`
+}
+
+// ReleaseInfoModel ...
+type ReleaseInfoModel struct {
+	TagName         string    `json:"tag_name"`
+	TargetCommitish string    `json:"target_commitish"`
+	Name            string    `json:"name"`
+	Body            string    `json:"body"`
+	Draft           bool      `json:"draft"`
+	Prerelease      bool      `json:"prerelease"`
+	HTMLURL         string    `json:"html_url"`
+	Author          UserModel `json:"author"`
+}
+
+// MergeGroupEventModel ...
+type MergeGroupEventModel struct {
+	Action     string              `json:"action"`
+	Reason     string              `json:"reason"`
+	MergeGroup MergeGroupInfoModel `json:"merge_group"`
+	Repo       RepoInfoModel       `json:"repository"`
+	Sender     UserModel           `json:"sender"`
+}
+
+// MergeGroupInfoModel ...
+type MergeGroupInfoModel struct {
+	// the ref of the merge queue branch, e.g. refs/heads/gh-readonly-queue/main/pr-12-<sha>
+	HeadRef    string      `json:"head_ref"`
+	HeadSHA    string      `json:"head_sha"`
+	BaseRef    string      `json:"base_ref"`
+	BaseSHA    string      `json:"base_sha"`
+	HeadCommit CommitModel `json:"head_commit"`
+}
+
 // ---------------------------------------
 // --- Webhook Provider Implementation ---
 
@@ -289,14 +352,8 @@ func transformPullRequestEvent(pullRequest PullRequestEventModel) hookCommon.Tra
 		}
 	}
 
-	headRefBuildParam := fmt.Sprintf("pull/%d/head", pullRequest.PullRequestID)
-	unverifiedMergeRefBuildParam := fmt.Sprintf("pull/%d/merge", pullRequest.PullRequestID)
 	// If `mergeable` is nil, the merge ref is not up-to-date, it's not safe to use for checkouts.
 	mergeRefUpToDate := pullRequest.PullRequestInfo.Mergeable != nil
-	var mergeRefBuildParam string
-	if mergeRefUpToDate {
-		mergeRefBuildParam = unverifiedMergeRefBuildParam
-	}
 	if mergeRefUpToDate && *pullRequest.PullRequestInfo.Mergeable == false {
 		return hookCommon.TransformResultModel{
 			Error:      errors.New("pull Request is not mergeable"),
@@ -304,48 +361,16 @@ func transformPullRequestEvent(pullRequest PullRequestEventModel) hookCommon.Tra
 		}
 	}
 
-	commitMsg := pullRequest.PullRequestInfo.Title
-	if pullRequest.PullRequestInfo.Body != "" {
-		commitMsg = fmt.Sprintf("%s\n\n%s", commitMsg, pullRequest.PullRequestInfo.Body)
-	}
-
-	buildEnvs := make([]bitriseapi.EnvironmentItem, 0)
-	if pullRequest.PullRequestInfo.Draft {
-		buildEnvs = append(buildEnvs, bitriseapi.EnvironmentItem{
-			Name:     "GITHUB_PR_IS_DRAFT",
-			Value:    strconv.FormatBool(pullRequest.PullRequestInfo.Draft),
-			IsExpand: false,
-		})
-	}
-
-	var labels []string
-	for _, label := range pullRequest.PullRequestInfo.Labels {
-		labels = append(labels, label.Name)
-	}
-
 	result := bitriseapi.TriggerAPIParamsModel{
-		BuildParams: bitriseapi.BuildParamsModel{
-			CommitMessage:                    commitMsg,
-			CommitHash:                       pullRequest.PullRequestInfo.HeadBranchInfo.CommitHash,
-			Branch:                           pullRequest.PullRequestInfo.HeadBranchInfo.Ref,
-			BranchRepoOwner:                  pullRequest.PullRequestInfo.HeadBranchInfo.Repo.Owner.Login,
-			BranchDest:                       pullRequest.PullRequestInfo.BaseBranchInfo.Ref,
-			BranchDestRepoOwner:              pullRequest.PullRequestInfo.BaseBranchInfo.Repo.Owner.Login,
-			PullRequestID:                    &pullRequest.PullRequestID,
-			BaseRepositoryURL:                pullRequest.PullRequestInfo.BaseBranchInfo.getRepositoryURL(),
-			HeadRepositoryURL:                pullRequest.PullRequestInfo.HeadBranchInfo.getRepositoryURL(),
-			PullRequestRepositoryURL:         pullRequest.PullRequestInfo.HeadBranchInfo.getRepositoryURL(),
-			PullRequestAuthor:                pullRequest.PullRequestInfo.User.Login,
-			PullRequestHeadBranch:            headRefBuildParam,
-			PullRequestMergeBranch:           mergeRefBuildParam,
-			PullRequestUnverifiedMergeBranch: unverifiedMergeRefBuildParam,
-			DiffURL:                          pullRequest.PullRequestInfo.DiffURL,
-			Environments:                     buildEnvs,
-			PullRequestReadyState:            pullRequestReadyState(pullRequest),
-			PullRequestLabels:                labels,
-		},
+		BuildParams: pullRequestBuildParams(pullRequest.PullRequestInfo, pullRequest.PullRequestID),
 		TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, pullRequest.Sender.Login),
 	}
+	if mergeRefUpToDate {
+		result.BuildParams.PullRequestMergeBranch = result.BuildParams.PullRequestUnverifiedMergeBranch
+	}
+	if pullRequest.Action == "ready_for_review" {
+		result.BuildParams.PullRequestReadyState = bitriseapi.PullRequestReadyStateConvertedToReadyForReview
+	}
 
 	if pullRequest.Label != nil {
 		result.BuildParams.PullRequestLabelsAdded = []string{pullRequest.Label.Name}
@@ -360,15 +385,70 @@ func transformPullRequestEvent(pullRequest PullRequestEventModel) hookCommon.Tra
 	}
 }
 
-func pullRequestReadyState(pullRequest PullRequestEventModel) bitriseapi.PullRequestReadyState {
-	switch {
-	case pullRequest.Action == "ready_for_review":
-		return bitriseapi.PullRequestReadyStateConvertedToReadyForReview
-	case pullRequest.PullRequestInfo.Draft:
+// pullRequestBuildParams returns the build parameters of a pull request,
+// the merge branch is left empty as only the pull request events tell whether the merge ref is up-to-date.
+func pullRequestBuildParams(pullRequest PullRequestInfoModel, pullRequestID int) bitriseapi.BuildParamsModel {
+	return bitriseapi.BuildParamsModel{
+		CommitMessage:                    pullRequestCommitMessage(pullRequest.Title, pullRequest.Body),
+		CommitHash:                       pullRequest.HeadBranchInfo.CommitHash,
+		Branch:                           pullRequest.HeadBranchInfo.Ref,
+		BranchRepoOwner:                  pullRequest.HeadBranchInfo.Repo.Owner.Login,
+		BranchDest:                       pullRequest.BaseBranchInfo.Ref,
+		BranchDestRepoOwner:              pullRequest.BaseBranchInfo.Repo.Owner.Login,
+		PullRequestID:                    &pullRequestID,
+		BaseRepositoryURL:                pullRequest.BaseBranchInfo.getRepositoryURL(),
+		HeadRepositoryURL:                pullRequest.HeadBranchInfo.getRepositoryURL(),
+		PullRequestRepositoryURL:         pullRequest.HeadBranchInfo.getRepositoryURL(),
+		PullRequestAuthor:                pullRequest.User.Login,
+		PullRequestHeadBranch:            pullRequestHeadBranch(pullRequestID),
+		PullRequestUnverifiedMergeBranch: pullRequestMergeBranch(pullRequestID),
+		DiffURL:                          pullRequest.DiffURL,
+		Environments:                     draftEnvironments(pullRequest.Draft),
+		PullRequestReadyState:            draftReadyState(pullRequest.Draft),
+		PullRequestLabels:                labelNames(pullRequest.Labels),
+	}
+}
+
+func pullRequestHeadBranch(pullRequestID int) string {
+	return fmt.Sprintf("pull/%d/head", pullRequestID)
+}
+
+func pullRequestMergeBranch(pullRequestID int) string {
+	return fmt.Sprintf("pull/%d/merge", pullRequestID)
+}
+
+func pullRequestCommitMessage(title, body string) string {
+	if body == "" {
+		return title
+	}
+	return fmt.Sprintf("%s\n\n%s", title, body)
+}
+
+func draftEnvironments(isDraft bool) []bitriseapi.EnvironmentItem {
+	buildEnvs := make([]bitriseapi.EnvironmentItem, 0)
+	if isDraft {
+		buildEnvs = append(buildEnvs, bitriseapi.EnvironmentItem{
+			Name:     "GITHUB_PR_IS_DRAFT",
+			Value:    strconv.FormatBool(isDraft),
+			IsExpand: false,
+		})
+	}
+	return buildEnvs
+}
+
+func draftReadyState(isDraft bool) bitriseapi.PullRequestReadyState {
+	if isDraft {
 		return bitriseapi.PullRequestReadyStateDraft
-	default:
-		return bitriseapi.PullRequestReadyStateReadyForReview
 	}
+	return bitriseapi.PullRequestReadyStateReadyForReview
+}
+
+func labelNames(labels []LabelInfoModel) []string {
+	var names []string
+	for _, label := range labels {
+		names = append(names, label.Name)
+	}
+	return names
 }
 
 func isAcceptIssueCommentAction(action string) bool {
@@ -419,61 +499,186 @@ func transformIssueCommentEvent(eventModel IssueCommentEventModel) hookCommon.Tr
 
 	// NOTE: we cannot do the other PR checks (see transformPullRequestEvent mergeability conditions) because the payload doesn't have enough data
 
-	headRefBuildParam := fmt.Sprintf("pull/%d/head", issue.PullRequestID)
-	unverifiedMergeRefBuildParam := fmt.Sprintf("pull/%d/merge", issue.PullRequestID)
+	result := bitriseapi.TriggerAPIParamsModel{
+		BuildParams: bitriseapi.BuildParamsModel{
+			CommitMessage:                       pullRequestCommitMessage(issue.Title, issue.Body),
+			BranchDestRepoOwner:                 eventModel.Repo.Owner.Login,
+			PullRequestID:                       &issue.PullRequestID,
+			HeadRepositoryURL:                   eventModel.Repo.getRepositoryURL(),
+			PullRequestRepositoryURL:            eventModel.Repo.getRepositoryURL(),
+			PullRequestAuthor:                   issue.User.Login,
+			PullRequestHeadBranch:               pullRequestHeadBranch(issue.PullRequestID),
+			PullRequestUnverifiedMergeBranch:    pullRequestMergeBranch(issue.PullRequestID),
+			DiffURL:                             pullRequest.DiffURL,
+			Environments:                        draftEnvironments(issue.Draft),
+			PullRequestReadyState:               draftReadyState(issue.Draft),
+			PullRequestLabels:                   labelNames(issue.Labels),
+			PullRequestComment:                  eventModel.Comment.Body,
+			PullRequestCommentID:                strconv.FormatInt(eventModel.Comment.ID, 10),
+			PullRequestCommentAuthorAssociation: eventModel.Comment.AuthorAssociation,
+		},
+		TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, eventModel.Sender.Login),
+	}
 
-	commitMsg := issue.Title
-	if issue.Body != "" {
-		commitMsg = fmt.Sprintf("%s\n\n%s", commitMsg, issue.Body)
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			result,
+		},
+		SkippedByPrDescription: !hookCommon.ContainsSkipInstruction(issue.Title) &&
+			hookCommon.ContainsSkipInstruction(issue.Body),
 	}
+}
 
-	buildEnvs := make([]bitriseapi.EnvironmentItem, 0)
-	if issue.Draft {
-		buildEnvs = append(buildEnvs, bitriseapi.EnvironmentItem{
-			Name:     "GITHUB_PR_IS_DRAFT",
-			Value:    strconv.FormatBool(issue.Draft),
-			IsExpand: false,
-		})
+func isAcceptPullRequestReviewState(state string) bool {
+	return slices.Contains([]string{"approved", "changes_requested"}, state)
+}
+
+func transformPullRequestReviewEvent(eventModel PullRequestReviewEventModel) hookCommon.TransformResultModel {
+	if eventModel.Action != "submitted" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("pull request review action doesn't require a build: %s", eventModel.Action),
+			ShouldSkip: true,
+		}
 	}
 
-	var readyState bitriseapi.PullRequestReadyState
-	if issue.Draft {
-		readyState = bitriseapi.PullRequestReadyStateDraft
-	} else {
-		readyState = bitriseapi.PullRequestReadyStateReadyForReview
+	// the review state is lowercase in webhook payloads, but uppercase in the REST API
+	reviewState := strings.ToLower(eventModel.Review.State)
+	if !isAcceptPullRequestReviewState(reviewState) {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("pull request review state doesn't require a build: %s", reviewState),
+			ShouldSkip: true,
+		}
 	}
 
-	var labels []string
-	for _, label := range issue.Labels {
-		labels = append(labels, label.Name)
+	pullRequest := eventModel.PullRequestInfo
+	if pullRequest.State != "open" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("pull request review is for a pull request that is not open: %s", pullRequest.State),
+			ShouldSkip: true,
+		}
 	}
+	if pullRequest.Merged || pullRequest.MergedAt != "" {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("pull request review is for a pull request that is already merged"),
+			ShouldSkip: true,
+		}
+	}
+
+	// NOTE: the pull request of a review payload has no `mergeable` field, so the merge ref can't be verified
+	// (see transformPullRequestEvent mergeability conditions)
 
 	result := bitriseapi.TriggerAPIParamsModel{
-		BuildParams: bitriseapi.BuildParamsModel{
-			CommitMessage:                    commitMsg,
-			BranchDestRepoOwner:              eventModel.Repo.Owner.Login,
-			PullRequestID:                    &issue.PullRequestID,
-			HeadRepositoryURL:                eventModel.Repo.getRepositoryURL(),
-			PullRequestRepositoryURL:         eventModel.Repo.getRepositoryURL(),
-			PullRequestAuthor:                issue.User.Login,
-			PullRequestHeadBranch:            headRefBuildParam,
-			PullRequestUnverifiedMergeBranch: unverifiedMergeRefBuildParam,
-			DiffURL:                          pullRequest.DiffURL,
-			Environments:                     buildEnvs,
-			PullRequestReadyState:            readyState,
-			PullRequestLabels:                labels,
-			PullRequestComment:               eventModel.Comment.Body,
-			PullRequestCommentID:             strconv.FormatInt(eventModel.Comment.ID, 10),
-		},
+		BuildParams: pullRequestBuildParams(pullRequest, pullRequest.Number),
 		TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, eventModel.Sender.Login),
 	}
+	result.BuildParams.Environments = append([]bitriseapi.EnvironmentItem{
+		{Name: "GITHUB_PR_REVIEW_STATE", Value: reviewState, IsExpand: false},
+		{Name: "GITHUB_PR_REVIEWER", Value: eventModel.Review.User.Login, IsExpand: false},
+	}, result.BuildParams.Environments...)
 
 	return hookCommon.TransformResultModel{
 		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
 			result,
 		},
-		SkippedByPrDescription: !hookCommon.ContainsSkipInstruction(issue.Title) &&
-			hookCommon.ContainsSkipInstruction(issue.Body),
+		SkippedByPrDescription: !hookCommon.ContainsSkipInstruction(pullRequest.Title) &&
+			hookCommon.ContainsSkipInstruction(pullRequest.Body),
+	}
+}
+
+func transformMergeGroupEvent(eventModel MergeGroupEventModel) hookCommon.TransformResultModel {
+	if eventModel.Action != "checks_requested" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("merge group action doesn't require a build: %s", eventModel.Action),
+			ShouldSkip: true,
+		}
+	}
+
+	mergeGroup := eventModel.MergeGroup
+	if !strings.HasPrefix(mergeGroup.HeadRef, "refs/heads/") {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("merge group head ref (%s) is not a head ref", mergeGroup.HeadRef),
+		}
+	}
+	if mergeGroup.HeadSHA == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing commit hash"),
+		}
+	}
+
+	// The merge queue branch is built like a pushed branch, as it has no pull request:
+	// it includes the changes of the queued pull request(s), merged into the base branch.
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Branch:            strings.TrimPrefix(mergeGroup.HeadRef, "refs/heads/"),
+					CommitHash:        mergeGroup.HeadSHA,
+					CommitMessage:     mergeGroup.HeadCommit.CommitMessage,
+					BaseRepositoryURL: eventModel.Repo.getRepositoryURL(),
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_MERGE_GROUP_HEAD_REF", Value: mergeGroup.HeadRef, IsExpand: false},
+						{Name: "GITHUB_MERGE_GROUP_HEAD_SHA", Value: mergeGroup.HeadSHA, IsExpand: false},
+						{Name: "GITHUB_MERGE_GROUP_BASE_REF", Value: strings.TrimPrefix(mergeGroup.BaseRef, "refs/heads/"), IsExpand: false},
+						{Name: "GITHUB_MERGE_GROUP_BASE_SHA", Value: mergeGroup.BaseSHA, IsExpand: false},
+					},
+				},
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, eventModel.Sender.Login),
+			},
+		},
+	}
+}
+
+func transformReleaseEvent(eventModel ReleaseEventModel) hookCommon.TransformResultModel {
+	release := eventModel.Release
+	// GitHub sends both a `published` and a `prereleased` action when a pre-release is published,
+	// so that a pre-release is built only once, on its `prereleased` action.
+	switch {
+	case eventModel.Action == "published" && release.Prerelease:
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("release is a pre-release, it is built on the prereleased action"),
+			ShouldSkip: true,
+		}
+	case eventModel.Action != "published" && eventModel.Action != "prereleased":
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("release action doesn't require a build: %s", eventModel.Action),
+			ShouldSkip: true,
+		}
+	}
+	if release.Draft {
+		return hookCommon.TransformResultModel{
+			Error:      errors.New("release is a draft"),
+			ShouldSkip: true,
+		}
+	}
+	if release.TagName == "" {
+		return hookCommon.TransformResultModel{
+			Error: errors.New("missing release tag name"),
+		}
+	}
+
+	commitMsg := release.Name
+	if commitMsg == "" {
+		commitMsg = release.TagName
+	}
+
+	return hookCommon.TransformResultModel{
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               release.TagName,
+					CommitMessage:     commitMsg,
+					BaseRepositoryURL: eventModel.Repo.getRepositoryURL(),
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_RELEASE_NAME", Value: release.Name, IsExpand: false},
+						{Name: "GITHUB_RELEASE_BODY", Value: release.Body, IsExpand: false},
+						{Name: "GITHUB_RELEASE_IS_PRERELEASE", Value: strconv.FormatBool(release.Prerelease), IsExpand: false},
+						{Name: "GITHUB_RELEASE_TARGET", Value: release.TargetCommitish, IsExpand: false},
+						{Name: "GITHUB_RELEASE_URL", Value: release.HTMLURL, IsExpand: false},
+					},
+				},
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, eventModel.Sender.Login),
+			},
+		},
 	}
 }
 
@@ -512,7 +717,7 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 			ShouldSkip: true,
 		}
 	}
-	if ghEvent != "push" && ghEvent != "pull_request" && ghEvent != "issue_comment" {
+	if !slices.Contains([]string{"push", "pull_request", "pull_request_review", "issue_comment", "merge_group", "release"}, ghEvent) {
 		// Unsupported GitHub Event
 		return hookCommon.TransformResultModel{
 			Error: fmt.Errorf("unsupported GitHub Webhook event: %s", ghEvent),
@@ -539,6 +744,13 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 		}
 
 		return transformPullRequestEvent(*eventModel)
+	} else if ghEvent == "pull_request_review" {
+		eventModel, err := decodeEventPayload[PullRequestReviewEventModel](r, contentType)
+		if err != nil {
+			return hookCommon.TransformResultModel{Error: err}
+		}
+
+		return transformPullRequestReviewEvent(*eventModel)
 	} else if ghEvent == "issue_comment" {
 		eventModel, err := decodeEventPayload[IssueCommentEventModel](r, contentType)
 		if err != nil {
@@ -546,6 +758,20 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 		}
 
 		return transformIssueCommentEvent(*eventModel)
+	} else if ghEvent == "merge_group" {
+		eventModel, err := decodeEventPayload[MergeGroupEventModel](r, contentType)
+		if err != nil {
+			return hookCommon.TransformResultModel{Error: err}
+		}
+
+		return transformMergeGroupEvent(*eventModel)
+	} else if ghEvent == "release" {
+		eventModel, err := decodeEventPayload[ReleaseEventModel](r, contentType)
+		if err != nil {
+			return hookCommon.TransformResultModel{Error: err}
+		}
+
+		return transformReleaseEvent(*eventModel)
 	}
 
 	return hookCommon.TransformResultModel{
@@ -553,6 +779,25 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 	}
 }
 
+// DeliveryID ...
+// GitHub sends the same X-GitHub-Delivery GUID for the redeliveries of a webhook.
+func (hp HookProvider) DeliveryID(header http.Header) string {
+	return header.Get("X-GitHub-Delivery")
+}
+
+// VerifySignature ...
+// GitHub signs the payload with the webhook's secret, and sends the signature
+// in the X-Hub-Signature-256 header (sha256) and in the legacy X-Hub-Signature header (sha1).
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	if header.Get("X-Hub-Signature-256") != "" {
+		return hookCommon.VerifySHA256SignatureHeader(header, "X-Hub-Signature-256", body, secret)
+	}
+	if signature := header.Get("X-Hub-Signature"); signature != "" {
+		return hookCommon.VerifyHMACSignature(sha1.New, secret, body, strings.TrimPrefix(signature, "sha1="))
+	}
+	return fmt.Errorf("%w: no X-Hub-Signature-256 or X-Hub-Signature header found", hookCommon.ErrMissingSignature)
+}
+
 func decodeEventPayload[T interface{}](r *http.Request, contentType string) (*T, error) {
 	var eventModel T
 	if contentType == hookCommon.ContentTypeApplicationJSON {
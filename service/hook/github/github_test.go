@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
 )
 
 const (
@@ -667,6 +668,147 @@ const (
     "id": 517812
   }
 }`
+
+	sampleMergeGroupData = `{
+  "action": "checks_requested",
+  "merge_group": {
+    "head_sha": "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+    "head_ref": "refs/heads/gh-readonly-queue/main/pr-4-f9f9d9e2a0a1c5f0b2c4a1e2d3f4a5b6c7d8e9f0",
+    "base_sha": "f9f9d9e2a0a1c5f0b2c4a1e2d3f4a5b6c7d8e9f0",
+    "base_ref": "refs/heads/main",
+    "head_commit": {
+      "id": "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+      "tree_id": "31b122c26a97cf9af023e9ddab94a82c6e77b0ea",
+      "message": "Merge pull request #4 from test_user/feature\n\nnew PR",
+      "timestamp": "2024-04-04T11:48:35Z"
+    }
+  },
+  "repository": {
+    "id": 769121357,
+    "name": "webhook-test",
+    "full_name": "test_user/webhook-test",
+    "private": true,
+    "owner": {
+      "login": "test_user",
+      "id": 517812
+    },
+    "ssh_url": "git@github.com:test_user/webhook-test.git",
+    "clone_url": "https://github.com/test_user/webhook-test.git"
+  },
+  "sender": {
+    "login": "test_user",
+    "id": 517812
+  }
+}`
+
+	samplePullRequestReviewApprovedData = `{
+  "action": "submitted",
+  "review": {
+    "id": 1978394611,
+    "user": {
+      "login": "reviewer_user",
+      "id": 517813
+    },
+    "body": "LGTM",
+    "commit_id": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+    "submitted_at": "2024-04-04T12:01:10Z",
+    "state": "approved"
+  },
+  "pull_request": {
+    "number": 4,
+    "state": "open",
+    "title": "new PR",
+    "body": "Very detailed description of a pull request.",
+    "draft": false,
+    "merged_at": null,
+    "diff_url": "https://github.com/test_user/webhook-test/pull/4.diff",
+    "user": {
+      "login": "test_user",
+      "id": 517812
+    },
+    "labels": [
+      {
+        "id": 6813472651,
+        "name": "ui-tests"
+      }
+    ],
+    "head": {
+      "ref": "feature/login",
+      "sha": "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+      "repo": {
+        "private": false,
+        "owner": {
+          "login": "test_user"
+        },
+        "ssh_url": "git@github.com:test_user/webhook-test.git",
+        "clone_url": "https://github.com/test_user/webhook-test.git"
+      }
+    },
+    "base": {
+      "ref": "main",
+      "sha": "bffeb74224043ba2feb48d137756c8a9331c449a",
+      "repo": {
+        "private": false,
+        "owner": {
+          "login": "test_user"
+        },
+        "ssh_url": "git@github.com:test_user/webhook-test.git",
+        "clone_url": "https://github.com/test_user/webhook-test.git"
+      }
+    }
+  },
+  "repository": {
+    "name": "webhook-test",
+    "full_name": "test_user/webhook-test",
+    "private": false,
+    "owner": {
+      "login": "test_user"
+    },
+    "ssh_url": "git@github.com:test_user/webhook-test.git",
+    "clone_url": "https://github.com/test_user/webhook-test.git"
+  },
+  "sender": {
+    "login": "reviewer_user",
+    "id": 517813
+  }
+}`
+
+	sampleReleasePublishedData = `{
+  "action": "published",
+  "release": {
+    "url": "https://api.github.com/repos/test_user/webhook-test/releases/150939999",
+    "html_url": "https://github.com/test_user/webhook-test/releases/tag/v1.2.0",
+    "id": 150939999,
+    "tag_name": "v1.2.0",
+    "target_commitish": "main",
+    "name": "Version 1.2.0",
+    "draft": false,
+    "prerelease": false,
+    "created_at": "2024-04-04T11:48:35Z",
+    "published_at": "2024-04-04T11:50:00Z",
+    "body": "Release notes",
+    "author": {
+      "login": "test_user",
+      "id": 517812
+    }
+  },
+  "repository": {
+    "id": 769121357,
+    "name": "webhook-test",
+    "full_name": "test_user/webhook-test",
+    "private": false,
+    "owner": {
+      "login": "test_user",
+      "id": 517812
+    },
+    "ssh_url": "git@github.com:test_user/webhook-test.git",
+    "clone_url": "https://github.com/test_user/webhook-test.git"
+  },
+  "sender": {
+    "login": "test_user",
+    "id": 517812
+  }
+}`
 )
 
 var boolFalse = false
@@ -1714,6 +1856,218 @@ func Test_transformIssueCommentEvent(t *testing.T) {
 	}
 }
 
+func Test_transformPullRequestReviewEvent(t *testing.T) {
+	t.Log("Unsupported review action")
+	{
+		event := PullRequestReviewEventModel{
+			Action: "dismissed",
+		}
+		hookTransformResult := transformPullRequestReviewEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull request review action doesn't require a build: dismissed")
+	}
+
+	t.Log("Comment only review")
+	{
+		event := PullRequestReviewEventModel{
+			Action: "submitted",
+			Review: ReviewInfoModel{State: "commented"},
+		}
+		hookTransformResult := transformPullRequestReviewEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull request review state doesn't require a build: commented")
+	}
+
+	t.Log("PR not open")
+	{
+		event := PullRequestReviewEventModel{
+			Action:          "submitted",
+			Review:          ReviewInfoModel{State: "approved"},
+			PullRequestInfo: PullRequestInfoModel{State: "closed"},
+		}
+		hookTransformResult := transformPullRequestReviewEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull request review is for a pull request that is not open: closed")
+	}
+
+	t.Log("PR already merged")
+	{
+		event := PullRequestReviewEventModel{
+			Action: "submitted",
+			Review: ReviewInfoModel{State: "approved"},
+			PullRequestInfo: PullRequestInfoModel{
+				State:    "open",
+				MergedAt: "2024-04-01T01:23:45Z",
+			},
+		}
+		hookTransformResult := transformPullRequestReviewEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "pull request review is for a pull request that is already merged")
+	}
+
+	t.Log("Changes requested on a draft PR - uppercase state")
+	{
+		event := PullRequestReviewEventModel{
+			Action: "submitted",
+			Review: ReviewInfoModel{
+				State: "CHANGES_REQUESTED",
+				User:  UserModel{Login: "reviewer_user"},
+			},
+			PullRequestInfo: PullRequestInfoModel{
+				Number: 12,
+				State:  "open",
+				Title:  "PR title",
+				Draft:  true,
+				HeadBranchInfo: BranchInfoModel{
+					Ref:        "feature/login",
+					CommitHash: "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+				},
+				BaseBranchInfo: BranchInfoModel{Ref: "main"},
+				User:           UserModel{Login: "test_user"},
+			},
+			Sender: UserModel{Login: "reviewer_user"},
+		}
+		hookTransformResult := transformPullRequestReviewEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitMessage:                    "PR title",
+					CommitHash:                       "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+					Branch:                           "feature/login",
+					BranchDest:                       "main",
+					PullRequestID:                    &intTwelve,
+					PullRequestAuthor:                "test_user",
+					PullRequestHeadBranch:            "pull/12/head",
+					PullRequestUnverifiedMergeBranch: "pull/12/merge",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_PR_REVIEW_STATE", Value: "changes_requested", IsExpand: false},
+						{Name: "GITHUB_PR_REVIEWER", Value: "reviewer_user", IsExpand: false},
+						{Name: "GITHUB_PR_IS_DRAFT", Value: "true", IsExpand: false},
+					},
+					PullRequestReadyState: bitriseapi.PullRequestReadyStateDraft,
+				},
+				TriggeredBy: "webhook-github/reviewer_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+}
+
+func Test_transformMergeGroupEvent(t *testing.T) {
+	t.Log("Destroyed merge group - should be skipped")
+	{
+		event := MergeGroupEventModel{
+			Action: "destroyed",
+			Reason: "merged",
+		}
+		hookTransformResult := transformMergeGroupEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "merge group action doesn't require a build: destroyed")
+	}
+
+	t.Log("Not a head ref")
+	{
+		event := MergeGroupEventModel{
+			Action:     "checks_requested",
+			MergeGroup: MergeGroupInfoModel{HeadRef: "gh-readonly-queue/main/pr-4", HeadSHA: "ec26c3e57ca3a959ca5aad62de7213c562f8c821"},
+		}
+		hookTransformResult := transformMergeGroupEvent(event)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "merge group head ref (gh-readonly-queue/main/pr-4) is not a head ref")
+	}
+
+	t.Log("Missing head SHA")
+	{
+		event := MergeGroupEventModel{
+			Action:     "checks_requested",
+			MergeGroup: MergeGroupInfoModel{HeadRef: "refs/heads/gh-readonly-queue/main/pr-4"},
+		}
+		hookTransformResult := transformMergeGroupEvent(event)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "missing commit hash")
+	}
+}
+
+func Test_transformReleaseEvent(t *testing.T) {
+	t.Log("Pre-release :: prereleased - should be handled")
+	{
+		event := ReleaseEventModel{
+			Action: "prereleased",
+			Release: ReleaseInfoModel{
+				TagName:         "v1.2.0-beta.1",
+				TargetCommitish: "main",
+				Prerelease:      true,
+				HTMLURL:         "https://github.com/test_user/webhook-test/releases/tag/v1.2.0-beta.1",
+			},
+			Sender: UserModel{Login: "test_user"},
+		}
+		hookTransformResult := transformReleaseEvent(event)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:           "v1.2.0-beta.1",
+					CommitMessage: "v1.2.0-beta.1",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_RELEASE_NAME", Value: "", IsExpand: false},
+						{Name: "GITHUB_RELEASE_BODY", Value: "", IsExpand: false},
+						{Name: "GITHUB_RELEASE_IS_PRERELEASE", Value: "true", IsExpand: false},
+						{Name: "GITHUB_RELEASE_TARGET", Value: "main", IsExpand: false},
+						{Name: "GITHUB_RELEASE_URL", Value: "https://github.com/test_user/webhook-test/releases/tag/v1.2.0-beta.1", IsExpand: false},
+					},
+				},
+				TriggeredBy: "webhook-github/test_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Pre-release :: published - should be skipped")
+	{
+		event := ReleaseEventModel{
+			Action:  "published",
+			Release: ReleaseInfoModel{TagName: "v1.2.0-beta.1", Prerelease: true},
+		}
+		hookTransformResult := transformReleaseEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "release is a pre-release, it is built on the prereleased action")
+	}
+
+	t.Log("Unsupported release action - should be skipped")
+	{
+		for _, action := range []string{"created", "edited", "deleted", "released", "unpublished"} {
+			event := ReleaseEventModel{
+				Action:  action,
+				Release: ReleaseInfoModel{TagName: "v1.2.0"},
+			}
+			hookTransformResult := transformReleaseEvent(event)
+			require.True(t, hookTransformResult.ShouldSkip)
+			require.EqualError(t, hookTransformResult.Error, "release action doesn't require a build: "+action)
+		}
+	}
+
+	t.Log("Draft - should be skipped")
+	{
+		event := ReleaseEventModel{
+			Action:  "published",
+			Release: ReleaseInfoModel{TagName: "v1.2.0", Draft: true},
+		}
+		hookTransformResult := transformReleaseEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "release is a draft")
+	}
+
+	t.Log("Missing tag name")
+	{
+		event := ReleaseEventModel{
+			Action: "published",
+		}
+		hookTransformResult := transformReleaseEvent(event)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "missing release tag name")
+	}
+}
+
 func Test_isAcceptPullRequestAction(t *testing.T) {
 	t.Log("Accept")
 	{
@@ -2081,20 +2435,21 @@ func Test_HookProvider_TransformRequest(t *testing.T) {
 		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
 			{
 				BuildParams: bitriseapi.BuildParamsModel{
-					CommitMessage:                    "new PR\n\nVery detailed description of a pull request.",
-					BranchDestRepoOwner:              "test_user",
-					PullRequestID:                    &intFour,
-					PullRequestRepositoryURL:         "git@github.com:test_user/webhook-test.git",
-					HeadRepositoryURL:                "git@github.com:test_user/webhook-test.git",
-					PullRequestAuthor:                "test_user",
-					PullRequestUnverifiedMergeBranch: "pull/4/merge",
-					PullRequestHeadBranch:            "pull/4/head",
-					DiffURL:                          "https://github.com/test_user/webhook-test/pull/4.diff",
-					Environments:                     make([]bitriseapi.EnvironmentItem, 0),
-					PullRequestReadyState:            bitriseapi.PullRequestReadyStateReadyForReview,
-					PullRequestLabels:                []string{"trigger-other"},
-					PullRequestComment:               "first comment",
-					PullRequestCommentID:             "2036438149",
+					CommitMessage:                       "new PR\n\nVery detailed description of a pull request.",
+					BranchDestRepoOwner:                 "test_user",
+					PullRequestID:                       &intFour,
+					PullRequestRepositoryURL:            "git@github.com:test_user/webhook-test.git",
+					HeadRepositoryURL:                   "git@github.com:test_user/webhook-test.git",
+					PullRequestAuthor:                   "test_user",
+					PullRequestUnverifiedMergeBranch:    "pull/4/merge",
+					PullRequestHeadBranch:               "pull/4/head",
+					DiffURL:                             "https://github.com/test_user/webhook-test/pull/4.diff",
+					Environments:                        make([]bitriseapi.EnvironmentItem, 0),
+					PullRequestReadyState:               bitriseapi.PullRequestReadyStateReadyForReview,
+					PullRequestLabels:                   []string{"trigger-other"},
+					PullRequestComment:                  "first comment",
+					PullRequestCommentID:                "2036438149",
+					PullRequestCommentAuthorAssociation: "OWNER",
 				},
 				TriggeredBy: "webhook-github/test_user",
 			},
@@ -2114,28 +2469,132 @@ func Test_HookProvider_TransformRequest(t *testing.T) {
 		hookTransformResult := provider.TransformRequest(&request)
 		require.NoError(t, hookTransformResult.Error)
 		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitMessage:                       "new PR\n\nVery detailed description of a pull request.",
+					BranchDestRepoOwner:                 "test_user",
+					PullRequestID:                       &intFour,
+					PullRequestRepositoryURL:            "git@github.com:test_user/webhook-test.git",
+					HeadRepositoryURL:                   "git@github.com:test_user/webhook-test.git",
+					PullRequestAuthor:                   "test_user",
+					PullRequestUnverifiedMergeBranch:    "pull/4/merge",
+					PullRequestHeadBranch:               "pull/4/head",
+					DiffURL:                             "https://github.com/test_user/webhook-test/pull/4.diff",
+					Environments:                        make([]bitriseapi.EnvironmentItem, 0),
+					PullRequestReadyState:               bitriseapi.PullRequestReadyStateReadyForReview,
+					PullRequestLabels:                   []string{"trigger-other"},
+					PullRequestComment:                  "I have a much better idea for a comment now.",
+					PullRequestCommentID:                "2036438149",
+					PullRequestCommentAuthorAssociation: "OWNER",
+				},
+				TriggeredBy: "webhook-github/test_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+		require.Equal(t, false, hookTransformResult.DontWaitForTriggerResponse)
+	}
+
+	t.Log("Merge group :: checks requested - should be handled")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"X-Github-Event": {"merge_group"},
+				"Content-Type":   {"application/json"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(sampleMergeGroupData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Branch:            "gh-readonly-queue/main/pr-4-f9f9d9e2a0a1c5f0b2c4a1e2d3f4a5b6c7d8e9f0",
+					CommitHash:        "ec26c3e57ca3a959ca5aad62de7213c562f8c821",
+					CommitMessage:     "Merge pull request #4 from test_user/feature\n\nnew PR",
+					BaseRepositoryURL: "git@github.com:test_user/webhook-test.git",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_MERGE_GROUP_HEAD_REF", Value: "refs/heads/gh-readonly-queue/main/pr-4-f9f9d9e2a0a1c5f0b2c4a1e2d3f4a5b6c7d8e9f0", IsExpand: false},
+						{Name: "GITHUB_MERGE_GROUP_HEAD_SHA", Value: "ec26c3e57ca3a959ca5aad62de7213c562f8c821", IsExpand: false},
+						{Name: "GITHUB_MERGE_GROUP_BASE_REF", Value: "main", IsExpand: false},
+						{Name: "GITHUB_MERGE_GROUP_BASE_SHA", Value: "f9f9d9e2a0a1c5f0b2c4a1e2d3f4a5b6c7d8e9f0", IsExpand: false},
+					},
+				},
+				TriggeredBy: "webhook-github/test_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Pull Request :: review approved - should be handled")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"X-Github-Event": {"pull_request_review"},
+				"Content-Type":   {"application/json"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(samplePullRequestReviewApprovedData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
 		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
 			{
 				BuildParams: bitriseapi.BuildParamsModel{
 					CommitMessage:                    "new PR\n\nVery detailed description of a pull request.",
+					CommitHash:                       "83b86e5f286f546dc5a4a58db66ceef44460c85e",
+					Branch:                           "feature/login",
+					BranchRepoOwner:                  "test_user",
+					BranchDest:                       "main",
 					BranchDestRepoOwner:              "test_user",
 					PullRequestID:                    &intFour,
-					PullRequestRepositoryURL:         "git@github.com:test_user/webhook-test.git",
-					HeadRepositoryURL:                "git@github.com:test_user/webhook-test.git",
+					BaseRepositoryURL:                "https://github.com/test_user/webhook-test.git",
+					HeadRepositoryURL:                "https://github.com/test_user/webhook-test.git",
+					PullRequestRepositoryURL:         "https://github.com/test_user/webhook-test.git",
 					PullRequestAuthor:                "test_user",
-					PullRequestUnverifiedMergeBranch: "pull/4/merge",
 					PullRequestHeadBranch:            "pull/4/head",
+					PullRequestUnverifiedMergeBranch: "pull/4/merge",
 					DiffURL:                          "https://github.com/test_user/webhook-test/pull/4.diff",
-					Environments:                     make([]bitriseapi.EnvironmentItem, 0),
-					PullRequestReadyState:            bitriseapi.PullRequestReadyStateReadyForReview,
-					PullRequestLabels:                []string{"trigger-other"},
-					PullRequestComment:               "I have a much better idea for a comment now.",
-					PullRequestCommentID:             "2036438149",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_PR_REVIEW_STATE", Value: "approved", IsExpand: false},
+						{Name: "GITHUB_PR_REVIEWER", Value: "reviewer_user", IsExpand: false},
+					},
+					PullRequestReadyState: bitriseapi.PullRequestReadyStateReadyForReview,
+					PullRequestLabels:     []string{"ui-tests"},
+				},
+				TriggeredBy: "webhook-github/reviewer_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("Release :: published - should be handled")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"X-Github-Event": {"release"},
+				"Content-Type":   {"application/json"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(sampleReleasePublishedData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               "v1.2.0",
+					CommitMessage:     "Version 1.2.0",
+					BaseRepositoryURL: "https://github.com/test_user/webhook-test.git",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITHUB_RELEASE_NAME", Value: "Version 1.2.0", IsExpand: false},
+						{Name: "GITHUB_RELEASE_BODY", Value: "Release notes", IsExpand: false},
+						{Name: "GITHUB_RELEASE_IS_PRERELEASE", Value: "false", IsExpand: false},
+						{Name: "GITHUB_RELEASE_TARGET", Value: "main", IsExpand: false},
+						{Name: "GITHUB_RELEASE_URL", Value: "https://github.com/test_user/webhook-test/releases/tag/v1.2.0", IsExpand: false},
+					},
 				},
 				TriggeredBy: "webhook-github/test_user",
 			},
 		}, hookTransformResult.TriggerAPIParams)
-		require.Equal(t, false, hookTransformResult.DontWaitForTriggerResponse)
 	}
 }
 
@@ -2194,3 +2653,57 @@ func Test_transformPullRequestEvent_readyState(t *testing.T) {
 		})
 	}
 }
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	// the signature check itself is tested by hookCommon.VerifySHA256SignatureHeader, only the headers are tested here
+	provider := HookProvider{}
+	body := []byte(`{"ref":"refs/heads/master"}`)
+
+	tests := []struct {
+		name    string
+		header  http.Header
+		secret  string
+		wantErr error
+	}{
+		{
+			name:   "Valid X-Hub-Signature-256",
+			header: http.Header{"X-Hub-Signature-256": {"sha256=5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"}},
+			secret: "my-secret",
+		},
+		{
+			name:   "Valid legacy X-Hub-Signature",
+			header: http.Header{"X-Hub-Signature": {"sha1=0527727fe68882dbe8997d8fcc659429cc40fcb5"}},
+			secret: "my-secret",
+		},
+		{
+			name: "X-Hub-Signature-256 is preferred over X-Hub-Signature",
+			header: http.Header{
+				"X-Hub-Signature-256": {"sha256=5f83ca85f05b130356d5cb0c55789eabdb9017a58c295cae144d9870123bf8a0"},
+				"X-Hub-Signature":     {"sha1=0000000000000000000000000000000000000000"},
+			},
+			secret: "my-secret",
+		},
+		{
+			name:    "Missing signature",
+			header:  http.Header{},
+			secret:  "my-secret",
+			wantErr: hookCommon.ErrMissingSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := provider.VerifySignature(tt.header, body, tt.secret)
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func Test_HookProvider_DeliveryID(t *testing.T) {
+	provider := HookProvider{}
+	require.Equal(t, "a1b2c3", provider.DeliveryID(http.Header{"X-Github-Delivery": {"a1b2c3"}}))
+	require.Equal(t, "", provider.DeliveryID(http.Header{}))
+}
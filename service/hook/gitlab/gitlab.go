@@ -34,8 +34,30 @@ package gitlab
 // A merge request is sent with the header: `X-Gitlab-Event: Merge Request Hook`
 // Official docs: https://gitlab.com/gitlab-org/gitlab-ce/blob/master/doc/user/project/integrations/webhooks.md#merge-request-events
 //
+// ### Pipeline
+// A pipeline webhook is sent with the header: `X-Gitlab-Event: Pipeline Hook`, every time the status of a pipeline changes.
+// Official docs: https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#pipeline-events
+//
+// Only the `success` status triggers a build, so that Bitrise can pick up after the GitLab CI stages passed.
+// Merge request pipelines run on the `refs/merge-requests/<iid>/head` ref, these are built on the source branch
+// of the merge request. The "merged results" pipelines run on the `refs/merge-requests/<iid>/merge` ref, on a temporary
+// merge commit which is not on the source branch: these are built on the merge ref, without the commit hash.
+// The other merge request pipelines (e.g. merge trains) are skipped.
+//
+// ### Release
+// A release webhook is sent with the header: `X-Gitlab-Event: Release Hook`.
+// Official docs: https://docs.gitlab.com/ee/user/project/integrations/webhook_events.html#release-events
+//
+// Only the `create` action triggers a build, on the tag of the release. The payload has no user information.
+//
+// ## Secret token
+//
+// If a secret token is specified for the webhook, GitLab sends it as-is in the `X-Gitlab-Token` header.
+// GitLab doesn't sign the payload, so the token is compared with the configured secret.
+//
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -59,6 +81,8 @@ const (
 	codePushEventID             = "Push Hook"
 	mergeRequestEventID         = "Merge Request Hook"
 	commentEventID              = "Note Hook"
+	pipelineEventID             = "Pipeline Hook"
+	releaseEventID              = "Release Hook"
 	gitlabPublicVisibilityLevel = 20
 
 	// ProviderID ...
@@ -191,6 +215,54 @@ type MergeRequestCommentEventModel struct {
 	User             UserModel             `json:"user"`
 }
 
+// PipelineInfoModel ...
+type PipelineInfoModel struct {
+	ID     int    `json:"id"`
+	IID    int    `json:"iid"`
+	Name   string `json:"name"`
+	Ref    string `json:"ref"`
+	Tag    bool   `json:"tag"`
+	SHA    string `json:"sha"`
+	Source string `json:"source"`
+	Status string `json:"status"`
+	URL    string `json:"url"`
+}
+
+// PipelineMergeRequestInfoModel ...
+type PipelineMergeRequestInfoModel struct {
+	ID           int    `json:"iid"`
+	SourceBranch string `json:"source_branch"`
+	TargetBranch string `json:"target_branch"`
+}
+
+// PipelineCommitInfoModel ...
+type PipelineCommitInfoModel struct {
+	CommitHash    string `json:"id"`
+	CommitMessage string `json:"message"`
+}
+
+// PipelineEventModel ...
+type PipelineEventModel struct {
+	ObjectKind       string                         `json:"object_kind"`
+	ObjectAttributes PipelineInfoModel              `json:"object_attributes"`
+	MergeRequest     *PipelineMergeRequestInfoModel `json:"merge_request"`
+	User             UserModel                      `json:"user"`
+	Project          RepositoryModel                `json:"project"`
+	Commit           PipelineCommitInfoModel        `json:"commit"`
+}
+
+// ReleaseEventModel ...
+type ReleaseEventModel struct {
+	ObjectKind  string                  `json:"object_kind"`
+	Action      string                  `json:"action"`
+	Name        string                  `json:"name"`
+	Description string                  `json:"description"`
+	Tag         string                  `json:"tag"`
+	URL         string                  `json:"url"`
+	Project     RepositoryModel         `json:"project"`
+	Commit      PipelineCommitInfoModel `json:"commit"`
+}
+
 // ---------------------------------------
 // --- Webhook Provider Implementation ---
 
@@ -228,7 +300,7 @@ func detectContentTypeAndEventID(header http.Header) (string, string, error) {
 }
 
 func isAcceptEventType(eventKey string) bool {
-	return slices.Contains([]string{tagPushEventID, codePushEventID, mergeRequestEventID, commentEventID}, eventKey)
+	return slices.Contains([]string{tagPushEventID, codePushEventID, mergeRequestEventID, commentEventID, pipelineEventID, releaseEventID}, eventKey)
 }
 
 func isAcceptMergeRequestState(prState string) bool {
@@ -629,6 +701,132 @@ func transformMergeRequest(
 	}
 }
 
+func transformPipelineEvent(event PipelineEventModel) hookCommon.TransformResultModel {
+	if event.ObjectKind != "pipeline" {
+		return hookCommon.TransformResultModel{
+			DontWaitForTriggerResponse: true,
+			Error:                      errors.New("Not a Pipeline object"),
+			ShouldSkip:                 true,
+		}
+	}
+
+	pipeline := event.ObjectAttributes
+	if pipeline.Status != "success" {
+		return hookCommon.TransformResultModel{
+			DontWaitForTriggerResponse: true,
+			Error:                      fmt.Errorf("Pipeline status doesn't require a build: %s", pipeline.Status),
+			ShouldSkip:                 true,
+		}
+	}
+	if pipeline.SHA == "" {
+		return hookCommon.TransformResultModel{
+			DontWaitForTriggerResponse: true,
+			Error:                      errors.New("Missing commit hash"),
+		}
+	}
+
+	buildParams := bitriseapi.BuildParamsModel{
+		CommitHash:        pipeline.SHA,
+		CommitMessage:     event.Commit.CommitMessage,
+		BaseRepositoryURL: event.Project.getRepositoryURL(),
+		Environments: []bitriseapi.EnvironmentItem{
+			{Name: "GITLAB_PIPELINE_ID", Value: strconv.Itoa(pipeline.ID), IsExpand: false},
+			{Name: "GITLAB_PIPELINE_IID", Value: strconv.Itoa(pipeline.IID), IsExpand: false},
+			{Name: "GITLAB_PIPELINE_SOURCE", Value: pipeline.Source, IsExpand: false},
+			{Name: "GITLAB_PIPELINE_URL", Value: pipeline.URL, IsExpand: false},
+		},
+	}
+
+	switch {
+	case pipeline.Tag:
+		buildParams.Tag = strings.TrimPrefix(pipeline.Ref, "refs/tags/")
+	case event.MergeRequest != nil:
+		mergeRequestID := event.MergeRequest.ID
+		switch pipeline.Ref {
+		case fmt.Sprintf("refs/merge-requests/%d/head", mergeRequestID):
+			buildParams.Branch = event.MergeRequest.SourceBranch
+		case fmt.Sprintf("refs/merge-requests/%d/merge", mergeRequestID):
+			// the pipeline's commit is GitLab's temporary merge commit, not a commit of the source branch
+			buildParams.CommitHash = ""
+			buildParams.Branch = event.MergeRequest.SourceBranch
+			buildParams.BranchDest = event.MergeRequest.TargetBranch
+			buildParams.PullRequestID = &mergeRequestID
+			buildParams.PullRequestMergeBranch = fmt.Sprintf("merge-requests/%d/merge", mergeRequestID)
+		default:
+			return hookCommon.TransformResultModel{
+				DontWaitForTriggerResponse: true,
+				Error:                      fmt.Errorf("Merge request pipeline ref doesn't require a build: %s", pipeline.Ref),
+				ShouldSkip:                 true,
+			}
+		}
+		buildParams.Environments = append(buildParams.Environments, bitriseapi.EnvironmentItem{
+			Name:     "GITLAB_PIPELINE_MERGE_REQUEST_IID",
+			Value:    strconv.Itoa(event.MergeRequest.ID),
+			IsExpand: false,
+		})
+	default:
+		buildParams.Branch = strings.TrimPrefix(pipeline.Ref, "refs/heads/")
+	}
+
+	return hookCommon.TransformResultModel{
+		DontWaitForTriggerResponse: true,
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: buildParams,
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, event.User.Username),
+			},
+		},
+	}
+}
+
+func transformReleaseEvent(event ReleaseEventModel) hookCommon.TransformResultModel {
+	if event.ObjectKind != "release" {
+		return hookCommon.TransformResultModel{
+			DontWaitForTriggerResponse: true,
+			Error:                      errors.New("Not a Release object"),
+			ShouldSkip:                 true,
+		}
+	}
+	if event.Action != "create" {
+		return hookCommon.TransformResultModel{
+			DontWaitForTriggerResponse: true,
+			Error:                      fmt.Errorf("Release action doesn't require a build: %s", event.Action),
+			ShouldSkip:                 true,
+		}
+	}
+	if event.Tag == "" {
+		return hookCommon.TransformResultModel{
+			DontWaitForTriggerResponse: true,
+			Error:                      errors.New("Missing release tag"),
+		}
+	}
+
+	commitMessage := event.Name
+	if commitMessage == "" {
+		commitMessage = event.Tag
+	}
+
+	return hookCommon.TransformResultModel{
+		DontWaitForTriggerResponse: true,
+		TriggerAPIParams: []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               event.Tag,
+					CommitHash:        event.Commit.CommitHash,
+					CommitMessage:     commitMessage,
+					BaseRepositoryURL: event.Project.getRepositoryURL(),
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITLAB_RELEASE_NAME", Value: event.Name, IsExpand: false},
+						{Name: "GITLAB_RELEASE_DESCRIPTION", Value: event.Description, IsExpand: false},
+						{Name: "GITLAB_RELEASE_URL", Value: event.URL, IsExpand: false},
+					},
+				},
+				TriggeredBy: hookCommon.GenerateTriggeredBy(ProviderID, ""),
+			},
+		},
+	}
+}
+
 func mergeRequestReadyState(mergeRequest MergeRequestEventModel) bitriseapi.PullRequestReadyState {
 	// converted from draft to ready to review
 	if mergeRequest.Changes.Draft.Previous == true && mergeRequest.Changes.Draft.Current == false {
@@ -642,6 +840,24 @@ func mergeRequestReadyState(mergeRequest MergeRequestEventModel) bitriseapi.Pull
 	return bitriseapi.PullRequestReadyStateReadyForReview
 }
 
+// DeliveryID ...
+// GitLab sends the same X-Gitlab-Event-UUID for the retries and the manual resends of a webhook.
+func (hp HookProvider) DeliveryID(header http.Header) string {
+	return header.Get("X-Gitlab-Event-UUID")
+}
+
+// VerifySignature ...
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	token := header.Get("X-Gitlab-Token")
+	if token == "" {
+		return fmt.Errorf("%w: no X-Gitlab-Token header found", hookCommon.ErrMissingSignature)
+	}
+	if subtle.ConstantTimeCompare([]byte(token), []byte(secret)) != 1 {
+		return fmt.Errorf("%w: X-Gitlab-Token does not match", hookCommon.ErrInvalidSignature)
+	}
+	return nil
+}
+
 // TransformRequest ...
 func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
 	contentType, eventID, err := detectContentTypeAndEventID(r.Header)
@@ -718,6 +934,26 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 		}
 
 		return transformMergeRequestCommentEvent(commentEvent)
+	} else if eventID == pipelineEventID {
+		var pipelineEvent PipelineEventModel
+		if err := json.NewDecoder(r.Body).Decode(&pipelineEvent); err != nil {
+			return hookCommon.TransformResultModel{
+				DontWaitForTriggerResponse: true,
+				Error:                      fmt.Errorf("Failed to parse request body as JSON: %s", err),
+			}
+		}
+
+		return transformPipelineEvent(pipelineEvent)
+	} else if eventID == releaseEventID {
+		var releaseEvent ReleaseEventModel
+		if err := json.NewDecoder(r.Body).Decode(&releaseEvent); err != nil {
+			return hookCommon.TransformResultModel{
+				DontWaitForTriggerResponse: true,
+				Error:                      fmt.Errorf("Failed to parse request body as JSON: %s", err),
+			}
+		}
+
+		return transformReleaseEvent(releaseEvent)
 	}
 
 	return hookCommon.TransformResultModel{
@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
 	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
 )
@@ -714,6 +715,78 @@ const sampleMergeRequestCommentUpdatedData = `{
   }
 }`
 
+const samplePipelineSuccessData = `{
+  "object_kind": "pipeline",
+  "object_attributes": {
+    "id": 31,
+    "iid": 3,
+    "name": "Pipeline for branch: develop",
+    "ref": "develop",
+    "tag": false,
+    "sha": "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+    "before_sha": "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+    "source": "push",
+    "status": "success",
+    "detailed_status": "passed",
+    "stages": ["build", "test", "deploy"],
+    "created_at": "2024-05-20 14:20:31 UTC",
+    "finished_at": "2024-05-20 14:31:04 UTC",
+    "duration": 63,
+    "url": "https://gitlab.example.com/gitlab-org/gitlab-test/-/pipelines/31"
+  },
+  "merge_request": null,
+  "user": {
+    "id": 1,
+    "name": "Test User",
+    "username": "test_user"
+  },
+  "project": {
+    "id": 1,
+    "name": "Gitlab Test",
+    "web_url": "https://gitlab.example.com/gitlab-org/gitlab-test",
+    "git_ssh_url": "git@gitlab.example.com:gitlab-org/gitlab-test.git",
+    "git_http_url": "https://gitlab.example.com/gitlab-org/gitlab-test.git",
+    "visibility_level": 20
+  },
+  "commit": {
+    "id": "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+    "message": "test\n",
+    "title": "test",
+    "url": "https://gitlab.example.com/gitlab-org/gitlab-test/-/commit/bcbb5ec396a2c0f828686f14fac9b80b780504f2"
+  },
+  "builds": []
+}`
+
+const sampleReleaseCreateData = `{
+  "id": 1,
+  "created_at": "2024-05-20 14:20:31 UTC",
+  "description": "v1.1 has been released",
+  "name": "v1.1",
+  "released_at": "2024-05-20 14:20:31 UTC",
+  "tag": "v1.1",
+  "object_kind": "release",
+  "project": {
+    "id": 2,
+    "name": "release-webhook-example",
+    "web_url": "https://gitlab.example.com/gitlab-org/release-webhook-example",
+    "git_ssh_url": "git@gitlab.example.com:gitlab-org/release-webhook-example.git",
+    "git_http_url": "https://gitlab.example.com/gitlab-org/release-webhook-example.git",
+    "visibility_level": 0
+  },
+  "url": "https://gitlab.example.com/gitlab-org/release-webhook-example/-/releases/v1.1",
+  "action": "create",
+  "assets": {
+    "count": 0,
+    "links": [],
+    "sources": []
+  },
+  "commit": {
+    "id": "ee0f8e14d53d7c05f3e0f0b3e1a0ab8bd45b1b2c",
+    "message": "Release v1.1",
+    "title": "Release v1.1"
+  }
+}`
+
 var intTwelve = 12
 
 func Test_detectContentTypeAndEventID(t *testing.T) {
@@ -1583,11 +1656,202 @@ func Test_transformMergeRequestCommentEvent(t *testing.T) {
 	}
 }
 
+func Test_transformPipelineEvent(t *testing.T) {
+	t.Log("Tag pipeline")
+	{
+		pipeline := PipelineEventModel{
+			ObjectKind: "pipeline",
+			ObjectAttributes: PipelineInfoModel{
+				ID:     32,
+				IID:    4,
+				Ref:    "v1.0.0",
+				Tag:    true,
+				SHA:    "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+				Source: "push",
+				Status: "success",
+				URL:    "https://gitlab.example.com/gitlab-org/gitlab-test/-/pipelines/32",
+			},
+			User: UserModel{Username: "test_user"},
+		}
+		hookTransformResult := transformPipelineEvent(pipeline)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:        "v1.0.0",
+					CommitHash: "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITLAB_PIPELINE_ID", Value: "32", IsExpand: false},
+						{Name: "GITLAB_PIPELINE_IID", Value: "4", IsExpand: false},
+						{Name: "GITLAB_PIPELINE_SOURCE", Value: "push", IsExpand: false},
+						{Name: "GITLAB_PIPELINE_URL", Value: "https://gitlab.example.com/gitlab-org/gitlab-test/-/pipelines/32", IsExpand: false},
+					},
+				},
+				TriggeredBy: "webhook-gitlab/test_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+		require.Equal(t, true, hookTransformResult.DontWaitForTriggerResponse)
+	}
+
+	t.Log("Merge request pipeline")
+	{
+		pipeline := PipelineEventModel{
+			ObjectKind: "pipeline",
+			ObjectAttributes: PipelineInfoModel{
+				ID:     33,
+				IID:    5,
+				Ref:    "refs/merge-requests/12/head",
+				SHA:    "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+				Source: "merge_request_event",
+				Status: "success",
+			},
+			MergeRequest: &PipelineMergeRequestInfoModel{
+				ID:           12,
+				SourceBranch: "feature/login",
+				TargetBranch: "develop",
+			},
+			User: UserModel{Username: "test_user"},
+		}
+		hookTransformResult := transformPipelineEvent(pipeline)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, "feature/login", hookTransformResult.TriggerAPIParams[0].BuildParams.Branch)
+		require.Equal(t, "bcbb5ec396a2c0f828686f14fac9b80b780504f2", hookTransformResult.TriggerAPIParams[0].BuildParams.CommitHash)
+		require.Equal(t, bitriseapi.EnvironmentItem{Name: "GITLAB_PIPELINE_MERGE_REQUEST_IID", Value: "12", IsExpand: false},
+			hookTransformResult.TriggerAPIParams[0].BuildParams.Environments[4])
+	}
+
+	t.Log("Merged results pipeline - built on the merge ref, without the temporary merge commit")
+	{
+		pipeline := PipelineEventModel{
+			ObjectKind: "pipeline",
+			ObjectAttributes: PipelineInfoModel{
+				ID:     34,
+				IID:    6,
+				Ref:    "refs/merge-requests/12/merge",
+				SHA:    "5f2c1c5e0d4ab0ec1a1c9e6c8c9b3e0ad4c3b2a1",
+				Source: "merge_request_event",
+				Status: "success",
+			},
+			MergeRequest: &PipelineMergeRequestInfoModel{
+				ID:           12,
+				SourceBranch: "feature/login",
+				TargetBranch: "develop",
+			},
+			User: UserModel{Username: "test_user"},
+		}
+		hookTransformResult := transformPipelineEvent(pipeline)
+		require.NoError(t, hookTransformResult.Error)
+		buildParams := hookTransformResult.TriggerAPIParams[0].BuildParams
+		require.Equal(t, "", buildParams.CommitHash)
+		require.Equal(t, "feature/login", buildParams.Branch)
+		require.Equal(t, "develop", buildParams.BranchDest)
+		require.Equal(t, &intTwelve, buildParams.PullRequestID)
+		require.Equal(t, "merge-requests/12/merge", buildParams.PullRequestMergeBranch)
+	}
+
+	t.Log("Merge train pipeline - should be skipped")
+	{
+		pipeline := PipelineEventModel{
+			ObjectKind: "pipeline",
+			ObjectAttributes: PipelineInfoModel{
+				Ref:    "refs/merge-requests/12/train",
+				SHA:    "5f2c1c5e0d4ab0ec1a1c9e6c8c9b3e0ad4c3b2a1",
+				Status: "success",
+			},
+			MergeRequest: &PipelineMergeRequestInfoModel{ID: 12, SourceBranch: "feature/login", TargetBranch: "develop"},
+		}
+		hookTransformResult := transformPipelineEvent(pipeline)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Merge request pipeline ref doesn't require a build: refs/merge-requests/12/train")
+	}
+
+	t.Log("Not successful pipeline - should be skipped")
+	{
+		for _, status := range []string{"created", "pending", "running", "failed", "canceled", "skipped", "manual"} {
+			pipeline := PipelineEventModel{
+				ObjectKind: "pipeline",
+				ObjectAttributes: PipelineInfoModel{
+					Ref:    "develop",
+					SHA:    "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+					Status: status,
+				},
+			}
+			hookTransformResult := transformPipelineEvent(pipeline)
+			require.True(t, hookTransformResult.ShouldSkip)
+			require.EqualError(t, hookTransformResult.Error, "Pipeline status doesn't require a build: "+status)
+			require.Nil(t, hookTransformResult.TriggerAPIParams)
+		}
+	}
+
+	t.Log("Missing commit hash")
+	{
+		pipeline := PipelineEventModel{
+			ObjectKind:       "pipeline",
+			ObjectAttributes: PipelineInfoModel{Ref: "develop", Status: "success"},
+		}
+		hookTransformResult := transformPipelineEvent(pipeline)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Missing commit hash")
+	}
+
+	t.Log("Not a pipeline object")
+	{
+		hookTransformResult := transformPipelineEvent(PipelineEventModel{ObjectKind: "build"})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Not a Pipeline object")
+	}
+}
+
+func Test_transformReleaseEvent(t *testing.T) {
+	t.Log("Release without a name")
+	{
+		release := ReleaseEventModel{
+			ObjectKind: "release",
+			Action:     "create",
+			Tag:        "v1.2",
+			Commit:     PipelineCommitInfoModel{CommitHash: "ee0f8e14d53d7c05f3e0f0b3e1a0ab8bd45b1b2c"},
+		}
+		hookTransformResult := transformReleaseEvent(release)
+		require.NoError(t, hookTransformResult.Error)
+		require.Equal(t, "v1.2", hookTransformResult.TriggerAPIParams[0].BuildParams.Tag)
+		require.Equal(t, "v1.2", hookTransformResult.TriggerAPIParams[0].BuildParams.CommitMessage)
+		require.Equal(t, "webhook", hookTransformResult.TriggerAPIParams[0].TriggeredBy)
+	}
+
+	t.Log("Release updated - should be skipped")
+	{
+		for _, action := range []string{"update", "delete"} {
+			release := ReleaseEventModel{
+				ObjectKind: "release",
+				Action:     action,
+				Tag:        "v1.2",
+			}
+			hookTransformResult := transformReleaseEvent(release)
+			require.True(t, hookTransformResult.ShouldSkip)
+			require.EqualError(t, hookTransformResult.Error, "Release action doesn't require a build: "+action)
+		}
+	}
+
+	t.Log("Missing tag")
+	{
+		hookTransformResult := transformReleaseEvent(ReleaseEventModel{ObjectKind: "release", Action: "create"})
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Missing release tag")
+	}
+
+	t.Log("Not a release object")
+	{
+		hookTransformResult := transformReleaseEvent(ReleaseEventModel{ObjectKind: "tag_push"})
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Not a Release object")
+	}
+}
+
 func Test_isAcceptEventType(t *testing.T) {
 	t.Log("Accept")
 	{
 		for _, anEvent := range []string{
-			"Push Hook", "Merge Request Hook", "Tag Push Hook", "Note Hook",
+			"Push Hook", "Merge Request Hook", "Tag Push Hook", "Note Hook", "Pipeline Hook", "Release Hook",
 		} {
 			t.Log(" * " + anEvent)
 			require.Equal(t, true, isAcceptEventType(anEvent))
@@ -1921,6 +2185,69 @@ func Test_HookProvider_TransformRequest(t *testing.T) {
 		require.Equal(t, true, hookTransformResult.DontWaitForTriggerResponse)
 	}
 
+	t.Log("Pipeline: success - should be handled")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"X-Gitlab-Event": {"Pipeline Hook"},
+				"Content-Type":   {"application/json"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(samplePipelineSuccessData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Branch:            "develop",
+					CommitHash:        "bcbb5ec396a2c0f828686f14fac9b80b780504f2",
+					CommitMessage:     "test\n",
+					BaseRepositoryURL: "https://gitlab.example.com/gitlab-org/gitlab-test.git",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITLAB_PIPELINE_ID", Value: "31", IsExpand: false},
+						{Name: "GITLAB_PIPELINE_IID", Value: "3", IsExpand: false},
+						{Name: "GITLAB_PIPELINE_SOURCE", Value: "push", IsExpand: false},
+						{Name: "GITLAB_PIPELINE_URL", Value: "https://gitlab.example.com/gitlab-org/gitlab-test/-/pipelines/31", IsExpand: false},
+					},
+				},
+				TriggeredBy: "webhook-gitlab/test_user",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+		require.Equal(t, true, hookTransformResult.DontWaitForTriggerResponse)
+	}
+
+	t.Log("Release: create - should be handled")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"X-Gitlab-Event": {"Release Hook"},
+				"Content-Type":   {"application/json"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(sampleReleaseCreateData)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					Tag:               "v1.1",
+					CommitHash:        "ee0f8e14d53d7c05f3e0f0b3e1a0ab8bd45b1b2c",
+					CommitMessage:     "v1.1",
+					BaseRepositoryURL: "git@gitlab.example.com:gitlab-org/release-webhook-example.git",
+					Environments: []bitriseapi.EnvironmentItem{
+						{Name: "GITLAB_RELEASE_NAME", Value: "v1.1", IsExpand: false},
+						{Name: "GITLAB_RELEASE_DESCRIPTION", Value: "v1.1 has been released", IsExpand: false},
+						{Name: "GITLAB_RELEASE_URL", Value: "https://gitlab.example.com/gitlab-org/release-webhook-example/-/releases/v1.1", IsExpand: false},
+					},
+				},
+				TriggeredBy: "webhook",
+			},
+		}, hookTransformResult.TriggerAPIParams)
+		require.Equal(t, true, hookTransformResult.DontWaitForTriggerResponse)
+	}
+
 	t.Log("Unsupported Content-Type")
 	{
 		request := http.Request{
@@ -2089,3 +2416,30 @@ func messagesSize(messages []string) int {
 	}
 	return size
 }
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	provider := NewDefaultHookProvider(zap.NewNop())
+
+	t.Log("Matching token")
+	{
+		header := http.Header{"X-Gitlab-Token": {"my-secret"}}
+		require.NoError(t, provider.VerifySignature(header, nil, "my-secret"))
+	}
+
+	t.Log("Token mismatch")
+	{
+		header := http.Header{"X-Gitlab-Token": {"not-my-secret"}}
+		require.ErrorIs(t, provider.VerifySignature(header, nil, "my-secret"), hookCommon.ErrInvalidSignature)
+	}
+
+	t.Log("Missing X-Gitlab-Token header")
+	{
+		require.ErrorIs(t, provider.VerifySignature(http.Header{}, nil, "my-secret"), hookCommon.ErrMissingSignature)
+	}
+}
+
+func Test_HookProvider_DeliveryID(t *testing.T) {
+	provider := HookProvider{}
+	require.Equal(t, "a1b2c3", provider.DeliveryID(http.Header{"X-Gitlab-Event-Uuid": {"a1b2c3"}}))
+	require.Equal(t, "", provider.DeliveryID(http.Header{}))
+}
@@ -24,8 +24,12 @@ import (
 // --- Webhook Data Model ---
 
 const (
-	pushEventID   = "push"
-	createEventID = "create"
+	pushEventID         = "push"
+	createEventID       = "create"
+	pullRequestEventID  = "pull_request"
+	issueCommentEventID = "issue_comment"
+
+	emptyCommitHash = "0000000000000000000000000000000000000000"
 
 	// ProviderID ...
 	ProviderID = "gogs"
@@ -35,13 +39,28 @@ const (
 type CommitModel struct {
 	CommitHash    string `json:"id"`
 	CommitMessage string `json:"message"`
+	Timestamp     string `json:"timestamp"`
+}
+
+// UserModel ...
+type UserModel struct {
+	UserName string `json:"username"`
+}
+
+// RepoInfoModel ...
+type RepoInfoModel struct {
+	FullName      string `json:"full_name"`
+	DefaultBranch string `json:"default_branch"`
 }
 
 // PushEventModel ...
 type PushEventModel struct {
 	Ref         string        `json:"ref"`
+	Before      string        `json:"before"`
 	CheckoutSHA string        `json:"after"`
 	Commits     []CommitModel `json:"commits"`
+	Repo        RepoInfoModel `json:"repository"`
+	Pusher      UserModel     `json:"pusher"`
 }
 
 // CreateEventModel ...
@@ -52,11 +71,72 @@ type CreateEventModel struct {
 	CommitMessage string `json:"message"`
 }
 
+// PullRequestInfoModel ...
+type PullRequestInfoModel struct {
+	Number         int    `json:"number"`
+	Title          string `json:"title"`
+	State          string `json:"state"`
+	HTMLURL        string `json:"html_url"`
+	HeadBranch     string `json:"head_branch"`
+	BaseBranch     string `json:"base_branch"`
+	HasMerged      bool   `json:"merged"`
+	MergedAt       string `json:"merged_at"`
+	MergedCommitID string `json:"merge_commit_sha"`
+}
+
+// PullRequestEventModel ...
+type PullRequestEventModel struct {
+	Action      string               `json:"action"`
+	Number      int                  `json:"number"`
+	PullRequest PullRequestInfoModel `json:"pull_request"`
+	Repo        RepoInfoModel        `json:"repository"`
+	Sender      UserModel            `json:"sender"`
+}
+
+// IssueInfoModel ...
+type IssueInfoModel struct {
+	Number      int                     `json:"number"`
+	PullRequest *IssuePullRequestsModel `json:"pull_request"`
+}
+
+// IssuePullRequestsModel ...
+type IssuePullRequestsModel struct {
+	HasMerged bool `json:"merged"`
+}
+
+// CommentInfoModel ...
+type CommentInfoModel struct {
+	CreatedAt string `json:"created_at"`
+}
+
+// IssueCommentEventModel ...
+type IssueCommentEventModel struct {
+	Action  string           `json:"action"`
+	Issue   IssueInfoModel   `json:"issue"`
+	Comment CommentInfoModel `json:"comment"`
+	Repo    RepoInfoModel    `json:"repository"`
+	Sender  UserModel        `json:"sender"`
+}
+
 // ---------------------------------------
 // --- Webhook Provider Implementation ---
 
 // HookProvider ...
-type HookProvider struct{}
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
 
 func detectContentTypeAndEventID(header http.Header) (string, string, error) {
 	contentType := header.Get("Content-Type")
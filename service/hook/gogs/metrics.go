@@ -0,0 +1,132 @@
+package gogs
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// GatherMetrics ...
+func (hp HookProvider) GatherMetrics(r *http.Request, appSlug string) ([]common.Metrics, error) {
+	_, eventID, err := detectContentTypeAndEventID(r.Header)
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var event interface{}
+	switch eventID {
+	case pushEventID:
+		event = &PushEventModel{}
+	case pullRequestEventID:
+		event = &PullRequestEventModel{}
+	case issueCommentEventID:
+		event = &IssueCommentEventModel{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	currentTime := hp.timeProvider.CurrentTime()
+	metricsList := hp.gatherMetrics(event, eventID, appSlug, currentTime)
+	return metricsList, nil
+}
+
+func (hp HookProvider) gatherMetrics(event interface{}, eventID, appSlug string, currentTime time.Time) []common.Metrics {
+	var metrics common.Metrics
+	switch event := event.(type) {
+	case *PushEventModel:
+		metrics = newPushMetrics(event, eventID, appSlug, currentTime)
+	case *PullRequestEventModel:
+		metrics = newPullRequestMetrics(event, eventID, appSlug, currentTime)
+	case *IssueCommentEventModel:
+		if event.Issue.PullRequest == nil {
+			return nil
+		}
+		metrics = newPullRequestCommentMetrics(event, eventID, appSlug, currentTime)
+	}
+
+	if metrics == nil {
+		return nil
+	}
+
+	return []common.Metrics{metrics}
+}
+
+func newPushMetrics(event *PushEventModel, eventID, appSlug string, currentTime time.Time) common.PushMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, commitIDAfter string, commitIDBefore string, oldestCommitTimestamp *time.Time, latestCommitTimestamp *time.Time, masterBranch string) common.PushMetrics
+
+	switch {
+	case event.Before == emptyCommitHash:
+		constructorFunc = common.NewPushCreatedMetrics
+	case event.CheckoutSHA == emptyCommitHash:
+		constructorFunc = common.NewPushDeletedMetrics
+	default:
+		constructorFunc = common.NewPushMetrics
+	}
+
+	var oldestCommitTime, latestCommitTime *time.Time
+	if len(event.Commits) > 0 {
+		// Gogs lists the commits from the newest to the oldest
+		latestCommitTime = parseTime(event.Commits[0].Timestamp)
+		oldestCommitTime = parseTime(event.Commits[len(event.Commits)-1].Timestamp)
+	}
+
+	generalMetrics := common.NewGeneralMetrics(ProviderID, event.Repo.FullName, currentTime, nil, appSlug, common.OriginalTrigger(eventID, ""), event.Pusher.UserName, event.Ref)
+	return constructorFunc(generalMetrics, event.CheckoutSHA, event.Before, oldestCommitTime, latestCommitTime, event.Repo.DefaultBranch)
+}
+
+func newPullRequestMetrics(event *PullRequestEventModel, eventID, appSlug string, currentTime time.Time) common.PullRequestMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, generalPullRequestMetrics common.GeneralPullRequestMetrics) common.PullRequestMetrics
+
+	pullRequest := event.PullRequest
+	// Gogs doesn't send the update time of the pull request, only the merge time
+	var timestamp *time.Time
+	switch event.Action {
+	case "opened":
+		constructorFunc = common.NewPullRequestOpenedMetrics
+	case "closed":
+		constructorFunc = common.NewPullRequestClosedMetrics
+		timestamp = parseTime(pullRequest.MergedAt)
+	default:
+		constructorFunc = common.NewPullRequestUpdatedMetrics
+	}
+
+	generalMetrics := common.NewGeneralMetrics(ProviderID, event.Repo.FullName, currentTime, timestamp, appSlug, common.OriginalTrigger(eventID, event.Action), event.Sender.UserName, pullRequest.HeadBranch)
+
+	generalPullRequestMetrics := common.GeneralPullRequestMetrics{
+		PullRequestTitle: pullRequest.Title,
+		PullRequestID:    fmt.Sprintf("%d", pullRequest.Number),
+		PullRequestURL:   pullRequest.HTMLURL,
+		TargetBranch:     pullRequest.BaseBranch,
+		MergeCommitSHA:   pullRequest.MergedCommitID,
+		Status:           pullRequest.State, // open or closed
+	}
+
+	return constructorFunc(generalMetrics, generalPullRequestMetrics)
+}
+
+func newPullRequestCommentMetrics(event *IssueCommentEventModel, eventID, appSlug string, currentTime time.Time) common.PullRequestCommentMetrics {
+	timestamp := parseTime(event.Comment.CreatedAt)
+	generalMetrics := common.NewGeneralMetrics(ProviderID, event.Repo.FullName, currentTime, timestamp, appSlug, common.OriginalTrigger(eventID, event.Action), event.Sender.UserName, "")
+	return common.NewPullRequestCommentMetrics(generalMetrics, fmt.Sprintf("%d", event.Issue.Number))
+}
+
+func parseTime(s string) *time.Time {
+	// 2024-03-07T10:15:30+01:00
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,162 @@
+package gogs
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const samplePushData = `{
+  "ref": "refs/heads/master",
+  "before": "28e1879d029cb852e4844d9c718537df08844e03",
+  "after": "f8f37818dc89a67516adfc21896d0c9ec43d05c2",
+  "commits": [
+    {
+      "id": "f8f37818dc89a67516adfc21896d0c9ec43d05c2",
+      "message": "second commit",
+      "timestamp": "2024-03-07T10:20:00+01:00"
+    },
+    {
+      "id": "bffeb74224043ba2feb48d137756c8a9331c449a",
+      "message": "first commit",
+      "timestamp": "2024-03-07T10:15:30+01:00"
+    }
+  ],
+  "repository": {
+    "full_name": "gogs/webhooks",
+    "default_branch": "master"
+  },
+  "pusher": {
+    "login": "gogs-user",
+    "username": "gogs-user"
+  }
+}`
+
+const samplePullRequestData = `{
+  "action": "closed",
+  "number": 2,
+  "pull_request": {
+    "id": 12,
+    "number": 2,
+    "title": "Add feature",
+    "state": "closed",
+    "html_url": "https://gogs.example.com/gogs/webhooks/pulls/2",
+    "head_branch": "feature",
+    "base_branch": "master",
+    "merged": true,
+    "merged_at": "2024-03-07T10:15:30+01:00",
+    "merge_commit_sha": "83b86e5f286f546dc5a4a58db66ceef44460c85e"
+  },
+  "repository": {
+    "full_name": "gogs/webhooks",
+    "default_branch": "master"
+  },
+  "sender": {
+    "username": "gogs-user"
+  }
+}`
+
+const sampleIssueCommentData = `{
+  "action": "created",
+  "issue": {
+    "number": 2,
+    "pull_request": {
+      "merged": false
+    }
+  },
+  "comment": {
+    "body": "LGTM",
+    "created_at": "2024-03-07T10:15:30+01:00"
+  },
+  "repository": {
+    "full_name": "gogs/webhooks"
+  },
+  "sender": {
+    "username": "commenter"
+  }
+}`
+
+func TestHookProvider_gatherMetrics(t *testing.T) {
+	currentTime := time.Date(2024, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		event   interface{}
+		eventID string
+		appSlug string
+		want    string
+	}{
+		{
+			name:    "Push webhook",
+			event:   testEvent[PushEventModel](t, samplePushData),
+			eventID: "push",
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"pushed","provider_type":"gogs","repository":"gogs/webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","user_name":"gogs-user","git_ref":"refs/heads/master","commit_id_after":"f8f37818dc89a67516adfc21896d0c9ec43d05c2","commit_id_before":"28e1879d029cb852e4844d9c718537df08844e03","oldest_commit_timestamp":"2024-03-07T10:15:30+01:00","latest_commit_timestamp":"2024-03-07T10:20:00+01:00","master_branch":"master","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:    "Push webhook - branch created",
+			event:   &PushEventModel{Ref: "refs/heads/feature", Before: emptyCommitHash, CheckoutSHA: "f8f37818dc89a67516adfc21896d0c9ec43d05c2", Repo: RepoInfoModel{FullName: "gogs/webhooks"}, Pusher: UserModel{UserName: "gogs-user"}},
+			eventID: "push",
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"created","provider_type":"gogs","repository":"gogs/webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"push:","user_name":"gogs-user","git_ref":"refs/heads/feature","commit_id_after":"f8f37818dc89a67516adfc21896d0c9ec43d05c2","commit_id_before":"0000000000000000000000000000000000000000","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:    "Pull request closed webhook",
+			event:   testEvent[PullRequestEventModel](t, samplePullRequestData),
+			eventID: "pull_request",
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"closed","provider_type":"gogs","repository":"gogs/webhooks","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30+01:00","app_slug":"slug","original_trigger":"pull_request:closed","user_name":"gogs-user","git_ref":"feature","pull_request_title":"Add feature","pull_request_id":"2","pull_request_url":"https://gogs.example.com/gogs/webhooks/pulls/2","target_branch":"master","changed_files_count":0,"addition_count":0,"deletion_count":0,"commit_count":0,"merge_commit_sha":"83b86e5f286f546dc5a4a58db66ceef44460c85e","status":"closed"}`,
+		},
+		{
+			name:    "Pull request synchronized webhook",
+			event:   &PullRequestEventModel{Action: "synchronized", PullRequest: PullRequestInfoModel{Number: 2, State: "open", HeadBranch: "feature", BaseBranch: "master"}, Repo: RepoInfoModel{FullName: "gogs/webhooks"}, Sender: UserModel{UserName: "gogs-user"}},
+			eventID: "pull_request",
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"updated","provider_type":"gogs","repository":"gogs/webhooks","timestamp":"2024-03-07T12:00:00Z","app_slug":"slug","original_trigger":"pull_request:synchronized","user_name":"gogs-user","git_ref":"feature","pull_request_id":"2","target_branch":"master","changed_files_count":0,"addition_count":0,"deletion_count":0,"commit_count":0,"status":"open"}`,
+		},
+		{
+			name:    "Pull request comment webhook",
+			event:   testEvent[IssueCommentEventModel](t, sampleIssueCommentData),
+			eventID: "issue_comment",
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"comment","provider_type":"gogs","repository":"gogs/webhooks","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30+01:00","app_slug":"slug","original_trigger":"issue_comment:created","user_name":"commenter","pull_request_id":"2"}`,
+		},
+		{
+			name:    "Issue comment webhook",
+			event:   &IssueCommentEventModel{Action: "created", Issue: IssueInfoModel{Number: 3}},
+			eventID: "issue_comment",
+			appSlug: "slug",
+			want:    "",
+		},
+		{
+			name:    "Unsupported webhook",
+			event:   &CreateEventModel{Ref: "v1.0.0", RefType: "tag"},
+			eventID: "create",
+			appSlug: "slug",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hp := HookProvider{}
+			got := hp.gatherMetrics(tt.event, tt.eventID, tt.appSlug, currentTime)
+			if tt.want != "" {
+				require.Equal(t, len(got), 1)
+				gotMetrics := got[0]
+				gotBytes, err := gotMetrics.Serialise()
+				require.NoError(t, err)
+				require.Equal(t, tt.want, string(gotBytes))
+			} else {
+				require.Nil(t, got)
+			}
+		})
+	}
+}
+
+func testEvent[T any](t *testing.T, payload string) *T {
+	var event T
+	require.NoError(t, json.Unmarshal([]byte(payload), &event))
+	return &event
+}
@@ -1,10 +1,13 @@
 package slack
 
 import (
+	"crypto/sha256"
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
 	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
@@ -14,13 +17,33 @@ const (
 
 	// ProviderID ...
 	ProviderID = "slack"
+
+	// signatureVersion is the version of Slack's request signing scheme
+	signatureVersion = "v0"
+	// maxRequestTimestampAge is the maximum accepted age of a signed request,
+	//  older requests are rejected to prevent replay attacks
+	maxRequestTimestampAge = 5 * time.Minute
 )
 
 // ---------------------------------------
 // --- Webhook Provider Implementation ---
 
 // HookProvider ...
-type HookProvider struct{}
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
 
 func detectContentType(header http.Header) (string, error) {
 	contentType := header.Get("Content-Type")
@@ -137,6 +160,36 @@ func transformOutgoingWebhookMessage(slackText string) hookCommon.TransformResul
 	}
 }
 
+// VerifySignature ...
+// Slack signs the requests with the app's Signing Secret, see: https://api.slack.com/authentication/verifying-requests-from-slack
+// The signature is the HMAC SHA256 of the "v0:<X-Slack-Request-Timestamp>:<body>" base string,
+// sent in the X-Slack-Signature header, in "v0=<hex digest>" format.
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	signature := header.Get("X-Slack-Signature")
+	if signature == "" {
+		return fmt.Errorf("%w: no X-Slack-Signature header found", hookCommon.ErrMissingSignature)
+	}
+	timestampStr := header.Get("X-Slack-Request-Timestamp")
+	if timestampStr == "" {
+		return fmt.Errorf("%w: no X-Slack-Request-Timestamp header found", hookCommon.ErrMissingSignature)
+	}
+
+	timestamp, err := strconv.ParseInt(timestampStr, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: invalid X-Slack-Request-Timestamp: %s", hookCommon.ErrInvalidSignature, timestampStr)
+	}
+	age := hp.timeProvider.CurrentTime().Sub(time.Unix(timestamp, 0))
+	if age > maxRequestTimestampAge || age < -maxRequestTimestampAge {
+		return fmt.Errorf("%w: X-Slack-Request-Timestamp is too old or too far in the future: %s", hookCommon.ErrInvalidSignature, timestampStr)
+	}
+
+	if !strings.HasPrefix(signature, signatureVersion+"=") {
+		return fmt.Errorf("%w: unsupported X-Slack-Signature version", hookCommon.ErrInvalidSignature)
+	}
+	baseString := []byte(fmt.Sprintf("%s:%s:%s", signatureVersion, timestampStr, body))
+	return hookCommon.VerifyHMACSignature(sha256.New, secret, baseString, strings.TrimPrefix(signature, signatureVersion+"="))
+}
+
 // TransformRequest ...
 func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformResultModel {
 	contentType, err := detectContentType(r.Header)
@@ -265,6 +318,11 @@ func (hp HookProvider) TransformResponse(input hookCommon.TransformResponseInput
 			slackAttachments = append(slackAttachments, createAttachmentItemModel(errMsg, slackColorDanger))
 		}
 	}
+	if len(input.QueuedTriggerResponses) > 0 {
+		for _, aQueuedTrigResp := range input.QueuedTriggerResponses {
+			slackAttachments = append(slackAttachments, createAttachmentItemModel(aQueuedTrigResp.Message, slackColorWarning))
+		}
+	}
 	if len(input.SuccessTriggerResponses) > 0 {
 		for _, aSuccessTrigResp := range input.SuccessTriggerResponses {
 			slackAttachments = append(slackAttachments, createAttachmentItemModel(messageForBuildTrigger(aSuccessTrigResp), slackColorGood))
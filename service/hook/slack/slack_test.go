@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
 	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
@@ -926,3 +927,84 @@ func Test_HookProvider_TransformSuccessMessageResponse(t *testing.T) {
 		}, resp)
 	}
 }
+
+type testTimeProvider struct {
+	currentTime time.Time
+}
+
+func (p testTimeProvider) CurrentTime() time.Time {
+	return p.currentTime
+}
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	body := []byte("command=%2Fbitrise&text=branch%3Amaster")
+	validSignature := "v0=222e1961b4904d94c6545b20638a8b0546ee9cbb15f919c5d384fadcddaf6623"
+
+	tests := []struct {
+		name        string
+		header      http.Header
+		currentTime time.Time
+		wantErr     error
+	}{
+		{
+			name: "Valid signature",
+			header: http.Header{
+				"X-Slack-Signature":         {validSignature},
+				"X-Slack-Request-Timestamp": {"1700000000"},
+			},
+			currentTime: time.Unix(1700000060, 0),
+		},
+		{
+			name: "Stale timestamp",
+			header: http.Header{
+				"X-Slack-Signature":         {validSignature},
+				"X-Slack-Request-Timestamp": {"1700000000"},
+			},
+			currentTime: time.Unix(1700000000, 0).Add(10 * time.Minute),
+			wantErr:     hookCommon.ErrInvalidSignature,
+		},
+		{
+			name: "Timestamp does not match the signature",
+			header: http.Header{
+				"X-Slack-Signature":         {validSignature},
+				"X-Slack-Request-Timestamp": {"1700000001"},
+			},
+			currentTime: time.Unix(1700000060, 0),
+			wantErr:     hookCommon.ErrInvalidSignature,
+		},
+		{
+			name: "Unsupported signature version",
+			header: http.Header{
+				"X-Slack-Signature":         {"v1=222e1961b4904d94c6545b20638a8b0546ee9cbb15f919c5d384fadcddaf6623"},
+				"X-Slack-Request-Timestamp": {"1700000000"},
+			},
+			currentTime: time.Unix(1700000060, 0),
+			wantErr:     hookCommon.ErrInvalidSignature,
+		},
+		{
+			name: "Missing timestamp",
+			header: http.Header{
+				"X-Slack-Signature": {validSignature},
+			},
+			currentTime: time.Unix(1700000060, 0),
+			wantErr:     hookCommon.ErrMissingSignature,
+		},
+		{
+			name:        "Missing signature",
+			header:      http.Header{},
+			currentTime: time.Unix(1700000060, 0),
+			wantErr:     hookCommon.ErrMissingSignature,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			provider := NewHookProvider(testTimeProvider{currentTime: tt.currentTime}).(HookProvider)
+			err := provider.VerifySignature(tt.header, body, "my-signing-secret")
+			if tt.wantErr == nil {
+				require.NoError(t, err)
+			} else {
+				require.ErrorIs(t, err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,132 @@
+package visualstudioteamservices
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/bitrise-io/bitrise-webhooks/service/hook/common"
+)
+
+// GatherMetrics ...
+func (hp HookProvider) GatherMetrics(r *http.Request, appSlug string) ([]common.Metrics, error) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var eventModel EventModel
+	if err := json.Unmarshal(payload, &eventModel); err != nil {
+		return nil, err
+	}
+
+	var event interface{}
+	switch eventModel.EventType {
+	case Push:
+		event = &PushEventModel{}
+	case PullRequestCreate, PullRequestUpdate:
+		event = &PullRequestEventModel{}
+	case PullRequestComment:
+		event = &PullRequestCommentEventModel{}
+	default:
+		return nil, nil
+	}
+	if err := json.Unmarshal(payload, event); err != nil {
+		return nil, err
+	}
+
+	currentTime := hp.timeProvider.CurrentTime()
+	metricsList := hp.gatherMetrics(event, appSlug, currentTime)
+	return metricsList, nil
+}
+
+func (hp HookProvider) gatherMetrics(event interface{}, appSlug string, currentTime time.Time) []common.Metrics {
+	var metrics common.Metrics
+	switch event := event.(type) {
+	case *PushEventModel:
+		if len(event.Resource.RefUpdates) == 0 {
+			return nil
+		}
+		metrics = newPushMetrics(event, appSlug, currentTime)
+	case *PullRequestEventModel:
+		metrics = newPullRequestMetrics(event, appSlug, currentTime)
+	case *PullRequestCommentEventModel:
+		metrics = newPullRequestCommentMetrics(event, appSlug, currentTime)
+	}
+
+	if metrics == nil {
+		return nil
+	}
+
+	return []common.Metrics{metrics}
+}
+
+func newPushMetrics(event *PushEventModel, appSlug string, currentTime time.Time) common.PushMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, commitIDAfter string, commitIDBefore string, oldestCommitTimestamp *time.Time, latestCommitTimestamp *time.Time, masterBranch string) common.PushMetrics
+
+	refUpdate := event.Resource.RefUpdates[0]
+	switch {
+	case refUpdate.OldObjectID == emptyCommitHash:
+		constructorFunc = common.NewPushCreatedMetrics
+	case refUpdate.NewObjectID == emptyCommitHash:
+		constructorFunc = common.NewPushDeletedMetrics
+	default:
+		constructorFunc = common.NewPushMetrics
+	}
+
+	var oldestCommitTime, latestCommitTime *time.Time
+	if commits := event.Resource.Commits; len(commits) > 0 {
+		// Commits are in descending order, by commit date-time (first one is the latest)
+		latestCommitTime = parseTime(commits[0].Author.Date)
+		oldestCommitTime = parseTime(commits[len(commits)-1].Author.Date)
+	}
+
+	repository := event.Resource.Repository
+	generalMetrics := common.NewGeneralMetrics(ProviderID, repository.Name, currentTime, parseTime(event.CreatedDate), appSlug, common.OriginalTrigger(event.EventType, ""), event.Resource.PushedBy.DisplayName, refUpdate.Name)
+	return constructorFunc(generalMetrics, refUpdate.NewObjectID, refUpdate.OldObjectID, oldestCommitTime, latestCommitTime, repository.DefaultBranch)
+}
+
+func newPullRequestMetrics(event *PullRequestEventModel, appSlug string, currentTime time.Time) common.PullRequestMetrics {
+	var constructorFunc func(generalMetrics common.GeneralMetrics, generalPullRequestMetrics common.GeneralPullRequestMetrics) common.PullRequestMetrics
+
+	pullRequest := event.Resource
+	switch {
+	case event.EventType == PullRequestCreate:
+		constructorFunc = common.NewPullRequestOpenedMetrics
+	case pullRequest.Status == "completed" || pullRequest.Status == "abandoned":
+		constructorFunc = common.NewPullRequestClosedMetrics
+	default:
+		constructorFunc = common.NewPullRequestUpdatedMetrics
+	}
+
+	generalMetrics := common.NewGeneralMetrics(ProviderID, pullRequest.Repository.Name, currentTime, parseTime(event.CreatedDate), appSlug, common.OriginalTrigger(event.EventType, ""), pullRequest.CreatedBy.DisplayName, pullRequest.SourceReferenceName)
+
+	generalPullRequestMetrics := common.GeneralPullRequestMetrics{
+		PullRequestTitle: pullRequest.Title,
+		PullRequestID:    fmt.Sprintf("%d", pullRequest.PullRequestID),
+		PullRequestURL:   pullRequest.Links.Web.Href,
+		TargetBranch:     pullRequest.TargetReferenceName,
+		CommitID:         pullRequest.LastSourceCommit.CommitID,
+		MergeCommitSHA:   pullRequest.LastMergeCommit.CommitID,
+		Status:           pullRequest.Status, // active, completed or abandoned
+	}
+
+	return constructorFunc(generalMetrics, generalPullRequestMetrics)
+}
+
+func newPullRequestCommentMetrics(event *PullRequestCommentEventModel, appSlug string, currentTime time.Time) common.PullRequestCommentMetrics {
+	pullRequest := event.Resource.PullRequest
+	generalMetrics := common.NewGeneralMetrics(ProviderID, pullRequest.Repository.Name, currentTime, parseTime(event.CreatedDate), appSlug, common.OriginalTrigger(event.EventType, ""), event.Resource.Comment.Author.DisplayName, pullRequest.SourceReferenceName)
+	return common.NewPullRequestCommentMetrics(generalMetrics, fmt.Sprintf("%d", pullRequest.PullRequestID))
+}
+
+func parseTime(s string) *time.Time {
+	// 2024-03-07T10:15:30.1234567Z
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
@@ -0,0 +1,154 @@
+package visualstudioteamservices
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const sampleMetricsPushData = `{
+  "subscriptionId": "f0c23515-bcd7-4e8a-b3fb-c0f9b0b4a1e2",
+  "eventType": "git.push",
+  "publisherId": "tfs",
+  "resourceVersion": "1.0",
+  "createdDate": "2024-03-07T10:15:30Z",
+  "resource": {
+    "commits": [
+      {
+        "commitId": "33b55f7cb7e7e245323987634f960cf4a6e6bc74",
+        "comment": "Fixed bug in web.config file",
+        "author": {"date": "2024-03-07T10:10:00Z"}
+      },
+      {
+        "commitId": "be67f8871a4d2c75f13a51c1d3c30ac0d74d4ef4",
+        "comment": "Initial commit",
+        "author": {"date": "2024-03-07T10:00:00Z"}
+      }
+    ],
+    "refUpdates": [
+      {
+        "name": "refs/heads/master",
+        "oldObjectId": "aad331d8d3b131fa9ae03cf5e53965b51942618a",
+        "newObjectId": "33b55f7cb7e7e245323987634f960cf4a6e6bc74"
+      }
+    ],
+    "repository": {
+      "name": "Fabrikam-Fiber-Git",
+      "defaultBranch": "refs/heads/master"
+    },
+    "pushedBy": {"displayName": "Jamal Hartnett"}
+  }
+}`
+
+const sampleMetricsPullRequestUpdatedData = `{
+  "subscriptionId": "f0c23515-bcd7-4e8a-b3fb-c0f9b0b4a1e2",
+  "eventType": "git.pullrequest.updated",
+  "publisherId": "tfs",
+  "resourceVersion": "1.0",
+  "createdDate": "2024-03-07T10:15:30Z",
+  "resource": {
+    "repository": {"name": "Fabrikam"},
+    "pullRequestId": 1,
+    "status": "completed",
+    "createdBy": {"displayName": "Jamal Hartnett"},
+    "title": "my first pull request",
+    "sourceRefName": "refs/heads/mytopic",
+    "targetRefName": "refs/heads/master",
+    "mergeStatus": "succeeded",
+    "lastMergeSourceCommit": {"commitId": "53d54ac915144006c2c9e90d2c7d3880920db49c"},
+    "lastMergeCommit": {"commitId": "eef717f69257a6333f221566c1c987dc94cc0d72"},
+    "_links": {"web": {"href": "https://fabrikam.visualstudio.com/DefaultCollection/_git/Fabrikam/pullrequest/1"}}
+  }
+}`
+
+const sampleMetricsPullRequestCommentData = `{
+  "subscriptionId": "f0c23515-bcd7-4e8a-b3fb-c0f9b0b4a1e2",
+  "eventType": "ms.vss-code.git-pullrequest-comment-event",
+  "publisherId": "tfs",
+  "resourceVersion": "2.0",
+  "createdDate": "2024-03-07T10:15:30Z",
+  "resource": {
+    "comment": {
+      "id": 2,
+      "content": "This is my comment",
+      "commentType": "text",
+      "author": {"displayName": "Normal Paulk"}
+    },
+    "pullRequest": {
+      "repository": {"name": "Fabrikam"},
+      "pullRequestId": 1,
+      "status": "active",
+      "sourceRefName": "refs/heads/mytopic",
+      "targetRefName": "refs/heads/master"
+    }
+  }
+}`
+
+type testTimeProvider struct {
+	currentTime time.Time
+}
+
+func (p testTimeProvider) CurrentTime() time.Time {
+	return p.currentTime
+}
+
+func TestHookProvider_GatherMetrics(t *testing.T) {
+	currentTime := time.Date(2024, time.March, 7, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name    string
+		payload string
+		appSlug string
+		want    string
+	}{
+		{
+			name:    "Push webhook",
+			payload: sampleMetricsPushData,
+			appSlug: "slug",
+			want:    `{"event":"git_push","action":"pushed","provider_type":"visualstudio","repository":"Fabrikam-Fiber-Git","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30Z","app_slug":"slug","original_trigger":"git.push:","user_name":"Jamal Hartnett","git_ref":"refs/heads/master","commit_id_after":"33b55f7cb7e7e245323987634f960cf4a6e6bc74","commit_id_before":"aad331d8d3b131fa9ae03cf5e53965b51942618a","oldest_commit_timestamp":"2024-03-07T10:00:00Z","latest_commit_timestamp":"2024-03-07T10:10:00Z","master_branch":"refs/heads/master","changed_files_count":0,"addition_count":0,"deletion_count":0}`,
+		},
+		{
+			name:    "Pull request completed webhook",
+			payload: sampleMetricsPullRequestUpdatedData,
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"closed","provider_type":"visualstudio","repository":"Fabrikam","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30Z","app_slug":"slug","original_trigger":"git.pullrequest.updated:","user_name":"Jamal Hartnett","git_ref":"refs/heads/mytopic","pull_request_title":"my first pull request","pull_request_id":"1","pull_request_url":"https://fabrikam.visualstudio.com/DefaultCollection/_git/Fabrikam/pullrequest/1","target_branch":"refs/heads/master","commit_id":"53d54ac915144006c2c9e90d2c7d3880920db49c","changed_files_count":0,"addition_count":0,"deletion_count":0,"commit_count":0,"merge_commit_sha":"eef717f69257a6333f221566c1c987dc94cc0d72","status":"completed"}`,
+		},
+		{
+			name:    "Pull request comment webhook",
+			payload: sampleMetricsPullRequestCommentData,
+			appSlug: "slug",
+			want:    `{"event":"pull_request","action":"comment","provider_type":"visualstudio","repository":"Fabrikam","timestamp":"2024-03-07T12:00:00Z","event_timestamp":"2024-03-07T10:15:30Z","app_slug":"slug","original_trigger":"ms.vss-code.git-pullrequest-comment-event:","user_name":"Normal Paulk","git_ref":"refs/heads/mytopic","pull_request_id":"1"}`,
+		},
+		{
+			name:    "Unsupported webhook",
+			payload: `{"eventType": "build.complete", "publisherId": "tfs"}`,
+			appSlug: "slug",
+			want:    "",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hp := NewHookProvider(testTimeProvider{currentTime: currentTime}).(HookProvider)
+			request := &http.Request{
+				Header: http.Header{"Content-Type": {"application/json"}},
+				Body:   io.NopCloser(strings.NewReader(tt.payload)),
+			}
+
+			got, err := hp.GatherMetrics(request, tt.appSlug)
+			require.NoError(t, err)
+			if tt.want != "" {
+				require.Equal(t, len(got), 1)
+				gotMetrics := got[0]
+				gotBytes, err := gotMetrics.Serialise()
+				require.NoError(t, err)
+				require.Equal(t, tt.want, string(gotBytes))
+			} else {
+				require.Nil(t, got)
+			}
+		})
+	}
+}
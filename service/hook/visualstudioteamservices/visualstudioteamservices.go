@@ -1,6 +1,7 @@
 package visualstudioteamservices
 
 import (
+	"crypto/subtle"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -8,6 +9,7 @@ import (
 	"net/http"
 	"regexp"
 	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
@@ -26,6 +28,8 @@ const (
 	PullRequestCreate = "git.pullrequest.created"
 	// PullRequestUpdate event name
 	PullRequestUpdate = "git.pullrequest.updated"
+	// PullRequestComment event name
+	PullRequestComment = "ms.vss-code.git-pullrequest-comment-event"
 )
 
 // --------------------------
@@ -33,8 +37,14 @@ const (
 
 // CommitModel ...
 type CommitModel struct {
-	CommitID string `json:"commitId"`
-	Comment  string `json:"comment"`
+	CommitID string            `json:"commitId"`
+	Comment  string            `json:"comment"`
+	Author   CommitAuthorModel `json:"author"`
+}
+
+// CommitAuthorModel ...
+type CommitAuthorModel struct {
+	Date string `json:"date"`
 }
 
 // AuthorModel ...
@@ -42,6 +52,22 @@ type AuthorModel struct {
 	DisplayName string `json:"displayName"`
 }
 
+// RepositoryModel ...
+type RepositoryModel struct {
+	Name          string `json:"name"`
+	DefaultBranch string `json:"defaultBranch"`
+}
+
+// LinkModel ...
+type LinkModel struct {
+	Href string `json:"href"`
+}
+
+// PullRequestLinksModel ...
+type PullRequestLinksModel struct {
+	Web LinkModel `json:"web"`
+}
+
 // RefUpdatesModel ...
 type RefUpdatesModel struct {
 	Name        string `json:"name"`
@@ -53,17 +79,38 @@ type RefUpdatesModel struct {
 type PushResourceModel struct {
 	Commits    []CommitModel     `json:"commits"`
 	RefUpdates []RefUpdatesModel `json:"refUpdates"`
+	Repository RepositoryModel   `json:"repository"`
+	PushedBy   AuthorModel       `json:"pushedBy"`
 }
 
 // PullRequestResourceModel ...
 type PullRequestResourceModel struct {
-	SourceReferenceName string      `json:"sourceRefName"`
-	TargetReferenceName string      `json:"targetRefName"`
-	MergeStatus         string      `json:"mergeStatus"`
-	LastSourceCommit    CommitModel `json:"lastMergeSourceCommit"`
-	CreatedBy           AuthorModel `json:"createdBy"`
-	Status              string      `json:"status"`
-	PullRequestID       int         `json:"pullRequestId"`
+	SourceReferenceName string                `json:"sourceRefName"`
+	TargetReferenceName string                `json:"targetRefName"`
+	MergeStatus         string                `json:"mergeStatus"`
+	LastSourceCommit    CommitModel           `json:"lastMergeSourceCommit"`
+	LastMergeCommit     CommitModel           `json:"lastMergeCommit"`
+	CreatedBy           AuthorModel           `json:"createdBy"`
+	Status              string                `json:"status"`
+	PullRequestID       int                   `json:"pullRequestId"`
+	Title               string                `json:"title"`
+	Repository          RepositoryModel       `json:"repository"`
+	Links               PullRequestLinksModel `json:"_links"`
+}
+
+// CommentModel ...
+type CommentModel struct {
+	ID          int         `json:"id"`
+	Content     string      `json:"content"`
+	CommentType string      `json:"commentType"`
+	IsDeleted   bool        `json:"isDeleted"`
+	Author      AuthorModel `json:"author"`
+}
+
+// PullRequestCommentResourceModel ...
+type PullRequestCommentResourceModel struct {
+	Comment     CommentModel             `json:"comment"`
+	PullRequest PullRequestResourceModel `json:"pullRequest"`
 }
 
 // EventMessage ...
@@ -87,6 +134,7 @@ type PushEventModel struct {
 	ResourceVersion string            `json:"resourceVersion"`
 	DetailedMessage EventMessage      `json:"detailedMessage"`
 	Message         EventMessage      `json:"message"`
+	CreatedDate     string            `json:"createdDate"`
 }
 
 // PullRequestEventModel ...
@@ -98,13 +146,40 @@ type PullRequestEventModel struct {
 	ResourceVersion string                   `json:"resourceVersion"`
 	DetailedMessage EventMessage             `json:"detailedMessage"`
 	Message         EventMessage             `json:"message"`
+	CreatedDate     string                   `json:"createdDate"`
+}
+
+// PullRequestCommentEventModel ...
+type PullRequestCommentEventModel struct {
+	SubscriptionID  string                          `json:"subscriptionId"`
+	EventType       string                          `json:"eventType"`
+	PublisherID     string                          `json:"publisherId"`
+	Resource        PullRequestCommentResourceModel `json:"resource"`
+	ResourceVersion string                          `json:"resourceVersion"`
+	DetailedMessage EventMessage                    `json:"detailedMessage"`
+	Message         EventMessage                    `json:"message"`
+	CreatedDate     string                          `json:"createdDate"`
 }
 
 // ---------------------------------------
 // --- Webhook Provider Implementation ---
 
 // HookProvider ...
-type HookProvider struct{}
+type HookProvider struct {
+	timeProvider hookCommon.TimeProvider
+}
+
+// NewHookProvider ...
+func NewHookProvider(timeProvider hookCommon.TimeProvider) hookCommon.Provider {
+	return HookProvider{
+		timeProvider: timeProvider,
+	}
+}
+
+// NewDefaultHookProvider ...
+func NewDefaultHookProvider() hookCommon.Provider {
+	return NewHookProvider(hookCommon.NewDefaultTimeProvider())
+}
 
 // detectContentType ...
 func detectContentType(header http.Header) (string, error) {
@@ -252,7 +327,44 @@ func transformPullRequestEvent(pullRequestEvent PullRequestEventModel) hookCommo
 		}
 	}
 
-	pullRequest := pullRequestEvent.Resource
+	return transformPullRequest(pullRequestEvent.Resource, pullRequestEvent.Message.Text)
+}
+
+// transformPullRequestCommentEvent ...
+func transformPullRequestCommentEvent(commentEvent PullRequestCommentEventModel) hookCommon.TransformResultModel {
+	if commentEvent.ResourceVersion != "2.0" {
+		return hookCommon.TransformResultModel{
+			Error: fmt.Errorf("Unsupported resource version"),
+		}
+	}
+
+	comment := commentEvent.Resource.Comment
+	if comment.IsDeleted {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("Pull request comment deleted"),
+			ShouldSkip: true,
+		}
+	}
+
+	// system comments are generated by Azure DevOps, e.g. for votes and pushes
+	if comment.CommentType != "text" {
+		return hookCommon.TransformResultModel{
+			Error:      fmt.Errorf("Pull request comment type doesn't require a build: %s", comment.CommentType),
+			ShouldSkip: true,
+		}
+	}
+
+	result := transformPullRequest(commentEvent.Resource.PullRequest, commentEvent.Message.Text)
+	for i := range result.TriggerAPIParams {
+		result.TriggerAPIParams[i].BuildParams.PullRequestComment = comment.Content
+		result.TriggerAPIParams[i].BuildParams.PullRequestCommentID = strconv.Itoa(comment.ID)
+	}
+
+	return result
+}
+
+// transformPullRequest ...
+func transformPullRequest(pullRequest PullRequestResourceModel, commitMessage string) hookCommon.TransformResultModel {
 	if pullRequest.Status == "completed" {
 		return hookCommon.TransformResultModel{
 			Error:      fmt.Errorf("Pull request already completed"),
@@ -299,7 +411,7 @@ func transformPullRequestEvent(pullRequestEvent PullRequestEventModel) hookCommo
 
 	var buildParams = bitriseapi.BuildParamsModel{
 		CommitHash:        pullRequest.LastSourceCommit.CommitID,
-		CommitMessage:     pullRequestEvent.Message.Text,
+		CommitMessage:     commitMessage,
 		Branch:            strings.TrimPrefix(pullRequest.SourceReferenceName, "refs/heads/"),
 		BranchDest:        strings.TrimPrefix(pullRequest.TargetReferenceName, "refs/heads/"),
 		PullRequestAuthor: pullRequest.CreatedBy.DisplayName,
@@ -307,6 +419,14 @@ func transformPullRequestEvent(pullRequestEvent PullRequestEventModel) hookCommo
 
 	if pullRequest.PullRequestID != 0 {
 		buildParams.PullRequestID = &pullRequest.PullRequestID
+
+		// Azure DevOps updates the refs/pull/<id>/merge ref asynchronously, the last merge commit is
+		// up-to-date only if the merge status is succeeded (which is checked above).
+		if pullRequest.LastMergeCommit.CommitID != "" {
+			mergeRef := fmt.Sprintf("pull/%d/merge", pullRequest.PullRequestID)
+			buildParams.PullRequestMergeBranch = mergeRef
+			buildParams.PullRequestUnverifiedMergeBranch = mergeRef
+		}
 	}
 
 	return hookCommon.TransformResultModel{
@@ -388,6 +508,14 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 			}
 		}
 		return transformPullRequestEvent(pullRequestEvent)
+	} else if event.EventType == PullRequestComment {
+		var commentEvent PullRequestCommentEventModel
+		if err := json.Unmarshal(body, &commentEvent); err != nil {
+			return hookCommon.TransformResultModel{
+				Error: fmt.Errorf("Failed to parse request body as JSON: %s", err),
+			}
+		}
+		return transformPullRequestCommentEvent(commentEvent)
 	} else {
 		return hookCommon.TransformResultModel{
 			Error: fmt.Errorf("Unsupported event type"),
@@ -395,3 +523,29 @@ func (hp HookProvider) TransformRequest(r *http.Request) hookCommon.TransformRes
 	}
 
 }
+
+// VerifySignature ...
+// Azure DevOps doesn't sign the payload, but the service hook can be configured with Basic authentication
+// credentials: the secret is the password (the username is checked by VerifyUsername, if it's configured).
+func (hp HookProvider) VerifySignature(header http.Header, body []byte, secret string) error {
+	_, password, ok := (&http.Request{Header: header}).BasicAuth()
+	if !ok {
+		return fmt.Errorf("%w: no Basic authentication credentials found", hookCommon.ErrMissingSignature)
+	}
+	if subtle.ConstantTimeCompare([]byte(password), []byte(secret)) != 1 {
+		return fmt.Errorf("%w: Basic authentication credentials do not match", hookCommon.ErrInvalidSignature)
+	}
+	return nil
+}
+
+// VerifyUsername ...
+func (hp HookProvider) VerifyUsername(header http.Header, username string) error {
+	requestUsername, _, ok := (&http.Request{Header: header}).BasicAuth()
+	if !ok {
+		return fmt.Errorf("%w: no Basic authentication credentials found", hookCommon.ErrMissingSignature)
+	}
+	if subtle.ConstantTimeCompare([]byte(requestUsername), []byte(username)) != 1 {
+		return fmt.Errorf("%w: Basic authentication credentials do not match", hookCommon.ErrInvalidSignature)
+	}
+	return nil
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/bitrise-io/bitrise-webhooks/bitriseapi"
+	hookCommon "github.com/bitrise-io/bitrise-webhooks/service/hook/common"
 )
 
 const (
@@ -228,6 +229,50 @@ const (
       ]
     }
   }`
+
+	samplePullRequestComment = `{
+  "subscriptionId": "f0c23515-bcd1-4e30-9613-56a0a129c732",
+  "notificationId": 12,
+  "id": "af07be1b-f3ad-44c8-a7f1-c4835f2df06b",
+  "eventType": "ms.vss-code.git-pullrequest-comment-event",
+  "publisherId": "tfs",
+  "message": {
+    "text": "Jamal Hartnett has commented on a pull request"
+  },
+  "resourceVersion": "2.0",
+  "resource": {
+    "comment": {
+      "id": 2,
+      "parentCommentId": 1,
+      "author": {
+        "displayName": "Jamal Hartnett"
+      },
+      "content": "run ui-tests",
+      "publishedDate": "2014-06-17T16:55:46.363Z",
+      "commentType": "text"
+    },
+    "pullRequest": {
+      "pullRequestId": 14,
+      "status": "active",
+      "createdBy": {
+        "displayName": "Jamal Hartnett"
+      },
+      "title": "my first pull request",
+      "sourceRefName": "refs/heads/feature/addAzureDevOpsPullRequestSupport",
+      "targetRefName": "refs/heads/master",
+      "mergeStatus": "succeeded",
+      "lastMergeSourceCommit": {
+        "commitId": "53d54ac915144006c2c9e90d2c7d3880920db49c"
+      },
+      "lastMergeTargetCommit": {
+        "commitId": "a511f535b1ea495ee0c903badb68fbc83772c882"
+      },
+      "lastMergeCommit": {
+        "commitId": "eef717f69257a6333f221566c1c987dc94cc0d72"
+      }
+    }
+  }
+}`
 )
 
 var intFourteen = 14
@@ -927,6 +972,86 @@ func Test_transformPullRequestEvent(t *testing.T) {
 	}
 }
 
+func Test_transformPullRequestCommentEvent(t *testing.T) {
+	provider := HookProvider{}
+
+	t.Log("Comment created")
+	{
+		request := http.Request{
+			Header: http.Header{
+				"Content-Type": {"application/json; charset=utf-8"},
+			},
+			Body: ioutil.NopCloser(strings.NewReader(samplePullRequestComment)),
+		}
+		hookTransformResult := provider.TransformRequest(&request)
+		require.NoError(t, hookTransformResult.Error)
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.Equal(t, []bitriseapi.TriggerAPIParamsModel{
+			{
+				BuildParams: bitriseapi.BuildParamsModel{
+					CommitHash:                       "53d54ac915144006c2c9e90d2c7d3880920db49c",
+					CommitMessage:                    "Jamal Hartnett has commented on a pull request",
+					Branch:                           "feature/addAzureDevOpsPullRequestSupport",
+					BranchDest:                       "master",
+					PullRequestID:                    &intFourteen,
+					PullRequestAuthor:                "Jamal Hartnett",
+					PullRequestMergeBranch:           "pull/14/merge",
+					PullRequestUnverifiedMergeBranch: "pull/14/merge",
+					PullRequestComment:               "run ui-tests",
+					PullRequestCommentID:             "2",
+				},
+			},
+		}, hookTransformResult.TriggerAPIParams)
+	}
+
+	t.Log("System comment - should be skipped")
+	{
+		event := PullRequestCommentEventModel{
+			ResourceVersion: "2.0",
+			Resource: PullRequestCommentResourceModel{
+				Comment: CommentModel{ID: 3, Content: "Jamal Hartnett voted 10", CommentType: "system"},
+			},
+		}
+		hookTransformResult := transformPullRequestCommentEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Pull request comment type doesn't require a build: system")
+	}
+
+	t.Log("Deleted comment - should be skipped")
+	{
+		event := PullRequestCommentEventModel{
+			ResourceVersion: "2.0",
+			Resource: PullRequestCommentResourceModel{
+				Comment: CommentModel{ID: 2, CommentType: "text", IsDeleted: true},
+			},
+		}
+		hookTransformResult := transformPullRequestCommentEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Pull request comment deleted")
+	}
+
+	t.Log("Comment on a completed pull request - should be skipped")
+	{
+		event := PullRequestCommentEventModel{
+			ResourceVersion: "2.0",
+			Resource: PullRequestCommentResourceModel{
+				Comment:     CommentModel{ID: 2, CommentType: "text", Content: "run ui-tests"},
+				PullRequest: PullRequestResourceModel{Status: "completed"},
+			},
+		}
+		hookTransformResult := transformPullRequestCommentEvent(event)
+		require.True(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Pull request already completed")
+	}
+
+	t.Log("Unsupported resource version")
+	{
+		hookTransformResult := transformPullRequestCommentEvent(PullRequestCommentEventModel{ResourceVersion: "1.0"})
+		require.False(t, hookTransformResult.ShouldSkip)
+		require.EqualError(t, hookTransformResult.Error, "Unsupported resource version")
+	}
+}
+
 func Test_HookProvider_TransformRequest(t *testing.T) {
 	provider := HookProvider{}
 
@@ -1022,3 +1147,45 @@ func Test_HookProvider_TransformRequest(t *testing.T) {
 		require.Equal(t, false, hookTransformResult.DontWaitForTriggerResponse)
 	}
 }
+
+func Test_HookProvider_VerifySignature(t *testing.T) {
+	provider := HookProvider{}
+	withBasicAuth := func(username, password string) http.Header {
+		request := http.Request{Header: http.Header{}}
+		request.SetBasicAuth(username, password)
+		return request.Header
+	}
+
+	t.Log("Password")
+	{
+		require.NoError(t, provider.VerifySignature(withBasicAuth("", "s3cr3t"), nil, "s3cr3t"))
+		require.NoError(t, provider.VerifySignature(withBasicAuth("any-user", "s3cr3t"), nil, "s3cr3t"))
+		require.ErrorIs(t, provider.VerifySignature(withBasicAuth("", "wrong"), nil, "s3cr3t"), hookCommon.ErrInvalidSignature)
+	}
+
+	t.Log("Password - it can start with user: and include colons")
+	{
+		require.NoError(t, provider.VerifySignature(withBasicAuth("any-user", "user:bitrise:s3cr3t"), nil, "user:bitrise:s3cr3t"))
+		require.ErrorIs(t, provider.VerifySignature(withBasicAuth("bitrise", "s3cr3t"), nil, "user:bitrise:s3cr3t"), hookCommon.ErrInvalidSignature)
+	}
+
+	t.Log("No credentials")
+	{
+		require.ErrorIs(t, provider.VerifySignature(http.Header{}, nil, "s3cr3t"), hookCommon.ErrMissingSignature)
+		require.ErrorIs(t, provider.VerifySignature(http.Header{"Authorization": {"Bearer token"}}, nil, "s3cr3t"), hookCommon.ErrMissingSignature)
+	}
+}
+
+func Test_HookProvider_VerifyUsername(t *testing.T) {
+	provider := HookProvider{}
+	withBasicAuth := func(username, password string) http.Header {
+		request := http.Request{Header: http.Header{}}
+		request.SetBasicAuth(username, password)
+		return request.Header
+	}
+
+	require.NoError(t, provider.VerifyUsername(withBasicAuth("bitrise", "s3cr3t"), "bitrise"))
+	require.ErrorIs(t, provider.VerifyUsername(withBasicAuth("other", "s3cr3t"), "bitrise"), hookCommon.ErrInvalidSignature)
+	require.ErrorIs(t, provider.VerifyUsername(withBasicAuth("", "s3cr3t"), "bitrise"), hookCommon.ErrInvalidSignature)
+	require.ErrorIs(t, provider.VerifyUsername(http.Header{}, "bitrise"), hookCommon.ErrMissingSignature)
+}